@@ -0,0 +1,267 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "os"
+    "os/signal"
+    "path/filepath"
+    "syscall"
+    "time"
+
+    "syskit/internal/agent"
+    "syskit/internal/alert"
+    "syskit/internal/config"
+    "syskit/internal/history"
+    "syskit/internal/notify"
+    "syskit/internal/procs"
+
+    "github.com/spf13/cobra"
+)
+
+var agentWriteUnit bool
+
+const agentUnitPath = "/etc/systemd/system/syskit-agent.service"
+
+var agentCmd = &cobra.Command{
+    Use:   "agent",
+    Short: "Run configured watchers continuously as a daemon",
+    Long: `The agent runs the watchers declared under "agent.watchers" in
+~/.syskit/config.yaml, each on its own interval. Watcher types:
+
+  service    restart a systemd service when it is down (like watchdog)
+  process    report suspicious or resource-hungry processes (like process-watch)
+  threshold  alert when CPU/RAM/disk exceed config thresholds
+  history    record metrics into the history store (default interval 1s)
+
+History is sampled every second even when no history watcher is declared.
+
+SIGHUP reloads the configuration, SIGTERM/SIGINT stops gracefully.`,
+}
+
+var agentRunCmd = &cobra.Command{
+    Use:   "run",
+    Short: "Run the agent in the foreground",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        logger := log.New(os.Stdout, "", log.LstdFlags)
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+        defer stop()
+
+        hup := make(chan os.Signal, 1)
+        signal.Notify(hup, syscall.SIGHUP)
+        defer signal.Stop(hup)
+        reload := make(chan struct{})
+        go func() {
+            for {
+                select {
+                case <-ctx.Done():
+                    return
+                case <-hup:
+                    logger.Printf("agent: SIGHUP, reloading config")
+                    select {
+                    case reload <- struct{}{}:
+                    case <-ctx.Done():
+                        return
+                    }
+                }
+            }
+        }()
+
+        // the new config is only published once the old watchers, which
+        // read it through config.Load, have stopped
+        var pending *config.Config
+        r := &agent.Runner{
+            Log: logger,
+            Build: func() ([]agent.Watcher, error) {
+                cfg, err := config.Read()
+                if err != nil {
+                    return nil, err
+                }
+                ws, err := buildWatchers(cfg, logger)
+                if err != nil {
+                    return nil, err
+                }
+                pending = cfg
+                return ws, nil
+            },
+            Activate: func() { config.Set(pending) },
+        }
+        return r.Run(ctx, reload)
+    },
+}
+
+var agentInstallUnitCmd = &cobra.Command{
+    Use:   "install-unit",
+    Short: "Print (or --write) a systemd unit for the agent",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        exe, err := os.Executable()
+        if err != nil {
+            return err
+        }
+        if abs, err := filepath.EvalSymlinks(exe); err == nil {
+            exe = abs
+        }
+        home, _ := os.UserHomeDir()
+        unit := agent.Unit(exe, home)
+        if !agentWriteUnit {
+            fmt.Print(unit)
+            return nil
+        }
+        if err := os.WriteFile(agentUnitPath, []byte(unit), 0o644); err != nil {
+            return err
+        }
+        fmt.Println("written", agentUnitPath)
+        fmt.Println("enable with: systemctl daemon-reload && systemctl enable --now syskit-agent")
+        return nil
+    },
+}
+
+// buildWatchers turns the declarative config into runnable watchers.
+func buildWatchers(cfg *config.Config, logger *log.Logger) ([]agent.Watcher, error) {
+    // reject broken channel definitions up front rather than on first alert
+    channels, err := notify.FromConfig(cfg)
+    if err != nil {
+        return nil, err
+    }
+    var out []agent.Watcher
+    sampling := false
+    for i, wc := range cfg.Agent.Watchers {
+        name := wc.Name
+        if name == "" {
+            name = fmt.Sprintf("%s-%d", wc.Type, i)
+        }
+        every := time.Minute
+        if wc.Type == "history" {
+            every = time.Second
+        }
+        if wc.Interval != "" {
+            d, err := time.ParseDuration(wc.Interval)
+            if err != nil || d <= 0 {
+                return nil, fmt.Errorf("watcher %s: invalid interval %q", name, wc.Interval)
+            }
+            every = d
+        }
+        var fn func(context.Context) error
+        switch wc.Type {
+        case "service":
+            if wc.Service == "" {
+                return nil, fmt.Errorf("watcher %s: service required", name)
+            }
+            svc := wc.Service
+            fn = func(context.Context) error {
+                onceWatch(svc)
+                return nil
+            }
+        case "process":
+            if _, err := notify.Select(channels, wc.Notify); err != nil {
+                return nil, fmt.Errorf("watcher %s: %w", name, err)
+            }
+            fn = processWatcher(wc, logger)
+        case "threshold":
+            f, err := thresholdWatcher(cfg, logger)
+            if err != nil {
+                return nil, fmt.Errorf("watcher %s: %w", name, err)
+            }
+            fn = f
+        case "history":
+            f, err := historyWatcher()
+            if err != nil {
+                return nil, fmt.Errorf("watcher %s: %w", name, err)
+            }
+            fn = f
+            sampling = true
+        default:
+            return nil, fmt.Errorf("watcher %s: unknown type %q", name, wc.Type)
+        }
+        out = append(out, agent.Func{ID: name, Every: every, Fn: fn})
+    }
+    if len(out) == 0 {
+        logger.Printf("agent: no watchers configured under agent.watchers")
+    }
+    if !sampling {
+        fn, err := historyWatcher()
+        if err != nil {
+            return nil, fmt.Errorf("watcher history: %w", err)
+        }
+        out = append(out, agent.Func{ID: "history", Every: time.Second, Fn: fn})
+    }
+    return out, nil
+}
+
+// historyWatcher records one sample per call into the default history store.
+func historyWatcher() (func(context.Context) error, error) {
+    st, err := history.Open(history.DefaultDir())
+    if err != nil {
+        return nil, err
+    }
+    s := history.NewSampler(st)
+    return func(context.Context) error { return s.Tick(time.Now()) }, nil
+}
+
+// processWatcher alerts once per newly matching process; a process that keeps
+// matching on later ticks is not reported again.
+func processWatcher(wc config.Watcher, logger *log.Logger) func(context.Context) error {
+    cpuLimit, ramLimit := wc.CPU, wc.RAM
+    if cpuLimit == 0 {
+        cpuLimit = 50
+    }
+    if ramLimit == 0 {
+        ramLimit = 30
+    }
+    sampler := procs.NewSampler(procs.Default)
+    reported := map[string]bool{}
+    return func(context.Context) error {
+        list, err := sampler.Sample()
+        if err != nil {
+            return err
+        }
+        current := map[string]bool{}
+        var fresh [][]string
+        for _, r := range scanProcesses(list, wc.Keywords, cpuLimit, ramLimit) {
+            key := r[0] + " " + r[2]
+            current[key] = true
+            if !reported[key] {
+                fresh = append(fresh, r)
+                logger.Printf("%s: pid=%s user=%s cpu=%s mem=%s cmd=%s", wc.Name, r[0], r[1], r[3], r[4], r[2])
+            }
+        }
+        reported = current
+        if len(fresh) > 0 {
+            logEntries(fresh)
+            return alertProcesses(fresh, wc.Notify)
+        }
+        return nil
+    }
+}
+
+// thresholdWatcher evaluates config.Thresholds through the alert engine and
+// routes firing/resolved transitions.
+func thresholdWatcher(cfg *config.Config, logger *log.Logger) (func(context.Context) error, error) {
+    rules, err := alert.RulesFromConfig(cfg)
+    if err != nil {
+        return nil, err
+    }
+    repeat, err := alert.RepeatFromConfig(cfg)
+    if err != nil {
+        return nil, fmt.Errorf("thresholds.repeat: %w", err)
+    }
+    eng := alert.NewEngine(rules)
+    eng.Repeat = repeat
+    sampler := alert.NewSampler()
+    return func(context.Context) error {
+        now := time.Now()
+        evs := eng.Evaluate(sampler.Sample(rules, now), now)
+        for _, e := range evs {
+            logger.Printf("threshold: %s", e)
+        }
+        return alert.Route(cfg, evs)
+    }, nil
+}
+
+func init() {
+    agentInstallUnitCmd.Flags().BoolVar(&agentWriteUnit, "write", false, "write the unit to "+agentUnitPath)
+
+    agentCmd.AddCommand(agentRunCmd)
+    agentCmd.AddCommand(agentInstallUnitCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+    "fmt"
+    "os"
+    "strings"
+    "time"
+
+    "syskit/internal/alert"
+    "syskit/internal/config"
+
+    "github.com/spf13/cobra"
+)
+
+// Nagios plugin exit codes.
+const (
+    nagiosOK       = 0
+    nagiosWarning  = 1
+    nagiosCritical = 2
+    nagiosUnknown  = 3
+)
+
+var (
+    checkWarnRatio float64
+    checkNotify    bool
+)
+
+var checkCmd = &cobra.Command{
+    Use:   "check",
+    Short: "One-shot threshold check with Nagios-compatible exit codes",
+    Long: `Evaluates config.yaml thresholds once and prints a Nagios plugin status line.
+Exit codes: 0 OK, 1 WARNING (>= warn-ratio x threshold), 2 CRITICAL, 3 UNKNOWN.
+The "for" duration is ignored since a single check cannot observe it.`,
+    Run: func(cmd *cobra.Command, args []string) {
+        os.Exit(runCheck())
+    },
+}
+
+func runCheck() int {
+    cfg := config.Load()
+    rules, err := alert.RulesFromConfig(cfg)
+    if err != nil {
+        fmt.Println("SYSKIT UNKNOWN -", err)
+        return nagiosUnknown
+    }
+    if len(rules) == 0 {
+        fmt.Println("SYSKIT UNKNOWN - no thresholds configured")
+        return nagiosUnknown
+    }
+    // two samples one second apart so CPU and network rates are available
+    s := alert.NewSampler()
+    s.Sample(rules, time.Now())
+    time.Sleep(time.Second)
+    samples := s.Sample(rules, time.Now())
+
+    var crit, warned, unknown bool
+    var problems, perf []string
+    for _, r := range rules {
+        v, ok := samples[r.Metric]
+        if !ok {
+            problems = append(problems, r.Name+" unavailable")
+            unknown = true
+            continue
+        }
+        warn := r.Threshold * checkWarnRatio
+        switch {
+        case v >= r.Threshold:
+            problems = append(problems, fmt.Sprintf("%s %.1f%s >= %.0f%s", r.Name, v, r.Unit, r.Threshold, r.Unit))
+            crit = true
+        case v >= warn:
+            problems = append(problems, fmt.Sprintf("%s %.1f%s >= %.0f%s", r.Name, v, r.Unit, warn, r.Unit))
+            warned = true
+        }
+        perf = append(perf, fmt.Sprintf("'%s'=%.1f;%.0f;%.0f", r.Name, v, warn, r.Threshold))
+    }
+    code := nagiosOK
+    switch {
+    case crit:
+        code = nagiosCritical
+    case warned:
+        code = nagiosWarning
+    case unknown:
+        code = nagiosUnknown
+    }
+
+    status := [...]string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}[code]
+    msg := "all thresholds within limits"
+    if len(problems) > 0 {
+        msg = strings.Join(problems, ", ")
+    }
+    fmt.Printf("SYSKIT %s - %s | %s\n", status, msg, strings.Join(perf, " "))
+
+    if checkNotify && code == nagiosCritical {
+        eng := alert.NewEngine(rules)
+        if err := alert.Route(cfg, eng.Evaluate(samples, time.Now())); err != nil {
+            fmt.Fprintln(os.Stderr, "notify:", err)
+        }
+    }
+    return code
+}
+
+func init() {
+    checkCmd.Flags().Float64Var(&checkWarnRatio, "warn-ratio", 0.9, "warn when a value reaches this fraction of its threshold")
+    checkCmd.Flags().BoolVar(&checkNotify, "notify", false, "send critical alerts through the configured channels")
+}
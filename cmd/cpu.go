@@ -1,8 +1,13 @@
 package cmd
 
 import (
+    "fmt"
+    "strconv"
+    "time"
+
     tea "github.com/charmbracelet/bubbletea"
     "syskit/internal/ui"
+    "syskit/internal/utils"
 
     "github.com/spf13/cobra"
 )
@@ -12,13 +17,39 @@ var watchCPU bool
 var cpuCmd = &cobra.Command{
 	Use:   "cpu",
 	Short: "Display CPU information",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Per-core busy time with user/system/iowait/steal breakdown from /proc/stat,
+current clock, scaling limits and governor from cpufreq, thermal throttle
+counts, socket/core/thread topology and CPU pressure (PSI).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+        if !interactive(cmd, watchCPU) {
+            r, err := ui.ReadCPUReport(500 * time.Millisecond)
+            if err != nil {
+                return err
+            }
+            utils.PrintValue(r, func() { printCPUTable(r) })
+            return nil
+        }
         m := ui.NewCpuModel(watchCPU)
-        p := tea.NewProgram(m)
-        _ = p.Start()
+        _, err := tea.NewProgram(m).Run()
+        return err
     },
 }
 
 func init() {
     cpuCmd.Flags().BoolVarP(&watchCPU, "watch", "w", false, "watch mode")
 }
+
+func printCPUTable(r ui.CPUReport) {
+    utils.Print([]string{"CPU", "Topology", "loadavg", "Pressure"}, [][]string{{
+        r.Model, r.Topology(), fmt.Sprintf("%.2f %.2f %.2f", r.Load1, r.Load5, r.Load15), r.PSI(),
+    }})
+    pct := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+    rows := [][]string{{"all", pct(r.Total.Busy), pct(r.Total.User), pct(r.Total.System), pct(r.Total.IOWait), pct(r.Total.Steal), "", "", "", "", "", ""}}
+    for _, c := range r.CPUs {
+        cur, limits := c.FreqRange()
+        rows = append(rows, []string{c.Name, pct(c.Usage.Busy), pct(c.Usage.User), pct(c.Usage.System), pct(c.Usage.IOWait), pct(c.Usage.Steal),
+            cur, limits, c.Governor, fmt.Sprintf("%d/%d", c.Package, c.Core), c.Threads,
+            strconv.FormatUint(c.CoreThrottles+c.PackageThrottles, 10)})
+    }
+    utils.Print([]string{"CPU", "BUSY%", "USR%", "SYS%", "IOW%", "STEAL%", "MHz", "MIN-MAX", "GOVERNOR", "PKG/CORE", "SIBLINGS", "THROTTLES"}, rows)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+
+    "syskit/internal/exporter"
+
+    "github.com/spf13/cobra"
+)
+
+var exportListen string
+
+var exportCmd = &cobra.Command{
+    Use:   "export",
+    Short: "Serve metrics for Prometheus in OpenMetrics format",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        mux := http.NewServeMux()
+        mux.Handle("/metrics", exporter.New())
+        mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+            if r.URL.Path != "/" {
+                http.NotFound(w, r)
+                return
+            }
+            fmt.Fprintln(w, `<html><body><h1>syskit exporter</h1><a href="/metrics">/metrics</a></body></html>`)
+        })
+        srv := &http.Server{Addr: exportListen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+        defer stop()
+        go func() {
+            <-ctx.Done()
+            shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+            defer cancel()
+            srv.Shutdown(shutdownCtx)
+        }()
+
+        fmt.Printf("serving metrics on %s/metrics\n", exportListen)
+        if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            return err
+        }
+        return nil
+    },
+}
+
+func init() {
+    exportCmd.Flags().StringVar(&exportListen, "listen", ":9469", "address to listen on")
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/signal"
+    "sort"
+    "syscall"
+    "time"
+
+    "syskit/internal/history"
+    "syskit/internal/utils"
+
+    "github.com/spf13/cobra"
+)
+
+var (
+    histSince    string
+    histUntil    string
+    histInterval time.Duration
+)
+
+var historyCmd = &cobra.Command{
+    Use:   "history",
+    Short: "Show recorded metric history (cpu|mem|swap|disk|net)",
+    Long: `Metric history is stored under ~/.syskit/history in ring files with three
+resolutions: 1s samples for the last hour, 1m averages for a day and 1h
+averages for 30 days. Run "syskit history record" (or the agent) to collect.`,
+}
+
+var historyRecordCmd = &cobra.Command{
+    Use:   "record",
+    Short: "Sample metrics into the history store until interrupted",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        st, err := history.Open(history.DefaultDir())
+        if err != nil {
+            return err
+        }
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+        defer stop()
+        s := history.NewSampler(st)
+        s.Interval = histInterval
+        return s.Run(ctx)
+    },
+}
+
+// newHistoryQueryCmd builds a query subcommand printing one column per series.
+func newHistoryQueryCmd(name string, headers []string, series []string, format func(float64) string) *cobra.Command {
+    return &cobra.Command{
+        Use:   name,
+        Short: fmt.Sprintf("Show %s history", name),
+        RunE: func(cmd *cobra.Command, args []string) error {
+            now := time.Now()
+            since, err := utils.ParseTime(histSince, now)
+            if err != nil {
+                return err
+            }
+            until, err := utils.ParseTime(histUntil, now)
+            if err != nil {
+                return err
+            }
+            if until.IsZero() {
+                until = now
+            }
+            st, err := history.Open(history.DefaultDir())
+            if err != nil {
+                return err
+            }
+            // merge the series on timestamp
+            byTime := map[int64][]string{}
+            var order []int64
+            for i, s := range series {
+                pts, _, err := st.Query(s, since, until)
+                if err != nil {
+                    return err
+                }
+                for _, p := range pts {
+                    ts := p.Time.Unix()
+                    row, ok := byTime[ts]
+                    if !ok {
+                        row = make([]string, len(series)+1)
+                        row[0] = p.Time.Format("2006-01-02 15:04:05")
+                        for j := range series {
+                            row[j+1] = "-"
+                        }
+                        byTime[ts] = row
+                        order = append(order, ts)
+                    }
+                    row[i+1] = format(p.Value)
+                }
+            }
+            if len(order) == 0 {
+                fmt.Printf("No %s history recorded (run: syskit history record)\n", name)
+                return nil
+            }
+            sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+            rows := make([][]string, 0, len(order))
+            for _, ts := range order {
+                rows = append(rows, byTime[ts])
+            }
+            utils.Print(headers, rows)
+            return nil
+        },
+    }
+}
+
+func pct(v float64) string { return fmt.Sprintf("%.1f", v) }
+
+func rate(v float64) string { return human(uint64(v)) + "/s" }
+
+func init() {
+    historyCmd.PersistentFlags().StringVar(&histSince, "since", "1h", "start of window (e.g. 6h, 2d, 2006-01-02)")
+    historyCmd.PersistentFlags().StringVar(&histUntil, "until", "", "end of window (default now)")
+    historyRecordCmd.Flags().DurationVar(&histInterval, "interval", time.Second, "sampling interval")
+
+    historyCmd.AddCommand(historyRecordCmd)
+    historyCmd.AddCommand(newHistoryQueryCmd("cpu", []string{"Time", "CPU%"}, []string{history.SeriesCPU}, pct))
+    historyCmd.AddCommand(newHistoryQueryCmd("mem", []string{"Time", "MEM%"}, []string{history.SeriesMem}, pct))
+    historyCmd.AddCommand(newHistoryQueryCmd("swap", []string{"Time", "Swap%"}, []string{history.SeriesSwap}, pct))
+    historyCmd.AddCommand(newHistoryQueryCmd("disk", []string{"Time", "Disk%"}, []string{history.SeriesDisk}, pct))
+    historyCmd.AddCommand(newHistoryQueryCmd("net", []string{"Time", "RX", "TX"}, []string{history.SeriesNetRx, history.SeriesNetTx}, rate))
+}
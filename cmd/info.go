@@ -1,16 +1,13 @@
 package cmd
 
 import (
-    "bufio"
     "fmt"
-    "net"
-    "os"
-    "os/exec"
-    "runtime"
+    "sort"
     "strconv"
     "strings"
     "time"
 
+    "syskit/internal/inventory"
     "syskit/internal/utils"
 
     "github.com/spf13/cobra"
@@ -24,11 +21,15 @@ var (
 var infoCmd = &cobra.Command{
     Use:   "info",
     Short: "Display system information",
+    Long: `Host, OS, kernel, CPU, memory, disk and network summary. --verbose adds the
+hardware and software inventory. With -o json|yaml the report is a single
+document; --quiet keeps only hostname, OS, kernel and uptime.`,
     Run: func(cmd *cobra.Command, args []string) {
-        printBasic()
-        if infoVerbose {
-            printDetailed()
+        si := inventory.Basic()
+        if !infoQuiet {
+            si = inventory.Gather(infoVerbose)
         }
+        utils.PrintValue(si, func() { printInfo(si) })
     },
 }
 
@@ -37,183 +38,121 @@ func init() {
     infoCmd.Flags().BoolVarP(&infoVerbose, "verbose", "v", false, "show detailed info")
 }
 
-func printBasic() {
-    if infoQuiet {
-        headers := []string{"Hostname", "OS", "Kernel", "Uptime"}
-        rows := [][]string{{hostname(), osRelease(), kernel(), uptime()}}
-        utils.Print(headers, rows)
-        return
+// printInfo renders si as one table grouped by section, followed by the
+// inventory tables in verbose mode.
+func printInfo(si inventory.SystemInfo) {
+    var rows [][]string
+    add := func(section string, kv ...string) {
+        for i := 0; i+1 < len(kv); i += 2 {
+            rows = append(rows, []string{section, kv[i], kv[i+1]})
+            section = ""
+        }
+    }
+    add("System", "Hostname", si.Hostname, "OS", si.OS, "Kernel", si.Kernel,
+        "Uptime", (time.Duration(si.UptimeSeconds) * time.Second).Truncate(time.Minute).String())
+    if c := si.CPU; c != nil {
+        freq := "-"
+        if c.MHz > 0 {
+            freq = fmt.Sprintf("%.0f MHz", c.MHz)
+        }
+        add("CPU", "Model", c.Model, "Cores", strconv.Itoa(c.Cores), "Freq", freq,
+            "Load", fmt.Sprintf("%.2f %.2f %.2f", c.Load1, c.Load5, c.Load15))
+    }
+    if m := si.Memory; m != nil {
+        add("Memory", "MemTotal", human(m.Total), "MemAvail", human(m.Available), "SwapUsed", human(m.SwapUsed)+" / "+human(m.SwapTotal))
+    }
+    if d := si.Disk; d != nil {
+        add("Disk", "Path", d.Path, "Size", human(d.Total), "Used%", fmt.Sprintf("%.0f%%", d.UsedPercent))
+    }
+    if si.GPU != "" {
+        add("GPU", "Device", si.GPU)
+    }
+    var kv []string
+    for _, it := range si.Interfaces {
+        kv = append(kv, it.Name, strings.Join(it.Addrs, "\n"))
+    }
+    add("Network", kv...)
+    utils.Print([]string{"Section", "Field", "Value"}, rows)
+    if si.Inventory != nil {
+        printInventory(*si.Inventory)
     }
-
-    fmt.Println("# Basic System Information")
-    utils.Print([]string{"Hostname", "OS"}, [][]string{{hostname(), osRelease()}})
-
-    utils.Print([]string{"Kernel", "Uptime"}, [][]string{{kernel(), uptime()}})
-
-    cpuModel, cores, freq, load := cpuInfo()
-    utils.Print([]string{"CPU", "Cores", "Freq", "Load"}, [][]string{{cpuModel, cores, freq, load}})
-
-    total, avail, swap := memInfo()
-    utils.Print([]string{"MemTotal", "MemAvail", "SwapUsed"}, [][]string{{total, avail, swap}})
-
-    size, used := rootDisk()
-    utils.Print([]string{"Disk", "Used%"}, [][]string{{size, used}})
-
-    gpu := gpuInfo()
-    utils.Print([]string{"GPU"}, [][]string{{gpu}})
-
-    ifaceRows := netInfo()
-    utils.Print([]string{"Iface", "IP"}, ifaceRows)
 }
 
-func printDetailed() {
+func printInventory(inv inventory.Inventory) {
     fmt.Println("\n# Detailed Info")
-    // placeholder: could extend with more detailed sections
-}
+    d := inv.DMI
+    utils.Print([]string{"Vendor", "Product", "Version", "Serial", "Chassis"},
+        [][]string{{d.SysVendor, d.ProductName, d.ProductVersion, d.ProductSerial, d.ChassisType}})
+    utils.Print([]string{"Board", "BIOS", "BIOS Date"},
+        [][]string{{strings.TrimSpace(d.BoardVendor + " " + d.BoardName), strings.TrimSpace(d.BIOSVendor + " " + d.BIOSVersion), d.BIOSDate}})
 
-func hostname() string {
-    h, _ := os.Hostname()
-    return h
-}
-
-func osRelease() string {
-    f, err := os.Open("/etc/os-release")
-    if err != nil {
-        return "-"
-    }
-    defer f.Close()
-    scanner := bufio.NewScanner(f)
-    var name, ver string
-    for scanner.Scan() {
-        line := scanner.Text()
-        if strings.HasPrefix(line, "NAME=") && name == "" {
-            name = strings.Trim(line[5:], "\"")
+    var rows [][]string
+    for _, b := range inv.Block {
+        kind := "SSD"
+        if b.Rotational {
+            kind = "HDD"
         }
-        if strings.HasPrefix(line, "VERSION_ID=") && ver == "" {
-            ver = strings.Trim(line[11:], "\"")
+        if b.Removable {
+            kind += ", removable"
         }
+        rows = append(rows, []string{b.Name, strings.TrimSpace(b.Vendor + " " + b.Model), b.Serial, human(b.Size), kind})
     }
-    return fmt.Sprintf("%s %s", name, ver)
-}
+    utils.Print([]string{"Disk", "Model", "Serial", "Size", "Type"}, rows)
 
-func kernel() string {
-    out, err := exec.Command("uname", "-r").Output()
-    if err != nil {
-        return "-"
+    rows = nil
+    for _, p := range inv.PCI {
+        name := strings.TrimSpace(p.Vendor + " " + p.Device)
+        if name == "" {
+            name = p.VendorID + ":" + p.DeviceID
+        }
+        rows = append(rows, []string{p.Slot, p.Class, name, p.Driver})
     }
-    return strings.TrimSpace(string(out))
-}
+    utils.Print([]string{"PCI", "Class", "Device", "Driver"}, rows)
 
-func uptime() string {
-    b, err := os.ReadFile("/proc/uptime")
-    if err != nil {
-        return "-"
+    rows = nil
+    for _, u := range inv.USB {
+        rows = append(rows, []string{fmt.Sprintf("%03d:%03d", u.Bus, u.Device), u.VendorID + ":" + u.ProductID,
+            strings.TrimSpace(u.Manufacturer + " " + u.Product), u.SpeedMbps})
     }
-    parts := strings.Fields(string(b))
-    sec, _ := strconv.ParseFloat(parts[0], 64)
-    d := time.Duration(sec) * time.Second
-    return d.Truncate(time.Minute).String()
-}
+    utils.Print([]string{"USB", "ID", "Device", "Mbps"}, rows)
 
-func cpuInfo() (model, cores, freq, load string) {
-    f, err := os.Open("/proc/cpuinfo")
-    if err == nil {
-        scanner := bufio.NewScanner(f)
-        for scanner.Scan() {
-            line := scanner.Text()
-            if strings.HasPrefix(line, "model name") && model == "" {
-                parts := strings.SplitN(line, ":", 2)
-                if len(parts) == 2 {
-                    model = strings.TrimSpace(parts[1])
-                }
-            }
-            if strings.HasPrefix(line, "cpu MHz") && freq == "" {
-                parts := strings.SplitN(line, ":", 2)
-                if len(parts) == 2 {
-                    freq = fmt.Sprintf("%s MHz", strings.TrimSpace(parts[1]))
-                }
-            }
+    rows = nil
+    for _, n := range inv.NICs {
+        speed := "-"
+        if n.SpeedMbps > 0 {
+            speed = fmt.Sprintf("%d Mb/s %s", n.SpeedMbps, n.Duplex)
         }
-        f.Close()
-    }
-    cores = fmt.Sprintf("%d", runtime.NumCPU())
-
-    b, _ := os.ReadFile("/proc/loadavg")
-    load = strings.Join(strings.Fields(string(b))[:3], " ")
-    return
-}
-
-func memInfo() (total, avail, swapUsed string) {
-    f, err := os.Open("/proc/meminfo")
-    if err != nil {
-        return
-    }
-    defer f.Close()
-    scanner := bufio.NewScanner(f)
-    var t, a, st, sf int64
-    for scanner.Scan() {
-        var key string
-        var val int64
-        fmt.Sscanf(scanner.Text(), "%s %d", &key, &val)
-        switch key {
-        case "MemTotal:":
-            t = val
-        case "MemAvailable:":
-            a = val
-        case "SwapTotal:":
-            st = val
-        case "SwapFree:":
-            sf = val
+        driver := n.Driver
+        if n.Virtual {
+            driver = "(virtual)"
         }
+        rows = append(rows, []string{n.Name, n.MAC, driver, speed, n.State, strconv.Itoa(n.MTU)})
     }
-    total = fmt.Sprintf("%.1f GB", float64(t)/1048576)
-    avail = fmt.Sprintf("%.1f GB", float64(a)/1048576)
-    if st > 0 {
-        swapUsed = fmt.Sprintf("%.1f GB", float64(st-sf)/1048576)
-    } else {
-        swapUsed = "0 GB"
-    }
-    return
-}
+    utils.Print([]string{"Iface", "MAC", "Driver", "Speed", "State", "MTU"}, rows)
 
-func rootDisk() (size, used string) {
-    out, err := exec.Command("df", "-h", "/").Output()
-    if err != nil {
-        return
-    }
-    lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-    if len(lines) < 2 {
-        return
-    }
-    fields := strings.Fields(lines[1])
-    if len(fields) >= 5 {
-        size = fields[1]
-        used = fields[4]
+    k := inv.Kernel
+    mods := make([]string, len(k.Modules))
+    for i, m := range k.Modules {
+        mods[i] = m.Name
     }
-    return
-}
+    sort.Strings(mods)
+    utils.Print([]string{"Kernel", "Cmdline"}, [][]string{{k.Release, k.Cmdline}})
+    fmt.Printf("%d modules: %s\n", len(mods), strings.Join(mods, " "))
 
-func gpuInfo() string {
-    out, err := exec.Command("lspci").Output()
-    if err != nil {
-        return "-"
-    }
-    for _, line := range strings.Split(string(out), "\n") {
-        if strings.Contains(line, "VGA") {
-            return strings.TrimSpace(line)
-        }
+    v := inv.Virtualization
+    utils.Print([]string{"Hypervisor", "Container"}, [][]string{{orDash(v.Hypervisor), orDash(v.Container)}})
+
+    rows = nil
+    for name, n := range inv.Packages {
+        rows = append(rows, []string{name, strconv.Itoa(n)})
     }
-    return "-"
+    sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+    utils.Print([]string{"Packages", "Installed"}, rows)
 }
 
-func netInfo() [][]string {
-    ifaces, _ := net.Interfaces()
-    var rows [][]string
-    for _, iface := range ifaces {
-        addrs, _ := iface.Addrs()
-        for _, a := range addrs {
-            rows = append(rows, []string{iface.Name, a.String()})
-        }
+func orDash(s string) string {
+    if s == "" {
+        return "-"
     }
-    return rows
+    return s
 }
@@ -1,24 +1,74 @@
 package cmd
 
 import (
+    "fmt"
+    "strconv"
+
     tea "github.com/charmbracelet/bubbletea"
     "syskit/internal/ui"
+    "syskit/internal/utils"
 
     "github.com/spf13/cobra"
 )
 
-var watchMem bool
+var (
+    watchMem bool
+    memTop   int
+    memSort  string
+)
 
 var memCmd = &cobra.Command{
     Use:   "mem",
     Short: "Display memory usage",
-    Run: func(cmd *cobra.Command, args []string) {
-        m := ui.NewMemModel(watchMem)
-        p := tea.NewProgram(m)
-        _ = p.Start()
+    Long: `RAM and swap usage with a used/buffers/cached/shmem/slab/hugepages breakdown,
+the top processes by RSS or PSS (from smaps_rollup), per-process swap, the
+processes with the highest OOM score and memory pressure (PSI).
+PSS and swap of other users' processes need root.`,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        if memSort != "rss" && memSort != "pss" {
+            return fmt.Errorf("--sort must be rss or pss")
+        }
+        if !interactive(cmd, watchMem) {
+            r, err := ui.ReadMemReport(memTop, memSort)
+            if err != nil {
+                return err
+            }
+            utils.PrintValue(r, func() { printMemTable(r) })
+            return nil
+        }
+        m := ui.NewMemModel(watchMem, memTop, memSort)
+        _, err := tea.NewProgram(m).Run()
+        return err
     },
 }
 
 func init() {
     memCmd.Flags().BoolVarP(&watchMem, "watch", "w", false, "watch mode")
+    memCmd.Flags().IntVarP(&memTop, "top", "n", 10, "number of processes per list")
+    memCmd.Flags().StringVar(&memSort, "sort", "rss", "order of the top list: rss or pss")
+}
+
+func printMemTable(r ui.MemReport) {
+    b := r.Memory
+    utils.Print([]string{"ram_percent", "swap_percent", "Total", "Used", "Free", "Available", "Buffers", "Cached", "Shmem", "Slab", "HugePages", "SwapUsed"},
+        [][]string{{fmt.Sprintf("%.1f", r.UsedPct), fmt.Sprintf("%.1f", r.SwapPct), human(b.Total), human(b.Used), human(b.Free), human(b.Available),
+            human(b.Buffers), human(b.Cached), human(b.Shmem), human(b.Slab), human(b.HugePages), human(b.SwapUsed)}})
+    if psi := r.PSI(); psi != "" {
+        fmt.Println("Pressure: " + psi)
+    }
+    procRows := func(list []ui.MemProc) [][]string {
+        var rows [][]string
+        for _, p := range list {
+            rows = append(rows, []string{strconv.Itoa(p.PID), p.Name, p.User, human(p.RSS), human(p.PSS), human(p.Swap),
+                strconv.Itoa(p.OOMScore), strconv.Itoa(p.OOMAdj)})
+        }
+        return rows
+    }
+    headers := func() []string { return []string{"pid", "Name", "user", "rss", "PSS", "Swap", "OOM", "OOM_ADJ"} }
+    fmt.Println("Top processes by " + r.SortBy)
+    utils.Print(headers(), procRows(r.Top))
+    fmt.Println("Swap users")
+    utils.Print(headers(), procRows(r.Swap))
+    fmt.Println("OOM kill candidates")
+    utils.Print(headers(), procRows(r.OOM))
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "syskit/internal/config"
+    "syskit/internal/notify"
+    "syskit/internal/utils"
+
+    "github.com/spf13/cobra"
+)
+
+var notifyCmd = &cobra.Command{
+    Use:   "notify",
+    Short: "Inspect and test alert notification channels",
+}
+
+var notifyListCmd = &cobra.Command{
+    Use:   "list",
+    Short: "List configured notification channels",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        cfg := config.Load()
+        if _, err := notify.FromConfig(cfg); err != nil {
+            return err
+        }
+        var rows [][]string
+        if len(cfg.Notify) == 0 && cfg.SMTP.Host != "" {
+            rows = append(rows, []string{"email", "smtp", cfg.SMTP.To})
+        }
+        for _, ch := range cfg.Notify {
+            target := ch.URL
+            switch ch.Type {
+            case "smtp", "email":
+                target = ch.To
+                if target == "" {
+                    target = cfg.SMTP.To
+                }
+            case "syslog":
+                target = ch.Addr
+                if target == "" {
+                    target = "local"
+                }
+            case "exec":
+                target = strings.Join(ch.Command, " ")
+            }
+            rows = append(rows, []string{ch.Name, ch.Type, target})
+        }
+        utils.Print([]string{"NAME", "TYPE", "TARGET"}, rows)
+        return nil
+    },
+}
+
+var notifyTestCmd = &cobra.Command{
+    Use:   "test [channel...]",
+    Short: "Send a test message to all or the named channels",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        ns, err := notify.FromConfig(config.Load())
+        if err != nil {
+            return err
+        }
+        if ns, err = notify.Select(ns, args); err != nil {
+            return err
+        }
+        if len(ns) == 0 {
+            return fmt.Errorf("no notification channels configured")
+        }
+        msg := notify.Message{
+            Subject:  "Syskit test notification",
+            Body:     "This is a test message from syskit notify test.",
+            Severity: notify.Info,
+            Source:   "notify-test",
+        }
+        for _, n := range ns {
+            if err := notify.Send(context.Background(), []notify.Notifier{n}, msg); err != nil {
+                fmt.Println("FAIL", err)
+            } else {
+                fmt.Println("OK  ", n.Name())
+            }
+        }
+        return nil
+    },
+}
+
+func init() {
+    notifyCmd.AddCommand(notifyListCmd, notifyTestCmd)
+}
@@ -1,26 +1,86 @@
 package cmd
 
 import (
+    "strconv"
+    "strings"
+
     tea "github.com/charmbracelet/bubbletea"
+    "syskit/internal/netstat"
     "syskit/internal/ui"
+    "syskit/internal/utils"
 
     "github.com/spf13/cobra"
 )
 
-var portFilter string
-var watchPorts bool
+var (
+    watchPorts       bool
+    portsProto       []string
+    portsState       []string
+    portsPort        int
+    portsPID         int
+    portsAll         bool
+    portsEstablished bool
+)
 
 var portsCmd = &cobra.Command{
     Use:   "ports",
     Short: "List open ports",
-    Run: func(cmd *cobra.Command, args []string) {
-        m := ui.NewPortsModel(portFilter, watchPorts)
-        p := tea.NewProgram(m)
-        _ = p.Start()
+    Long: `Sockets read from /proc/net/{tcp,tcp6,udp,udp6,unix} with the owning process,
+user and container. By default only listening TCP and bound UDP sockets are
+shown; --all shows every state and --established summarises connections per
+remote address. Other users' processes are only resolved when run as root.`,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        f := netstat.Filter{Protos: portsProto, States: portsState, Port: portsPort, PID: portsPID, All: portsAll}
+        if portsEstablished {
+            f.States = []string{"ESTABLISHED"}
+        }
+        if err := f.Validate(); err != nil {
+            return err
+        }
+        if !interactive(cmd, watchPorts) {
+            list, err := netstat.List(f)
+            if err != nil {
+                return err
+            }
+            if portsEstablished {
+                printRemotes(netstat.Remotes(list))
+                return nil
+            }
+            utils.PrintValue(list, func() {
+                var rows [][]string
+                for _, e := range list {
+                    rows = append(rows, ui.PortFields(e))
+                }
+                utils.Print([]string{"proto", "local", "peer", "status", "pid", "Process", "user", "Container"}, rows)
+            })
+            return nil
+        }
+        m := ui.NewPortsModel(f, portsEstablished, watchPorts)
+        _, err := tea.NewProgram(m).Run()
+        return err
     },
 }
 
 func init() {
-    portsCmd.Flags().StringVarP(&portFilter, "filter", "f", "", "filter substring (e.g. LISTEN)")
     portsCmd.Flags().BoolVarP(&watchPorts, "watch", "w", false, "watch mode (refresh)")
+    portsCmd.Flags().StringSliceVar(&portsProto, "proto", nil, "protocols: tcp, udp, unix (tcp4/tcp6/udp4/udp6 for one family); default tcp,udp")
+    portsCmd.Flags().StringSliceVar(&portsState, "state", nil, "socket states, e.g. LISTEN,ESTABLISHED,TIME_WAIT")
+    portsCmd.Flags().IntVar(&portsPort, "port", 0, "only sockets with this local or remote port")
+    portsCmd.Flags().IntVar(&portsPID, "pid", 0, "only sockets held by this process")
+    portsCmd.Flags().BoolVarP(&portsAll, "all", "a", false, "show sockets in every state, not only listening ones")
+    portsCmd.Flags().BoolVarP(&portsEstablished, "established", "e", false, "established connections with per-remote counts")
+}
+
+func printRemotes(list []netstat.RemoteCount) {
+    utils.PrintValue(list, func() {
+        var rows [][]string
+        for _, rc := range list {
+            ports := make([]string, len(rc.Ports))
+            for i, p := range rc.Ports {
+                ports[i] = strconv.Itoa(p)
+            }
+            rows = append(rows, []string{rc.Remote, strconv.Itoa(rc.Count), strings.Join(ports, ","), strings.Join(rc.Processes, ",")})
+        }
+        utils.Print([]string{"Remote", "Count", "Local Ports", "Processes"}, rows)
+    })
 }
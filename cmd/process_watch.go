@@ -1,19 +1,20 @@
 package cmd
 
 import (
-    "bufio"
     "fmt"
     "os"
-    "os/exec"
     "path/filepath"
     "strconv"
     "strings"
     "time"
 
     "syskit/internal/config"
-    "syskit/internal/email"
+    "syskit/internal/counters"
+    "syskit/internal/exporter"
+    "syskit/internal/notify"
     "syskit/internal/utils"
     "syskit/internal/i18n"
+    "syskit/internal/procs"
 
     "github.com/spf13/cobra"
 )
@@ -39,73 +40,94 @@ func init() {
 }
 
 func runProcessWatch() {
+    list, err := procs.NewSampler(procs.Default).SampleOver(500 * time.Millisecond)
+    if err != nil {
+        fmt.Println("process table error:", err)
+        return
+    }
+
+    suspectRows := scanProcesses(list, splitKeywords(pwKeywords), pwCpuLimit, pwRamLimit)
+    if len(suspectRows) == 0 {
+        fmt.Println(i18n.T("no_suspicious"))
+        return
+    }
+
+    logEntries(suspectRows)
+    utils.Print([]string{"PID", "USER", "CMD", "CPU%", "MEM%"}, suspectRows)
+    if err := alertProcesses(suspectRows, nil); err != nil {
+        fmt.Fprintln(os.Stderr, "notify:", err)
+    }
+}
+
+func splitKeywords(s string) []string {
     keywords := []string{}
-    for _, k := range strings.Split(pwKeywords, ",") {
+    for _, k := range strings.Split(s, ",") {
         k = strings.TrimSpace(k)
         if k != "" {
             keywords = append(keywords, strings.ToLower(k))
         }
     }
+    return keywords
+}
 
-    out, err := exec.Command("ps", "-eo", "pid,comm,%cpu,%mem", "--no-headers").Output()
-    if err != nil {
-        fmt.Println("ps error:", err)
-        return
-    }
-
-    scanner := bufio.NewScanner(strings.NewReader(string(out)))
+// scanProcesses returns rows for processes whose name contains one of keywords
+// or that exceed the CPU/RAM limits: pid, user, command, cpu, mem. Keywords
+// are not matched against arguments, which would flag "grep mining" and
+// syskit itself when run with --alert-on; our own process is skipped too.
+func scanProcesses(list []procs.Process, keywords []string, cpuLimit, ramLimit int) [][]string {
     var suspectRows [][]string
-    for scanner.Scan() {
-        fields := strings.Fields(scanner.Text())
-        if len(fields) < 4 {
+    self := os.Getpid()
+    for _, p := range list {
+        if p.PID == self {
             continue
         }
-        pid := fields[0]
-        cmdName := fields[1]
-        cpuStr := fields[2]
-        memStr := fields[3]
-        cpuVal, _ := strconv.ParseFloat(strings.TrimSpace(cpuStr), 64)
-        memVal, _ := strconv.ParseFloat(strings.TrimSpace(memStr), 64)
-
-        lower := strings.ToLower(cmdName)
+        cmdLine := p.CommandLine()
+        lower := strings.ToLower(p.Name)
         matchKeyword := false
         for _, kw := range keywords {
-            if strings.Contains(lower, kw) {
+            if strings.Contains(lower, strings.ToLower(kw)) {
                 matchKeyword = true
                 break
             }
         }
-        matchResource := int(cpuVal) >= pwCpuLimit || int(memVal) >= pwRamLimit
+        matchResource := int(p.CPU) >= cpuLimit || int(p.Mem) >= ramLimit
         if matchKeyword || matchResource {
-            suspectRows = append(suspectRows, []string{pid, cmdName, fmt.Sprintf("%.1f", cpuVal), fmt.Sprintf("%.1f", memVal)})
-            logEntry(pid, cmdName, cpuVal, memVal)
+            suspectRows = append(suspectRows, []string{strconv.Itoa(p.PID), p.User, cmdLine, fmt.Sprintf("%.1f", p.CPU), fmt.Sprintf("%.1f", p.Mem)})
         }
     }
+    return suspectRows
+}
 
-    if len(suspectRows) == 0 {
-        fmt.Println(i18n.T("no_suspicious"))
-        return
+// alertProcesses counts the suspect rows for the exporter and sends them to
+// the named notification channels (all configured channels when empty).
+func alertProcesses(suspectRows [][]string, channels []string) error {
+    if err := counters.Add(float64(len(suspectRows)), exporter.ProcessWatchHits); err != nil {
+        fmt.Fprintln(os.Stderr, "counters:", err)
     }
-
-    utils.Print([]string{"PID", "CMD", "CPU%", "MEM%"}, suspectRows)
-
-    // email alert
-    cfg := config.Load()
-    if cfg.SMTP.Host != "" {
-        body := "Suspicious processes detected:\n"
-        for _, r := range suspectRows {
-            body += strings.Join(r, " ") + "\n"
-        }
-        email.Send(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.To, "Syskit process alert", body)
+    body := "Suspicious processes detected:\n"
+    for _, r := range suspectRows {
+        body += strings.Join(r, " ") + "\n"
     }
+    return notify.Dispatch(config.Load(), channels, notify.Message{
+        Subject:  "Syskit process alert",
+        Body:     body,
+        Severity: notify.Warning,
+        Source:   "process-watch",
+    })
 }
 
-func logEntry(pid, name string, cpu, mem float64) {
+// logEntries appends rows from scanProcesses to ~/.syskit/process_watch.log.
+func logEntries(rows [][]string) {
     home, _ := os.UserHomeDir()
     logPath := filepath.Join(home, ".syskit", "process_watch.log")
     os.MkdirAll(filepath.Dir(logPath), 0o755)
-    f, _ := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+    f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+    if err != nil {
+        return
+    }
     defer f.Close()
     timestamp := time.Now().Format(time.RFC3339)
-    fmt.Fprintf(f, "%s pid=%s cmd=%s cpu=%.1f mem=%.1f\n", timestamp, pid, name, cpu, mem)
+    for _, r := range rows {
+        fmt.Fprintf(f, "%s pid=%s cmd=%q cpu=%s mem=%s\n", timestamp, r[0], r[2], r[3], r[4])
+    }
 }
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+    "os"
+    "reflect"
+    "testing"
+
+    "syskit/internal/procs"
+)
+
+func TestScanProcesses(t *testing.T) {
+    list := []procs.Process{
+        {PID: 10, Name: "miningd", User: "bob", Cmdline: []string{"/tmp/miningd", "-o", "pool"}, CPU: 5, Mem: 1},
+        {PID: 11, Name: "grep", User: "alice", Cmdline: []string{"grep", "mining", "notes.txt"}},
+        {PID: 12, Name: "vim", User: "alice", Cmdline: []string{"vim", "crypto.go"}},
+        {PID: 13, Name: "python3", User: "carol", Cmdline: []string{"python3", "train.py"}, CPU: 97.5, Mem: 12},
+        {PID: 14, Name: "java", User: "dave", CPU: 3, Mem: 45.25},
+        {PID: 15, Name: "CryptoTab", User: "erin"},
+        {PID: 16, Name: "sshd", User: "root"},
+        {PID: os.Getpid(), Name: "crypto-syskit", User: "root", CPU: 99},
+    }
+    got := scanProcesses(list, splitKeywords("crypto, Mining,,"), 50, 30)
+    want := [][]string{
+        {"10", "bob", "/tmp/miningd -o pool", "5.0", "1.0"},
+        {"13", "carol", "python3 train.py", "97.5", "12.0"},
+        {"14", "dave", "[java]", "3.0", "45.2"},
+        {"15", "erin", "[CryptoTab]", "0.0", "0.0"},
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("got %v\nwant %v", got, want)
+    }
+    if rows := scanProcesses(list[:3], nil, 50, 30); len(rows) != 0 {
+        t.Errorf("no keywords: got %v", rows)
+    }
+}
@@ -1,16 +1,59 @@
 package cmd
 
 import (
+    "context"
+    "fmt"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+
     "github.com/spf13/cobra"
     tea "github.com/charmbracelet/bubbletea"
     "syskit/internal/pulse"
 )
 
+var (
+    pulseDryRun   bool
+    pulseHistory  time.Duration
+    pulseColumns  []string
+    pulseRecord   string
+    pulseReplay   string
+    pulseInterval time.Duration
+    pulseSpeed    float64
+)
+
 var pulseCmd = &cobra.Command{
     Use:   "pulse",
     Short: "Real-time terminal dashboard",
-    Run: func(cmd *cobra.Command, args []string) {
-        p := tea.NewProgram(pulse.Initial())
-        p.Run()
+    RunE: func(cmd *cobra.Command, args []string) error {
+        opts := pulse.Options{DryRun: pulseDryRun, History: pulseHistory, Columns: pulseColumns, Speed: pulseSpeed}
+        switch {
+        case pulseRecord != "" && pulseReplay != "":
+            return fmt.Errorf("--record and --replay are mutually exclusive")
+        case pulseRecord != "":
+            ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+            defer stop()
+            return pulse.Record(ctx, pulseRecord, pulseInterval, opts)
+        case pulseReplay != "":
+            m, err := pulse.NewReplay(pulseReplay, opts)
+            if err != nil {
+                return err
+            }
+            _, err = tea.NewProgram(m).Run()
+            return err
+        }
+        _, err := tea.NewProgram(pulse.New(opts)).Run()
+        return err
     },
 }
+
+func init() {
+    pulseCmd.Flags().DurationVar(&pulseHistory, "history", 0, "sparkline window for graph mode (g); default pulse.history or 2m")
+    pulseCmd.Flags().StringSliceVar(&pulseColumns, "columns", nil, "process table columns: pid,ppid,user,name,state,nice,threads,cpu,mem,rss,vsz,start,io_read,io_write,cmd")
+    pulseCmd.Flags().BoolVar(&pulseDryRun, "dry-run", false, "log custom actions from config.yaml without running them")
+    pulseCmd.Flags().StringVar(&pulseRecord, "record", "", "append a snapshot every --interval to `file` without starting the dashboard")
+    pulseCmd.Flags().StringVar(&pulseReplay, "replay", "", "play back a recording made with --record")
+    pulseCmd.Flags().DurationVar(&pulseInterval, "interval", time.Second, "sampling interval for --record")
+    pulseCmd.Flags().Float64Var(&pulseSpeed, "speed", 1, "initial playback speed for --replay")
+}
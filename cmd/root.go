@@ -9,6 +9,7 @@ import (
 
 	"syskit/internal/config"
 	"syskit/internal/i18n"
+	"syskit/internal/theme"
 	"syskit/internal/utils"
 
 	"github.com/charmbracelet/lipgloss"
@@ -18,6 +19,7 @@ import (
 var (
 	outputFormat string
 	langCode     string
+	themeName    string
 )
 
 // rootCmd is the base command when called without any subcommands
@@ -42,6 +44,9 @@ var rootCmd = &cobra.Command{
 			}
 		}
 		i18n.Load(langCode)
+		if err := theme.Use(themeName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
 		if langCode != cfg.Lang {
 			cfg.Lang = langCode
 			_ = config.Save()
@@ -68,7 +73,10 @@ func init() {
 
 	// Bubble Tea tabanlı TUI yardım ekranı
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
-		header := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render(cmd.CommandPath() + " - " + cmd.Short)
+		// help runs without PersistentPreRun, so resolve the theme here
+		_ = theme.Use(themeName)
+		th := theme.Current()
+		header := th.Heading().Render(cmd.CommandPath() + " - " + cmd.Short)
 		long := ""
 		if cmd.Long != "" {
 			long = cmd.Long
@@ -84,7 +92,7 @@ func init() {
 				if !c.IsAvailableCommand() || c.Hidden {
 					continue
 				}
-				fmt.Printf("  %s  %s\n", lipgloss.NewStyle().Foreground(lipgloss.Color("69")).Render(c.Name()), c.Short)
+				fmt.Printf("  %s  %s\n", th.Fg(th.Accent).Render(c.Name()), c.Short)
 			}
 		}
 
@@ -101,6 +109,7 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table|json|yaml")
 	rootCmd.PersistentFlags().StringVar(&langCode, "lang", "", "language code (en, tr, de, es)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "color theme: default, high-contrast, solarized, monochrome or a theme from config.yaml")
 
 	rootCmd.AddCommand(langCmd)
 	rootCmd.AddCommand(cpuCmd)
@@ -117,6 +126,22 @@ func init() {
 	rootCmd.AddCommand(pulseCmd)
 	rootCmd.AddCommand(servicesCmd)
 	rootCmd.AddCommand(containersCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(notifyCmd)
+	rootCmd.AddCommand(snapshotCmd)
+}
+
+// interactive reports whether cmd should start its TUI: stdout must be a
+// terminal and the user asked for --watch or did not pick an --output
+// format. Everything else gets structured output via utils.Print.
+func interactive(cmd *cobra.Command, watch bool) bool {
+	if !utils.IsTerminal(os.Stdout) {
+		return false
+	}
+	return watch || !cmd.Flags().Changed("output")
 }
 
 // tryPlugin executes plugin binary if present under pluginDir.
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "syskit/internal/snapshot"
+    "syskit/internal/utils"
+
+    "github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+    Use:   "snapshot",
+    Short: "Save host state and show what changed (save|list|diff)",
+    Long: `A snapshot records system info, listening ports, enabled and running
+services, cron jobs and timers, accounts, containers, kernel modules and key
+sysctls under ~/.syskit/snapshots. "snapshot diff" compares two snapshots,
+or one snapshot with the live host, and lists added, removed and changed
+items per category.`,
+}
+
+var snapshotSaveCmd = &cobra.Command{
+    Use:   "save [name]",
+    Short: "Capture the current state (name defaults to the timestamp)",
+    Args:  cobra.MaximumNArgs(1),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        s := snapshot.Capture()
+        if len(args) == 1 {
+            s.Name = args[0]
+        }
+        path, err := snapshot.Save(s, snapshot.DefaultDir())
+        if err != nil {
+            return err
+        }
+        fmt.Println("Saved", path)
+        for _, cat := range snapshot.Categories {
+            if reason, ok := s.Errors[cat]; ok {
+                fmt.Printf("  %s not captured: %s\n", cat, reason)
+            }
+        }
+        return nil
+    },
+}
+
+var snapshotListCmd = &cobra.Command{
+    Use:   "list",
+    Short: "List saved snapshots",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        list, err := snapshot.List(snapshot.DefaultDir())
+        if err != nil {
+            return err
+        }
+        utils.PrintValue(list, func() {
+            var rows [][]string
+            for _, e := range list {
+                rows = append(rows, []string{e.Name, e.Taken.Format(time.DateTime), e.Path})
+            }
+            utils.Print([]string{"Name", "Time", "Path"}, rows)
+        })
+        return nil
+    },
+}
+
+var snapshotDiffCmd = &cobra.Command{
+    Use:   "diff A [B]",
+    Short: "Show changes from snapshot A to snapshot B or the live state",
+    Long: `A and B are snapshot names, file paths or "latest". Without B, A is
+compared with the live host.`,
+    Args: cobra.RangeArgs(1, 2),
+    RunE: func(cmd *cobra.Command, args []string) error {
+        dir := snapshot.DefaultDir()
+        a, err := snapshot.Load(args[0], dir)
+        if err != nil {
+            return err
+        }
+        var b *snapshot.Snapshot
+        if len(args) == 2 {
+            if b, err = snapshot.Load(args[1], dir); err != nil {
+                return err
+            }
+        } else {
+            b = snapshot.Capture()
+            b.Name = "live"
+        }
+        d := snapshot.Compare(a, b)
+        utils.PrintValue(d, func() { printSnapshotDiff(d) })
+        return nil
+    },
+}
+
+func init() {
+    snapshotCmd.AddCommand(snapshotSaveCmd, snapshotListCmd, snapshotDiffCmd)
+}
+
+var changeMarks = map[string]string{snapshot.Added: "+", snapshot.Removed: "-", snapshot.Changed: "~"}
+
+func printSnapshotDiff(d snapshot.Diff) {
+    fmt.Printf("%s → %s\n", d.From, d.To)
+    var skipped []string
+    for cat := range d.Skipped {
+        skipped = append(skipped, cat)
+    }
+    sort.Strings(skipped)
+    for _, cat := range skipped {
+        fmt.Printf("  %s skipped (%s)\n", cat, d.Skipped[cat])
+    }
+    if len(d.Changes) == 0 {
+        fmt.Println("No changes")
+        return
+    }
+    var rows [][]string
+    last := ""
+    for _, c := range d.Changes {
+        cat := c.Category
+        if cat == last {
+            cat = ""
+        }
+        last = c.Category
+        rows = append(rows, []string{cat, changeMarks[c.Kind], c.Item, c.Old, c.New})
+    }
+    utils.Print([]string{"Category", "Change", "Item", "Old", "New"}, rows)
+}
@@ -5,56 +5,121 @@ import (
     "fmt"
     "os"
     "os/exec"
-    "path/filepath"
     "sort"
     "strconv"
     "strings"
+    "time"
 
     "github.com/spf13/cobra"
+    "syskit/internal/cleanup"
+    "syskit/internal/config"
     "syskit/internal/i18n"
     "syskit/internal/utils"
 )
 
 var (
-    dryRun bool
-    force  bool
-    auto   bool
+    dryRun      bool
+    force       bool
+    auto        bool
+    cleanRules  []string
+    showSkipped bool
+    listRules   bool
 )
 
 var syscleanCmd = &cobra.Command{
     Use:   "sysclean",
     Short: "Clean temp files and caches",
-    Run: func(cmd *cobra.Command, args []string) {
-        runSysclean()
+    Long: `Deletes what the cleanup rules select: by default files in /tmp older than
+10 days, in /var/tmp older than 30 days, package caches and rotated logs.
+Rules, protected paths, ages, owners and size limits are configured under
+sysclean in ~/.syskit/config.yaml. Protected paths, items held open by a
+process and items containing sockets, FIFOs or device nodes are never
+deleted, so --auto is safe to run from cron. Run as root to see the open
+files of every process.`,
+    RunE: func(cmd *cobra.Command, args []string) error {
+        return runSysclean()
     },
 }
 
-type deleteItem struct {
-    Path string
-    Size int64
-}
+func runSysclean() error {
+    policy, err := cleanup.Load(config.Load())
+    if err != nil {
+        return err
+    }
+    if len(cleanRules) > 0 {
+        if policy.Rules, err = selectRules(policy.Rules, cleanRules); err != nil {
+            return err
+        }
+    }
+    if listRules {
+        printRules(policy)
+        return nil
+    }
 
-func runSysclean() {
-    showDiskUsage()
-    showLargest("/")
+    if !auto {
+        showDiskUsage()
+        showLargest("/")
+    }
 
-    targets := collectTargets()
-    previewTargets(targets)
+    items := policy.Plan(cleanup.OpenFiles(), time.Now())
+    n := previewTargets(items)
 
-    if dryRun {
-        return
+    if dryRun || n == 0 {
+        return nil
     }
 
     if !force && !auto {
         if !confirm(i18n.T("Proceed with deletion? [y/N]:")) {
             fmt.Println("aborted")
-            return
+            return nil
         }
     }
 
-    deleteTargets(targets)
+    deleteTargets(items)
     fmt.Println("\nAfter cleanup:")
     showDiskUsage()
+    return nil
+}
+
+// selectRules keeps the named rules, enabling them even if the config
+// disables them.
+func selectRules(rules []cleanup.Rule, names []string) ([]cleanup.Rule, error) {
+    var out []cleanup.Rule
+    for _, name := range names {
+        found := false
+        for _, r := range rules {
+            if r.Name == name {
+                r.Disabled = false
+                out = append(out, r)
+                found = true
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("unknown sysclean rule %q", name)
+        }
+    }
+    return out, nil
+}
+
+func printRules(p cleanup.Policy) {
+    var rows [][]string
+    for _, r := range p.Rules {
+        state := "enabled"
+        if r.Disabled {
+            state = "disabled"
+        }
+        limits := ""
+        if r.MinSize > 0 {
+            limits += ">= " + human(uint64(r.MinSize)) + " "
+        }
+        if r.MaxSize > 0 {
+            limits += "<= " + human(uint64(r.MaxSize))
+        }
+        rows = append(rows, []string{r.Name, state, strings.Join(r.Paths, " "), r.MinAge.String(), r.Owner,
+            strings.TrimSpace(limits), strings.Join(r.Exclude, " ")})
+    }
+    utils.Print([]string{"Rule", "State", "Paths", "Min age", "Owner", "Size", "Exclude"}, rows)
+    fmt.Println("Protected:", strings.Join(p.Protected, " "))
 }
 
 func showDiskUsage() {
@@ -129,44 +194,39 @@ func showLargest(root string) {
     utils.Print(headers, rows)
 }
 
-func collectTargets() []deleteItem {
-    patterns := []string{
-        "/tmp/*",
-        "/var/tmp/*",
-        "/var/cache/apt/archives/*.deb",
-        "/var/cache/yum/*",
-        "/var/cache/dnf/*",
-        "/var/log/*.log.*",
-    }
-    var items []deleteItem
-    for _, pat := range patterns {
-        matches, _ := filepath.Glob(pat)
-        for _, m := range matches {
-            var sz int64
-            filepath.Walk(m, func(_ string, info os.FileInfo, err error) error {
-                if err == nil {
-                    sz += info.Size()
-                }
-                return nil
-            })
-            items = append(items, deleteItem{m, sz})
+// previewTargets prints the items to delete and, with --show-skipped, the
+// kept ones with the reason. It returns the number of items to delete.
+func previewTargets(items []cleanup.Item) int {
+    fmt.Println("\nItems to delete:")
+    var rows, skipped [][]string
+    var total int64
+    now := time.Now()
+    for _, it := range items {
+        if it.Skip != "" {
+            skipped = append(skipped, []string{it.Path, it.Rule, it.Skip})
+            continue
         }
+        age := now.Sub(it.MTime).Truncate(time.Hour)
+        rows = append(rows, []string{it.Path, it.Rule, human(uint64(it.Size)), ageString(age)})
+        total += it.Size
+    }
+    utils.Print([]string{"Path", "Rule", "Size", "Age"}, rows)
+    fmt.Printf("TOTAL: %s\n", human(uint64(total)))
+    if showSkipped && len(skipped) > 0 {
+        fmt.Println("\nKept:")
+        utils.Print([]string{"Path", "Rule", "Reason"}, skipped)
+    } else if len(skipped) > 0 {
+        fmt.Printf("%d items kept (--show-skipped to list)\n", len(skipped))
     }
-    sort.Slice(items, func(i, j int) bool { return items[i].Size > items[j].Size })
-    return items
+    return len(rows)
 }
 
-func previewTargets(it []deleteItem) {
-    fmt.Println("\nItems to delete:")
-    headers := []string{"Path", "Size"}
-    var rows [][]string
-    var total int64
-    for _, d := range it {
-        rows = append(rows, []string{d.Path, human(uint64(d.Size))})
-        total += d.Size
+// ageString formats d in whole days once it exceeds two days.
+func ageString(d time.Duration) string {
+    if d >= 48*time.Hour {
+        return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
     }
-    utils.Print(headers, rows)
-    fmt.Printf("TOTAL: %s\n", human(uint64(total)))
+    return d.String()
 }
 
 func confirm(msg string) bool {
@@ -177,17 +237,26 @@ func confirm(msg string) bool {
     return in == "y" || in == "yes"
 }
 
-func deleteTargets(it []deleteItem) {
+func deleteTargets(items []cleanup.Item) {
     fmt.Println("\nDeleting:")
-    for _, d := range it {
-        fmt.Printf("%s (%s)\n", d.Path, human(uint64(d.Size)))
-        os.RemoveAll(d.Path)
-    }
+    cleanup.Remove(items, func(it cleanup.Item, err error) {
+        switch {
+        case err != nil:
+            fmt.Printf("%s: %v\n", it.Path, err)
+        case it.Skip != "":
+            fmt.Printf("%s: kept, %s\n", it.Path, it.Skip)
+        default:
+            fmt.Printf("%s (%s)\n", it.Path, human(uint64(it.Size)))
+        }
+    })
 }
 
 func init() {
     syscleanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "preview items to be cleaned")
     syscleanCmd.Flags().BoolVar(&force, "force", false, "force deletion without confirmation")
     syscleanCmd.Flags().BoolVar(&auto, "auto", false, "non-interactive mode for cron")
+    syscleanCmd.Flags().StringSliceVar(&cleanRules, "rule", nil, "only run these rules (enables them if disabled)")
+    syscleanCmd.Flags().BoolVar(&showSkipped, "show-skipped", false, "list matched items that are kept and why")
+    syscleanCmd.Flags().BoolVar(&listRules, "list-rules", false, "print the effective rules and protected paths")
 }
 
@@ -7,6 +7,7 @@ import (
     "time"
 
     "syskit/internal/ui"
+    "syskit/internal/utils"
 
     "github.com/spf13/cobra"
 )
@@ -18,9 +19,14 @@ type event struct {
     Msg      string
 }
 
-// gatherEvents collects logs for last 24h (same logic as previous implementation). Implementation kept in separate file.
+var (
+    tlSince string
+    tlUntil string
+)
+
+// gatherEvents collects logs for the requested window. Implementation kept in separate file.
 
-func buildTimelineGroups(evs []event) map[string][]string {
+func buildTimelineGroups(evs []event, layout string) map[string][]string {
     for i := range evs {
         e := &evs[i]
         switch e.Src {
@@ -41,27 +47,56 @@ func buildTimelineGroups(evs []event) map[string][]string {
             } else {
                 e.Category = "dmesg"
             }
+        case "wtmp":
+            if strings.Contains(e.Msg, "shutdown") {
+                e.Category = "shutdown"
+            } else {
+                e.Category = "boot"
+            }
         default:
             e.Category = e.Src
         }
     }
+    // sort chronologically before grouping; string order breaks across days
+    sort.SliceStable(evs, func(i, j int) bool { return evs[i].When.Before(evs[j].When) })
     groups := make(map[string][]string)
     for _, e := range evs {
-        groups[e.Category] = append(groups[e.Category], fmt.Sprintf("[%s] %s", e.When.Format("15:04"), e.Msg))
-    }
-    // sort each slice chronologically
-    for _, list := range groups {
-        sort.Strings(list)
+        groups[e.Category] = append(groups[e.Category], fmt.Sprintf("[%s] %s", e.When.Format(layout), e.Msg))
     }
     return groups
 }
 
 var timelineCmd = &cobra.Command{
     Use:   "timeline",
-    Short: "Show system event timeline (24h)",
+    Short: "Show system event timeline (default: last 24h)",
     RunE: func(cmd *cobra.Command, args []string) error {
-        evs := gatherEvents()
-        groups := buildTimelineGroups(evs)
+        now := time.Now()
+        since, err := utils.ParseTime(tlSince, now)
+        if err != nil {
+            return err
+        }
+        until, err := utils.ParseTime(tlUntil, now)
+        if err != nil {
+            return err
+        }
+        if !until.IsZero() && since.After(until) {
+            return fmt.Errorf("--since is after --until")
+        }
+        end := until
+        if end.IsZero() {
+            end = now
+        }
+        layout := "15:04"
+        if end.Sub(since) > 24*time.Hour {
+            layout = "01-02 15:04"
+        }
+        evs := gatherEvents(since, until)
+        groups := buildTimelineGroups(evs, layout)
         return ui.RunTimeline(groups)
     },
 }
+
+func init() {
+    timelineCmd.Flags().StringVar(&tlSince, "since", "24h", "start of window (duration ago like 6h/2d, or YYYY-MM-DD [HH:MM], or RFC3339)")
+    timelineCmd.Flags().StringVar(&tlUntil, "until", "", "end of window (same formats as --since; default now)")
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+    "fmt"
+    "os"
+    "time"
+
+    "syskit/internal/timeline"
+)
+
+// gatherEvents runs all timeline collectors for the [since, until] window.
+// Collector failures (e.g. /dev/kmsg without privileges) are reported on
+// stderr but do not abort the timeline.
+func gatherEvents(since, until time.Time) []event {
+    evs, errs := timeline.Gather(timeline.Default(), since, until)
+    for _, err := range errs {
+        fmt.Fprintln(os.Stderr, "timeline:", err)
+    }
+    out := make([]event, 0, len(evs))
+    for _, e := range evs {
+        out = append(out, event{When: e.When, Src: e.Src, Msg: e.Msg})
+    }
+    return out
+}
@@ -1,18 +1,92 @@
 package cmd
 
 import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
     tea "github.com/charmbracelet/bubbletea"
     "syskit/internal/ui"
+    "syskit/internal/utils"
 
     "github.com/spf13/cobra"
 )
 
+var (
+    watchUsers   bool
+    usersHistory bool
+    usersLimit   int
+)
+
 var usersCmd = &cobra.Command{
 	Use:   "users",
 	Short: "Show active users",
-	Run: func(cmd *cobra.Command, args []string) {
-        m := ui.NewUsersModel(false)
-        p := tea.NewProgram(m)
-        _ = p.Start()
+	Long: `Current sessions from /var/run/utmp with login time, idle time, remote host
+and session process. --history reads /var/log/wtmp and /var/log/btmp for past
+logins, reboots and failed attempts with durations and per-user totals
+(btmp is only readable by root).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+        if !interactive(cmd, watchUsers) {
+            if usersHistory {
+                h, err := ui.ReadUsersHistory(usersLimit)
+                if err != nil {
+                    return err
+                }
+                utils.PrintValue(h, func() { printUsersHistory(h) })
+                return nil
+            }
+            list, err := ui.ReadUsers()
+            if err != nil {
+                return err
+            }
+            utils.PrintValue(list, func() {
+                var rows [][]string
+                for _, r := range list {
+                    rows = append(rows, []string{r.User, r.TTY, r.Host, r.Login.Format(time.DateTime), ui.IdleString(r.IdleSeconds), strconv.Itoa(r.PID), r.Process})
+                }
+                utils.Print([]string{"user", "terminal", "From", "Login", "Idle", "pid", "Process"}, rows)
+            })
+            return nil
+        }
+        m := ui.NewUsersModel(watchUsers, usersHistory, usersLimit)
+        _, err := tea.NewProgram(m).Run()
+        return err
     },
 }
+
+func init() {
+    usersCmd.Flags().BoolVarP(&watchUsers, "watch", "w", false, "watch mode (refresh)")
+    usersCmd.Flags().BoolVar(&usersHistory, "history", false, "past logins, reboots and failed attempts from wtmp/btmp")
+    usersCmd.Flags().IntVarP(&usersLimit, "limit", "n", 20, "most recent sessions and failed attempts to list (0 = all)")
+}
+
+func printUsersHistory(h ui.UsersHistory) {
+    for _, w := range h.Warnings {
+        fmt.Println("warning:", w)
+    }
+    var rows [][]string
+    for _, s := range h.Sessions {
+        logout := s.End
+        if !s.Logout.IsZero() {
+            logout = s.Logout.Format(time.DateTime) + " (" + s.End + ")"
+        }
+        rows = append(rows, []string{s.User, s.TTY, s.Host, s.Login.Format(time.DateTime), logout, ui.ShortDuration(time.Duration(s.DurationSeconds) * time.Second)})
+    }
+    utils.Print([]string{"user", "terminal", "From", "Login", "Logout", "Duration"}, rows)
+    rows = nil
+    for _, f := range h.Failed {
+        rows = append(rows, []string{f.User, f.TTY, f.Host, f.Time.Format(time.DateTime)})
+    }
+    fmt.Println("Failed logins")
+    utils.Print([]string{"user", "terminal", "From", "Time"}, rows)
+    rows = nil
+    for _, u := range h.Users {
+        last := ""
+        if !u.LastLogin.IsZero() {
+            last = u.LastLogin.Format(time.DateTime)
+        }
+        rows = append(rows, []string{u.User, strconv.Itoa(u.Logins), strconv.Itoa(u.Failed), ui.ShortDuration(time.Duration(u.TotalSeconds) * time.Second), last, strings.Join(u.Hosts, ",")})
+    }
+    utils.Print([]string{"user", "Logins", "Failed", "Total", "Last login", "Hosts"}, rows)
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
     "fmt"
+    "os"
     "os/exec"
     "strings"
+    "syskit/internal/counters"
+    "syskit/internal/exporter"
     "syskit/internal/i18n"
     "time"
 
@@ -45,9 +48,15 @@ func onceWatch(svc string) {
     exec.Command("systemctl", "restart", svc).Run()
     time.Sleep(3 * time.Second)
     if isActive(svc) {
+        if err := counters.Inc(exporter.WatchdogRestarts, "service", svc, "result", "success"); err != nil {
+            fmt.Fprintln(os.Stderr, "counters:", err)
+        }
         fmt.Println(i18n.T("restart_success"))
         return
     }
+    if err := counters.Inc(exporter.WatchdogRestarts, "service", svc, "result", "failed"); err != nil {
+        fmt.Fprintln(os.Stderr, "counters:", err)
+    }
     fmt.Println(i18n.T("restart_failed"))
 
     hint := analyseFailure(svc)
@@ -0,0 +1,143 @@
+// Package agent runs a set of periodic watchers concurrently and supports
+// rebuilding them on reload.
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Watcher is a check executed every Interval.
+type Watcher interface {
+	Name() string
+	Interval() time.Duration
+	Check(ctx context.Context) error
+}
+
+// Func adapts a plain function to the Watcher interface.
+type Func struct {
+	ID    string
+	Every time.Duration
+	Fn    func(ctx context.Context) error
+}
+
+func (f Func) Name() string                    { return f.ID }
+func (f Func) Interval() time.Duration         { return f.Every }
+func (f Func) Check(ctx context.Context) error { return f.Fn(ctx) }
+
+// Runner owns the watcher goroutines.
+type Runner struct {
+	// Build returns the watcher set; it is called at start and on every reload.
+	Build func() ([]Watcher, error)
+	// Activate, if set, is called after a successful Build once the
+	// previous watchers have stopped and before the new ones start, so
+	// state shared with them can be swapped without a race.
+	Activate func()
+	Log      *log.Logger
+}
+
+// Run starts all watchers and blocks until ctx is cancelled. A value on
+// reload stops the current watchers, waits for in-flight checks and starts
+// a freshly built set. If a rebuild fails the previous set keeps running.
+func (r *Runner) Run(ctx context.Context, reload <-chan struct{}) error {
+	ws, err := r.Build()
+	if err != nil {
+		return err
+	}
+	r.activate()
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		for _, w := range ws {
+			wg.Add(1)
+			go func(w Watcher) {
+				defer wg.Done()
+				r.loop(runCtx, w)
+			}(w)
+		}
+		r.Log.Printf("agent: running %d watcher(s)", len(ws))
+
+		var next []Watcher
+		for rebuilt := false; !rebuilt; {
+			select {
+			case <-ctx.Done():
+				cancel()
+				wg.Wait()
+				r.Log.Printf("agent: stopped")
+				return nil
+			case <-reload:
+				if next, err = r.Build(); err != nil {
+					r.Log.Printf("agent: reload failed, keeping current watchers: %v", err)
+					continue
+				}
+				rebuilt = true
+			}
+		}
+		cancel()
+		wg.Wait()
+		ws = next
+		r.activate()
+		r.Log.Printf("agent: reloaded")
+	}
+}
+
+func (r *Runner) activate() {
+	if r.Activate != nil {
+		r.Activate()
+	}
+}
+
+func (r *Runner) loop(ctx context.Context, w Watcher) {
+	iv := w.Interval()
+	if iv <= 0 {
+		iv = time.Minute
+	}
+	t := time.NewTicker(iv)
+	defer t.Stop()
+	for {
+		if err := r.check(ctx, w); err != nil {
+			r.Log.Printf("%s: %v", w.Name(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// check runs one iteration, turning a panic into an error so a single
+// misbehaving watcher cannot take the daemon down.
+func (r *Runner) check(ctx context.Context, w Watcher) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return w.Check(ctx)
+}
+
+// Unit renders a systemd service unit that runs exe as the agent. home is
+// exported as HOME so the daemon reads the same ~/.syskit/config.yaml.
+func Unit(exe, home string) string {
+	return fmt.Sprintf(`[Unit]
+Description=syskit agent
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+Environment=HOME=%s
+ExecStart=%s agent run
+ExecReload=/bin/kill -HUP $MAINPID
+KillSignal=SIGTERM
+Restart=on-failure
+RestartSec=5s
+
+[Install]
+WantedBy=multi-user.target
+`, home, exe)
+}
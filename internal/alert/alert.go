@@ -0,0 +1,161 @@
+// Package alert evaluates threshold rules against metric samples and turns
+// sustained breaches into firing/resolved events.
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rule fires when the sample named Metric is >= Threshold for at least For,
+// and resolves once it has stayed below for at least ClearFor, so a value
+// hovering around the threshold does not flap.
+type Rule struct {
+	Name      string
+	Metric    string
+	Threshold float64
+	Unit      string // "%" or "KB/s", for messages only
+	For       time.Duration
+	ClearFor  time.Duration
+}
+
+// State of a rule.
+type State int
+
+const (
+	Inactive State = iota
+	Pending
+	Firing
+)
+
+func (s State) String() string {
+	return [...]string{"ok", "pending", "firing"}[s]
+}
+
+// Kind of a transition event.
+const (
+	KindFiring   = "firing"
+	KindResolved = "resolved"
+)
+
+// Event is emitted when a rule starts firing, repeats, or resolves.
+type Event struct {
+	Kind  string
+	Rule  Rule
+	Value float64
+	Since time.Time // start of the breach
+	At    time.Time
+}
+
+// String renders a one-line description.
+func (e Event) String() string {
+	if e.Kind == KindResolved {
+		return fmt.Sprintf("RESOLVED %s: %.1f%s (threshold %.0f%s)", e.Rule.Name, e.Value, e.Rule.Unit, e.Rule.Threshold, e.Rule.Unit)
+	}
+	return fmt.Sprintf("FIRING %s: %.1f%s >= %.0f%s for %s", e.Rule.Name, e.Value, e.Rule.Unit, e.Rule.Threshold, e.Rule.Unit,
+		e.At.Sub(e.Since).Truncate(time.Second))
+}
+
+type ruleState struct {
+	state    State
+	since    time.Time
+	notified time.Time
+	clearing time.Time // first sample below the threshold while firing
+	value    float64
+}
+
+// Engine tracks rule state across evaluations.
+type Engine struct {
+	rules  []Rule
+	states map[string]*ruleState
+	// Repeat re-emits a firing event while a rule stays firing; zero means
+	// only the initial transition is reported.
+	Repeat time.Duration
+}
+
+// NewEngine returns an engine for rules.
+func NewEngine(rules []Rule) *Engine {
+	e := &Engine{rules: rules, states: map[string]*ruleState{}}
+	for _, r := range rules {
+		e.states[r.Name] = &ruleState{}
+	}
+	return e
+}
+
+// Rules returns the configured rules.
+func (e *Engine) Rules() []Rule { return e.rules }
+
+// Evaluate updates every rule with the current samples. Rules whose metric is
+// missing from samples keep their state.
+func (e *Engine) Evaluate(samples map[string]float64, now time.Time) []Event {
+	var evs []Event
+	for _, r := range e.rules {
+		v, ok := samples[r.Metric]
+		if !ok {
+			continue
+		}
+		st := e.states[r.Name]
+		st.value = v
+		if v < r.Threshold {
+			if st.state == Firing {
+				if st.clearing.IsZero() {
+					st.clearing = now
+				}
+				if now.Sub(st.clearing) < r.ClearFor {
+					continue
+				}
+				evs = append(evs, Event{Kind: KindResolved, Rule: r, Value: v, Since: st.since, At: now})
+			}
+			*st = ruleState{value: v}
+			continue
+		}
+		st.clearing = time.Time{}
+		switch st.state {
+		case Inactive:
+			st.state, st.since = Pending, now
+			fallthrough
+		case Pending:
+			if now.Sub(st.since) >= r.For {
+				st.state, st.notified = Firing, now
+				evs = append(evs, Event{Kind: KindFiring, Rule: r, Value: v, Since: st.since, At: now})
+			}
+		case Firing:
+			if e.Repeat > 0 && now.Sub(st.notified) >= e.Repeat {
+				st.notified = now
+				evs = append(evs, Event{Kind: KindFiring, Rule: r, Value: v, Since: st.since, At: now})
+			}
+		}
+	}
+	return evs
+}
+
+// Status describes the current state of one rule.
+type Status struct {
+	Rule  Rule
+	State State
+	Value float64
+	Since time.Time
+}
+
+// Status returns the state of all rules sorted by name.
+func (e *Engine) Status() []Status {
+	out := make([]Status, 0, len(e.rules))
+	for _, r := range e.rules {
+		st := e.states[r.Name]
+		out = append(out, Status{Rule: r, State: st.state, Value: st.value, Since: st.since})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Rule.Name < out[j].Rule.Name })
+	return out
+}
+
+// Firing returns the rules currently firing.
+func (e *Engine) Firing() []Status {
+	var out []Status
+	for _, s := range e.Status() {
+		if s.State == Firing {
+			out = append(out, s)
+		}
+	}
+	return out
+}
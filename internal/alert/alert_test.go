@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	rule := Rule{Name: "cpu", Metric: MetricCPU, Threshold: 90, For: 10 * time.Second, ClearFor: 20 * time.Second}
+	t0 := time.Unix(1709280000, 0)
+	steps := []struct {
+		sec   int
+		value float64
+		want  string // kind of the emitted event, "" for none
+		state State
+	}{
+		{0, 95, "", Pending},
+		{5, 80, "", Inactive}, // dropped before for: elapsed
+		{10, 95, "", Pending},
+		{20, 96, KindFiring, Firing},
+		{25, 85, "", Firing}, // below, clear_for not yet elapsed
+		{30, 91, "", Firing}, // back above restarts the clear timer
+		{35, 85, "", Firing},
+		{50, 70, "", Firing},
+		{55, 70, KindResolved, Inactive},
+		{60, 70, "", Inactive},
+	}
+	eng := NewEngine([]Rule{rule})
+	for _, st := range steps {
+		evs := eng.Evaluate(map[string]float64{MetricCPU: st.value}, t0.Add(time.Duration(st.sec)*time.Second))
+		var got string
+		if len(evs) > 1 {
+			t.Fatalf("t+%ds: %d events", st.sec, len(evs))
+		}
+		if len(evs) == 1 {
+			got = evs[0].Kind
+		}
+		if got != st.want {
+			t.Errorf("t+%ds: event %q, want %q", st.sec, got, st.want)
+		}
+		if s := eng.Status()[0].State; s != st.state {
+			t.Errorf("t+%ds: state %s, want %s", st.sec, s, st.state)
+		}
+	}
+}
+
+func TestEvaluateRepeat(t *testing.T) {
+	eng := NewEngine([]Rule{{Name: "ram", Metric: MetricRAM, Threshold: 90}})
+	eng.Repeat = time.Minute
+	t0 := time.Unix(1709280000, 0)
+	var kinds []string
+	for _, sec := range []int{0, 30, 60, 90, 120} {
+		for _, e := range eng.Evaluate(map[string]float64{MetricRAM: 95}, t0.Add(time.Duration(sec)*time.Second)) {
+			kinds = append(kinds, e.Kind)
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "firing,firing,firing" {
+		t.Errorf("got %s", got)
+	}
+	// without clear_for a single low sample resolves
+	evs := eng.Evaluate(map[string]float64{MetricRAM: 10}, t0.Add(130*time.Second))
+	if len(evs) != 1 || evs[0].Kind != KindResolved {
+		t.Errorf("got %v", evs)
+	}
+	// missing samples keep the state
+	if evs := eng.Evaluate(map[string]float64{}, t0.Add(140*time.Second)); len(evs) != 0 {
+		t.Errorf("got %v", evs)
+	}
+}
@@ -0,0 +1,166 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"syskit/internal/config"
+	"syskit/internal/metrics"
+	"syskit/internal/notify"
+)
+
+// Metric keys produced by Sampler.
+const (
+	MetricCPU = "cpu"
+	MetricRAM = "ram"
+)
+
+// DiskMetric is the key for usage of the filesystem mounted at mountpoint.
+func DiskMetric(mountpoint string) string { return "disk:" + mountpoint }
+
+// NetMetric is the key for an interface direction (rx|tx) rate in KB/s.
+func NetMetric(iface, dir string) string { return "net_" + dir + ":" + iface }
+
+// DefaultFor is how long a threshold must be exceeded, and then cleared,
+// when thresholds.for is not set.
+const DefaultFor = time.Minute
+
+// RulesFromConfig builds rules from config.Thresholds. The global disk
+// threshold applies to "/" unless overridden in Disks. for defaults to
+// DefaultFor and clear_for to for, so a value hovering around a threshold
+// does not notify on every crossing.
+func RulesFromConfig(cfg *config.Config) ([]Rule, error) {
+	t := cfg.Thresholds
+	forDur := DefaultFor
+	if t.For != "" {
+		d, err := time.ParseDuration(t.For)
+		if err != nil {
+			return nil, fmt.Errorf("thresholds.for: %w", err)
+		}
+		forDur = d
+	}
+	clearFor := forDur
+	if t.ClearFor != "" {
+		d, err := time.ParseDuration(t.ClearFor)
+		if err != nil {
+			return nil, fmt.Errorf("thresholds.clear_for: %w", err)
+		}
+		clearFor = d
+	}
+	var rules []Rule
+	add := func(name, metric string, limit int, unit string) {
+		if limit > 0 {
+			rules = append(rules, Rule{Name: name, Metric: metric, Threshold: float64(limit), Unit: unit,
+				For: forDur, ClearFor: clearFor})
+		}
+	}
+	add("cpu", MetricCPU, t.CPU, "%")
+	add("ram", MetricRAM, t.RAM, "%")
+	disks := map[string]int{"/": t.Disk}
+	for mp, v := range t.Disks {
+		disks[mp] = v
+	}
+	for _, mp := range sortedKeys(disks) {
+		add("disk "+mp, DiskMetric(mp), disks[mp], "%")
+	}
+	for _, iface := range sortedKeys(t.Interfaces) {
+		add("net "+iface+" rx", NetMetric(iface, "rx"), t.Interfaces[iface], "KB/s")
+		add("net "+iface+" tx", NetMetric(iface, "tx"), t.Interfaces[iface], "KB/s")
+	}
+	return rules, nil
+}
+
+// RepeatFromConfig parses thresholds.repeat (zero when unset).
+func RepeatFromConfig(cfg *config.Config) (time.Duration, error) {
+	if cfg.Thresholds.Repeat == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(cfg.Thresholds.Repeat)
+}
+
+// Sampler produces the metric map rules are evaluated against. CPU and
+// network rates need a previous call and are absent on the first one.
+type Sampler struct {
+	FS      metrics.FS
+	prevCPU metrics.CPUStat
+	prevNet map[string]metrics.NetDev
+	prevAt  time.Time
+}
+
+// NewSampler returns a sampler for the live system.
+func NewSampler() *Sampler {
+	return &Sampler{FS: metrics.Default}
+}
+
+// Sample reads metrics needed by rules.
+func (s *Sampler) Sample(rules []Rule, now time.Time) map[string]float64 {
+	out := map[string]float64{}
+	first := s.prevAt.IsZero()
+	if st, err := s.FS.Stat(); err == nil {
+		if !first {
+			out[MetricCPU] = metrics.Busy(s.prevCPU, st.Total)
+		}
+		s.prevCPU = st.Total
+	}
+	if mi, err := s.FS.MemInfo(); err == nil {
+		out[MetricRAM] = mi.UsedPercent()
+	}
+	for _, r := range rules {
+		if mp := strings.TrimPrefix(r.Metric, "disk:"); mp != r.Metric {
+			if du, err := metrics.DiskUsage(mp); err == nil {
+				out[r.Metric] = du.UsedPercent()
+			}
+		}
+	}
+	if devs, err := s.FS.NetDev(); err == nil {
+		secs := now.Sub(s.prevAt).Seconds()
+		cur := map[string]metrics.NetDev{}
+		for _, d := range devs {
+			cur[d.Name] = d
+			if p, ok := s.prevNet[d.Name]; ok && !first && secs > 0 && d.RxBytes >= p.RxBytes && d.TxBytes >= p.TxBytes {
+				out[NetMetric(d.Name, "rx")] = float64(d.RxBytes-p.RxBytes) / 1024 / secs
+				out[NetMetric(d.Name, "tx")] = float64(d.TxBytes-p.TxBytes) / 1024 / secs
+			}
+		}
+		s.prevNet = cur
+	}
+	s.prevAt = now
+	return out
+}
+
+// Route delivers events to the channels listed in thresholds.notify (all
+// configured channels when empty).
+func Route(cfg *config.Config, evs []Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	var lines []string
+	firing := 0
+	for _, e := range evs {
+		lines = append(lines, e.String())
+		if e.Kind == KindFiring {
+			firing++
+		}
+	}
+	severity := notify.Info
+	if firing > 0 {
+		severity = notify.Critical
+	}
+	return notify.Dispatch(cfg, cfg.Thresholds.Notify, notify.Message{
+		Subject:  fmt.Sprintf("Syskit alert: %d firing, %d resolved", firing, len(evs)-firing),
+		Body:     strings.Join(lines, "\n"),
+		Severity: severity,
+		Source:   "thresholds",
+	})
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,72 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"syskit/internal/config"
+)
+
+func TestRulesFromConfigDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no config.yaml: the built-in defaults
+	cfg, err := config.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Thresholds.Disks = map[string]int{"/var": 80}
+	rules, err := RulesFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range rules {
+		names = append(names, r.Name)
+		if r.For != DefaultFor || r.ClearFor != DefaultFor {
+			t.Errorf("%s: for %v clear_for %v, want %v", r.Name, r.For, r.ClearFor, DefaultFor)
+		}
+	}
+	if got, want := len(rules), 4; got != want {
+		t.Errorf("rules %v, want cpu, ram, disk / and disk /var", names)
+	}
+
+	// a value hovering around the default threshold stays quiet
+	eng := NewEngine(rules)
+	t0 := time.Unix(1709280000, 0)
+	for i := 0; i < 120; i++ {
+		v := 89.0
+		if i%2 == 0 {
+			v = 91
+		}
+		if evs := eng.Evaluate(map[string]float64{MetricCPU: v}, t0.Add(time.Duration(i)*time.Second)); len(evs) > 0 {
+			t.Fatalf("t+%ds: %v", i, evs)
+		}
+	}
+}
+
+func TestRulesFromConfigDurations(t *testing.T) {
+	tests := []struct {
+		forS, clearS string
+		wantFor      time.Duration
+		wantClear    time.Duration
+		wantErr      bool
+	}{
+		{"5m", "", 5 * time.Minute, 5 * time.Minute, false},
+		{"0s", "", 0, 0, false},
+		{"", "30s", DefaultFor, 30 * time.Second, false},
+		{"soon", "", 0, 0, true},
+		{"", "later", 0, 0, true},
+	}
+	for _, tt := range tests {
+		cfg := &config.Config{}
+		cfg.Thresholds.CPU = 90
+		cfg.Thresholds.For, cfg.Thresholds.ClearFor = tt.forS, tt.clearS
+		rules, err := RulesFromConfig(cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("for %q clear_for %q: err = %v", tt.forS, tt.clearS, err)
+			continue
+		}
+		if err == nil && (rules[0].For != tt.wantFor || rules[0].ClearFor != tt.wantClear) {
+			t.Errorf("for %q clear_for %q: got %v/%v", tt.forS, tt.clearS, rules[0].For, rules[0].ClearFor)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+// +build linux
+
+package cleanup
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileUID returns the owner of info, or -1 if unknown.
+func fileUID(info os.FileInfo) int {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int(st.Uid)
+	}
+	return -1
+}
+
+// fileCTime returns the last status change of info, or the zero time if
+// unknown.
+func fileCTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec))
+	}
+	return time.Time{}
+}
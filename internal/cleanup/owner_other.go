@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package cleanup
+
+import (
+	"os"
+	"time"
+)
+
+// fileUID is unknown off Linux, so owner-restricted rules match nothing.
+func fileUID(info os.FileInfo) int { return -1 }
+
+// fileCTime is unknown off Linux; ages then rest on the mtime alone.
+func fileCTime(info os.FileInfo) time.Time { return time.Time{} }
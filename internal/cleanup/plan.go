@@ -0,0 +1,220 @@
+package cleanup
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"syskit/internal/procs"
+)
+
+// Item is a cleanup candidate. Skip is empty for items to delete and
+// otherwise says why the item is kept.
+type Item struct {
+	Path  string    `json:"path" yaml:"path"`
+	Rule  string    `json:"rule" yaml:"rule"`
+	Size  int64     `json:"size" yaml:"size"`
+	MTime time.Time `json:"mtime" yaml:"mtime"` // newest modification or status change in the item
+	Owner string    `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Skip  string    `json:"skip,omitempty" yaml:"skip,omitempty"`
+}
+
+// Plan expands the enabled rules and checks every match. open is the set of
+// paths held open by processes (see OpenFiles). Items are returned largest
+// first; a path matched by several rules is reported once, for the first.
+func (p Policy) Plan(open map[string]bool, now time.Time) []Item {
+	var items []Item
+	seen := map[string]bool{}
+	for _, r := range p.Rules {
+		if r.Disabled {
+			continue
+		}
+		for _, pat := range r.Paths {
+			matches, _ := filepath.Glob(pat)
+			for _, m := range matches {
+				m = filepath.Clean(m)
+				if seen[m] {
+					continue
+				}
+				seen[m] = true
+				items = append(items, p.check(r, m, open, now))
+			}
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Size > items[j].Size })
+	return items
+}
+
+func (p Policy) check(r Rule, path string, open map[string]bool, now time.Time) Item {
+	it := Item{Path: path, Rule: r.Name}
+	if pat := p.protects(path); pat != "" {
+		it.Skip = "protected (" + pat + ")"
+		return it
+	}
+	for _, pat := range r.Exclude {
+		if matchPath(pat, path) {
+			it.Skip = "excluded (" + pat + ")"
+			return it
+		}
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		it.Skip = err.Error()
+		return it
+	}
+	if uid := fileUID(info); uid >= 0 {
+		it.Owner = procs.UserName(uid)
+	}
+	if r.Owner != "" && it.Owner != r.Owner {
+		it.Skip = "owner " + it.Owner
+		return it
+	}
+	it.Skip = scan(path, open, &it)
+	if it.Skip != "" {
+		return it
+	}
+	switch {
+	case now.Sub(it.MTime) < r.MinAge:
+		it.Skip = "modified " + now.Sub(it.MTime).Truncate(time.Minute).String() + " ago"
+	case r.MinSize > 0 && it.Size < r.MinSize:
+		it.Skip = "below min_size"
+	case r.MaxSize > 0 && it.Size > r.MaxSize:
+		it.Skip = "above max_size"
+	}
+	return it
+}
+
+// scan walks path without following symlinks, summing sizes and tracking
+// the newest modification time. The ctime counts as a modification, since
+// tar, cp -p and rsync -a restore old mtimes on files they just created.
+// It returns a reason to keep the item if
+// any file inside is open, a socket, a FIFO or a device node.
+func scan(path string, open map[string]bool, it *Item) string {
+	reason := ""
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			reason = err.Error()
+			return filepath.SkipAll
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		switch mode := info.Mode(); {
+		case mode&os.ModeSocket != 0:
+			reason = "contains socket " + p
+		case mode&os.ModeNamedPipe != 0:
+			reason = "contains FIFO " + p
+		case mode&(os.ModeDevice|os.ModeCharDevice) != 0:
+			reason = "contains device " + p
+		case open[p]:
+			reason = "in use: " + p
+		}
+		if reason != "" {
+			return filepath.SkipAll
+		}
+		if mode := info.Mode(); mode.IsRegular() {
+			it.Size += info.Size()
+		}
+		changed := info.ModTime()
+		if c := fileCTime(info); c.After(changed) {
+			changed = c
+		}
+		if changed.After(it.MTime) {
+			it.MTime = changed
+		}
+		return nil
+	})
+	return reason
+}
+
+// protects returns the protected pattern that covers path: one matching it
+// or one that may match something below it.
+func (p Policy) protects(path string) string {
+	for _, pat := range p.Protected {
+		if matchPath(pat, path) || below(pat, path) {
+			return pat
+		}
+	}
+	return ""
+}
+
+// below reports whether pat can match a path strictly inside dir.
+func below(pat, dir string) bool {
+	if dir == "/" {
+		return true
+	}
+	pp := strings.Split(strings.Trim(pat, "/"), "/")
+	dp := strings.Split(strings.Trim(dir, "/"), "/")
+	if !strings.HasPrefix(pat, "/") || len(dp) >= len(pp) {
+		return false
+	}
+	for i, c := range dp {
+		if ok, _ := filepath.Match(pp[i], c); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPath matches a glob against the full path, or against the base name
+// when the glob has no slash.
+func matchPath(pat, path string) bool {
+	if ok, _ := filepath.Match(pat, path); ok {
+		return true
+	}
+	if !strings.Contains(pat, "/") {
+		ok, _ := filepath.Match(pat, filepath.Base(path))
+		return ok
+	}
+	return false
+}
+
+// OpenFiles returns the paths every process holds open, read from
+// /proc/<pid>/fd, plus their working directories. Processes we may not
+// inspect (other users' without root) are missed, so run sysclean as root.
+func OpenFiles() map[string]bool {
+	open := map[string]bool{}
+	pids, _ := filepath.Glob("/proc/[0-9]*")
+	for _, dir := range pids {
+		if cwd, err := os.Readlink(filepath.Join(dir, "cwd")); err == nil {
+			open[cwd] = true
+		}
+		fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+			if err == nil && strings.HasPrefix(target, "/") {
+				open[strings.TrimSuffix(target, " (deleted)")] = true
+			}
+		}
+	}
+	return open
+}
+
+// Remove deletes the items without a Skip reason and returns the bytes
+// freed. Open files are checked again since time may have passed since
+// Plan. report is called for each item deleted, failed or skipped now.
+func Remove(items []Item, report func(it Item, err error)) int64 {
+	open := OpenFiles()
+	var freed int64
+	for _, it := range items {
+		if it.Skip != "" {
+			continue
+		}
+		var again Item
+		if reason := scan(it.Path, open, &again); reason != "" {
+			it.Skip = reason
+			report(it, nil)
+			continue
+		}
+		err := os.RemoveAll(it.Path)
+		if err == nil {
+			freed += it.Size
+		}
+		report(it, err)
+	}
+	return freed
+}
@@ -0,0 +1,196 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"syskit/internal/procs"
+)
+
+// tree creates files under root with the given sizes.
+func tree(t *testing.T, root string, files map[string]int) {
+	t.Helper()
+	for name, size := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	root := t.TempDir()
+	// the ctime cannot be backdated, so plan 20 days from now instead
+	now := time.Now().Add(20 * day)
+	tree(t, root, map[string]int{
+		"cache/old-dir/a":            1500,
+		"cache/old-dir/b":            500,
+		"cache/new-file":             1000,
+		"cache/keep.lock":            1000,
+		"cache/small":                10,
+		"cache/big":                  5000,
+		"cache/protected-dir/x":      1000,
+		"cache/parent/child/keep-me": 1000,
+		"cache/busy/f":               1000,
+		"other/x":                    1000,
+	})
+	os.Chtimes(filepath.Join(root, "cache/new-file"), now, now)
+
+	p := Policy{
+		Rules: []Rule{
+			{Name: "cache", Paths: []string{root + "/cache/*"}, MinAge: 7 * day,
+				MinSize: 100, MaxSize: 4000, Exclude: []string{"*.lock"}},
+			{Name: "disabled", Paths: []string{root + "/other/*"}, Disabled: true},
+			{Name: "owned", Paths: []string{root + "/other/*"}, Owner: "no-such-user"},
+			{Name: "again", Paths: []string{root + "/cache/old-*"}},
+		},
+		Protected: []string{root + "/cache/protected-dir", root + "/cache/parent/*/keep-*"},
+	}
+	busy := filepath.Join(root, "cache/busy/f")
+	items := p.Plan(map[string]bool{busy: true}, now)
+
+	me := procs.UserName(os.Getuid())
+	want := map[string]string{
+		"cache/old-dir":       "",
+		"cache/new-file":      "modified ",
+		"cache/keep.lock":     "excluded (*.lock)",
+		"cache/small":         "below min_size",
+		"cache/big":           "above max_size",
+		"cache/protected-dir": "protected (" + root + "/cache/protected-dir)",
+		"cache/parent":        "protected (" + root + "/cache/parent/*/keep-*)",
+		"cache/busy":          "in use: " + busy,
+		"other/x":             "owner " + me,
+	}
+	if len(items) != len(want) {
+		t.Errorf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i, it := range items {
+		rel, _ := filepath.Rel(root, it.Path)
+		w, ok := want[rel]
+		if !ok {
+			t.Errorf("unexpected item %s", rel)
+			continue
+		}
+		if w == "" && it.Skip != "" || !strings.HasPrefix(it.Skip, w) {
+			t.Errorf("%s: skip %q, want %q", rel, it.Skip, w)
+		}
+		if i > 0 && it.Size > items[i-1].Size {
+			t.Errorf("items not sorted by size at %s", rel)
+		}
+		if rel == "cache/old-dir" {
+			if it.Rule != "cache" || it.Size != 2000 || it.Owner != me || it.MTime.After(now.Add(-19*day)) {
+				t.Errorf("old-dir = %+v", it)
+			}
+		}
+		if rel == "other/x" && it.Rule != "owned" {
+			t.Errorf("other/x matched by %s, want owned", it.Rule)
+		}
+	}
+
+	var deleted []string
+	freed := Remove(items, func(it Item, err error) {
+		if err != nil {
+			t.Errorf("remove %s: %v", it.Path, err)
+		}
+		deleted = append(deleted, it.Path)
+	})
+	if freed != 2000 || len(deleted) != 1 {
+		t.Errorf("freed %d bytes from %v, want 2000 from old-dir", freed, deleted)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cache/old-dir")); !os.IsNotExist(err) {
+		t.Errorf("old-dir still there: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cache/parent/child/keep-me")); err != nil {
+		t.Errorf("protected file removed: %v", err)
+	}
+}
+
+func TestPlanRestoredMTime(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("ctime is read on Linux only")
+	}
+	// as left by tar x, cp -p or rsync -a: old mtime, fresh ctime
+	root := t.TempDir()
+	tree(t, root, map[string]int{"unpacked/file": 100})
+	old := time.Now().Add(-30 * day)
+	for _, p := range []string{"unpacked/file", "unpacked"} {
+		os.Chtimes(filepath.Join(root, p), old, old)
+	}
+	p := Policy{Rules: []Rule{{Name: "tmp", Paths: []string{root + "/*"}, MinAge: 10 * day}}}
+	items := p.Plan(nil, time.Now())
+	if len(items) != 1 || !strings.HasPrefix(items[0].Skip, "modified ") {
+		t.Errorf("got %+v, want the item kept", items)
+	}
+}
+
+func TestProtects(t *testing.T) {
+	p := Policy{Protected: []string{"/", "/var/lib", "/tmp/ssh-*", "/srv/*/keep", "relative"}}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "/"},
+		{"/var/lib", "/var/lib"},
+		{"/var", "/var/lib"},   // parent of a protected path
+		{"/var/lib/apt", ""},   // inside one is fine
+		{"/tmp", "/tmp/ssh-*"}, // may contain a match
+		{"/tmp/ssh-XXXX", "/tmp/ssh-*"},
+		{"/tmp/other", ""},
+		{"/srv", "/srv/*/keep"},
+		{"/srv/a", "/srv/*/keep"},
+		{"/srv/a/keep", "/srv/*/keep"},
+		{"/srv/a/b", ""},
+		{"/x/relative", "relative"}, // globs without a slash match the base name
+	}
+	for _, tt := range tests {
+		if got := p.protects(tt.path); got != tt.want {
+			t.Errorf("protects(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBelow(t *testing.T) {
+	tests := []struct {
+		pat, dir string
+		want     bool
+	}{
+		{"/a/b/c", "/", true},
+		{"/a/b/c", "/a", true},
+		{"/a/b/c", "/a/b", true},
+		{"/a/b/c", "/a/b/c", false},
+		{"/a/b/c", "/a/b/c/d", false},
+		{"/a/*/c", "/a/x", true},
+		{"/a/b", "/x", false},
+		{"b/c", "/b", false},
+	}
+	for _, tt := range tests {
+		if got := below(tt.pat, tt.dir); got != tt.want {
+			t.Errorf("below(%q, %q) = %v, want %v", tt.pat, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pat, path string
+		want      bool
+	}{
+		{"*.lock", "/home/bob/x.lock", true},
+		{"*.lock", "/home/bob/x.lock.d", false},
+		{"/home/bob/*", "/home/bob/cache", true},
+		{"/home/bob/*", "/home/alice/cache", false},
+		{"/home/*", "/home/bob/cache", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pat, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pat, tt.path, got, tt.want)
+		}
+	}
+}
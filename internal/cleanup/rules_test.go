@@ -0,0 +1,116 @@
+package cleanup
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"syskit/internal/config"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"512", 512},
+		{"100K", 100 << 10},
+		{"1.5G", 3 << 29},
+		{"2GiB", 2 << 30},
+		{"10mb", 10 << 20},
+		{" 1T ", 1 << 40},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "K", "abc", "-1", "-1M", "NaN", "nanK", "Inf", "+Inf", "-Inf", "infinity", "1e30", "9000000T"} {
+		if got, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want error", in, got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var cfg config.Config
+	cfg.Sysclean.Protected = []string{"/srv/keep-*"}
+	cfg.Sysclean.Rules = []config.CleanRule{
+		{Name: "tmp", MinAge: "3d"},
+		{Name: "rotated-logs", Disabled: true},
+		{Name: "build-cache", Paths: []string{"/home/*/.cache/go-build/*"}, MinAge: "2w",
+			Owner: "alice", MinSize: "1M", MaxSize: "20G", Exclude: []string{"*.lock"}},
+	}
+	p, err := Load(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := map[string]Rule{}
+	for _, r := range p.Rules {
+		rules[r.Name] = r
+	}
+	if len(p.Rules) != len(DefaultRules)+1 {
+		t.Errorf("got %d rules, want %d", len(p.Rules), len(DefaultRules)+1)
+	}
+	// an override keeps the fields it does not set
+	if r := rules["tmp"]; r.MinAge != 3*day || len(r.Paths) != 1 || r.Paths[0] != "/tmp/*" {
+		t.Errorf("tmp = %+v", r)
+	}
+	if !rules["rotated-logs"].Disabled {
+		t.Error("rotated-logs not disabled")
+	}
+	want := Rule{Name: "build-cache", Paths: []string{"/home/*/.cache/go-build/*"}, MinAge: 14 * day,
+		Owner: "alice", MinSize: 1 << 20, MaxSize: 20 << 30, Exclude: []string{"*.lock"}}
+	if got := rules["build-cache"]; got.Name != want.Name || got.MinAge != want.MinAge || got.Owner != want.Owner ||
+		got.MinSize != want.MinSize || got.MaxSize != want.MaxSize || strings.Join(got.Exclude, ",") != "*.lock" {
+		t.Errorf("build-cache = %+v, want %+v", got, want)
+	}
+	if last := p.Protected[len(p.Protected)-1]; last != "/srv/keep-*" {
+		t.Errorf("protected ends with %q", last)
+	}
+	// the defaults are not modified
+	if DefaultRules[0].MinAge != 10*day || DefaultRules[5].Disabled {
+		t.Error("DefaultRules changed by Load")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		protected []string
+		rule      config.CleanRule
+		want      string
+	}{
+		{name: "no name", rule: config.CleanRule{Paths: []string{"/x/*"}}, want: "without a name"},
+		{name: "no paths", rule: config.CleanRule{Name: "new"}, want: "no paths"},
+		{name: "bad glob", rule: config.CleanRule{Name: "new", Paths: []string{"/x/["}}, want: "syntax error"},
+		{name: "bad exclude", rule: config.CleanRule{Name: "tmp", Exclude: []string{"["}}, want: "syntax error"},
+		{name: "bad age", rule: config.CleanRule{Name: "tmp", MinAge: "soon"}, want: "invalid duration"},
+		{name: "bad size", rule: config.CleanRule{Name: "tmp", MaxSize: "NaN"}, want: "invalid size"},
+		{name: "bad protected", protected: []string{"/srv/["}, want: "protected"},
+	}
+	for _, tt := range tests {
+		var cfg config.Config
+		cfg.Sysclean.Protected = tt.protected
+		if tt.rule.Name != "" || len(tt.rule.Paths) > 0 {
+			cfg.Sysclean.Rules = []config.CleanRule{tt.rule}
+		}
+		_, err := Load(&cfg)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMergeDisabledKeepsOverride(t *testing.T) {
+	r := Rule{Name: "tmp", Paths: []string{"/tmp/*"}, MinAge: time.Hour, Disabled: true}
+	if err := merge(&r, config.CleanRule{Name: "tmp", MinSize: "1K"}); err != nil {
+		t.Fatal(err)
+	}
+	// a config entry re-enables a rule unless it says disabled itself
+	if r.Disabled || r.MinAge != time.Hour || r.MinSize != 1024 {
+		t.Errorf("got %+v", r)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build !windows
+// +build !windows
+
+package cleanup
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestScanSpecialFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"sock", "fifo", "dev", "plain/sub"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := net.Listen("unix", filepath.Join(root, "sock", "s"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := syscall.Mkfifo(filepath.Join(root, "fifo", "p"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, "plain/sub/f"), []byte("hello"), 0o644)
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"sock", "contains socket " + filepath.Join(root, "sock", "s")},
+		{"fifo", "contains FIFO " + filepath.Join(root, "fifo", "p")},
+		{"plain", ""},
+	}
+	// device nodes need root
+	if err := syscall.Mknod(filepath.Join(root, "dev", "null"), syscall.S_IFCHR|0o666, 1<<8|3); err == nil {
+		tests = append(tests, struct{ dir, want string }{"dev", "contains device " + filepath.Join(root, "dev", "null")})
+	}
+	for _, tt := range tests {
+		var it Item
+		if got := scan(filepath.Join(root, tt.dir), nil, &it); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+
+	var it Item
+	open := map[string]bool{filepath.Join(root, "plain/sub/f"): true}
+	if got := scan(filepath.Join(root, "plain"), open, &it); !strings.HasPrefix(got, "in use: ") {
+		t.Errorf("open file: got %q", got)
+	}
+	it = Item{}
+	if scan(filepath.Join(root, "plain"), nil, &it); it.Size != 5 || time.Since(it.MTime) > time.Minute {
+		t.Errorf("plain = %+v", it)
+	}
+
+	// an open socket inside a planned item keeps it
+	p := Policy{Rules: []Rule{{Name: "all", Paths: []string{root + "/*"}}}}
+	for _, it := range p.Plan(nil, time.Now()) {
+		if it.Path == filepath.Join(root, "sock") && !strings.HasPrefix(it.Skip, "contains socket") {
+			t.Errorf("sock planned: %+v", it)
+		}
+	}
+}
+
+func TestOpenFiles(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("no procfs")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "held")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	path, _ := filepath.EvalSymlinks(f.Name())
+	if !OpenFiles()[path] {
+		t.Errorf("%s not reported as open", path)
+	}
+}
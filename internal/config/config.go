@@ -1,6 +1,7 @@
 package config
 
 import (
+    "fmt"
     "io/ioutil"
     "os"
     "path/filepath"
@@ -18,27 +19,195 @@ import (
 //   port: 587
 //   username: user
 //   password: pass
-//   to: admin@example.com
+//   from: "Syskit <syskit@example.com>"   # default: username if an address, else first "to"
+//   to: admin@example.com, oncall@example.com
+//   security: auto   # auto (465 = tls, else starttls) | starttls | tls | plain
+//   ca_file: /etc/ssl/private-ca.pem      # extra trusted CA bundle
 // thresholds:
 //   cpu: 90
 //   ram: 90
 //   disk: 90
+//   for: 5m          # must stay above the limit this long before alerting (default 1m)
+//   clear_for: 2m    # must stay below this long before resolving (default: for)
+//   repeat: 1h       # re-notify while still firing (0 = only once)
+//   disks:           # per-mountpoint overrides (percent)
+//     /var: 80
+//   interfaces:      # per-interface rx/tx limits in KB/s
+//     eth0: 50000
+//   notify: [ops]    # channels for threshold alerts (default: all)
+// notify:            # alert channels; without this list smtp above is used
+//   - name: mail
+//     type: smtp       # smtp | webhook | slack | mattermost | syslog | exec
+//     to: oncall@example.com
+//   - name: ops
+//     type: slack
+//     url: https://hooks.slack.com/services/T000/B000/XXXX
+//     channel: "#alerts"
+//   - name: hook
+//     type: webhook
+//     url: https://example.com/syskit
+//     headers: {Authorization: "Bearer token"}
+//   - name: local
+//     type: syslog     # network/addr for a remote logger, e.g. udp / loghost:514
+//   - name: pager
+//     type: exec
+//     command: [/usr/local/bin/page, --team, ops]
+// agent:
+//   watchers:
+//     - name: nginx
+//       type: service      # service | process | threshold | history
+//       service: nginx
+//       interval: 30s
+//     - name: miners
+//       type: process
+//       keywords: [crypto, mining]
+//       cpu: 50
+//       ram: 30
+//       interval: 1m
+//       notify: [ops]
+// pulse:
+//   dry_run: false     # log matching actions without running them
+//   history: 2m        # sparkline window (toggle graphs with g)
+//   columns: [pid, user, cpu, mem, rss, io_read, io_write, cmd]
+//   actions:
+//     - name: kill-runaway-python
+//       process: "python*"          # glob on the process name
+//       cmdline: "train\\.py"       # regexp on the full command line
+//       user: alice                 # glob on the user name
+//       cpu: 90                     # percent, 0 = ignore
+//       mem: 0
+//       for: 30s                    # condition must hold this long
+//       command: "kill -TERM {pid}" # {pid} {name} {user} {cpu} {mem} are shell-quoted
+//       cooldown: 5m                # per process, before the action may fire again
+// theme: solarized   # default | high-contrast | solarized | monochrome | a user theme
+// themes:
+//   - name: midnight
+//     base: solarized  # unset colors come from here (default: default)
+//     colors:          # 256-color numbers or #rrggbb; bar_from/bar_to must be hex
+//       title: "#ff79c6"
+//       selected_bg: "57"
+// sysclean:          # rules replace the built-in rule of the same name
+//   protected: [/srv/cache/keep-*]   # added to the built-in protected paths
+//   rules:
+//     - name: tmp
+//       min_age: 3d
+//     - name: rotated-logs
+//       disabled: true
+//     - name: build-cache
+//       paths: [/home/*/.cache/go-build/*]
+//       min_age: 14d    # newest mtime/ctime in the item must be older (s, m, h, d, w)
+//       owner: alice    # only items owned by this user
+//       min_size: 1M    # skip smaller items
+//       max_size: 20G   # skip larger items
+//       exclude: ["*.lock", /home/bob/*]   # globs on the path or base name
 //
 
 type Config struct {
-    Lang string `yaml:"lang"`
+    Lang   string     `yaml:"lang"`
+    Theme  string     `yaml:"theme,omitempty"`
+    Themes []ThemeDef `yaml:"themes,omitempty"`
     SMTP struct {
         Host     string `yaml:"host"`
         Port     int    `yaml:"port"`
         Username string `yaml:"username"`
         Password string `yaml:"password"`
-        To       string `yaml:"to"`
+        From     string `yaml:"from,omitempty"`
+        To       string `yaml:"to"` // comma separated
+        Security string `yaml:"security,omitempty"`
+        CAFile   string `yaml:"ca_file,omitempty"`
+        // InsecureSkipVerify disables certificate verification (testing only).
+        InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
     } `yaml:"smtp"`
     Thresholds struct {
-        CPU  int `yaml:"cpu"`
-        RAM  int `yaml:"ram"`
-        Disk int `yaml:"disk"`
+        CPU        int            `yaml:"cpu"`
+        RAM        int            `yaml:"ram"`
+        Disk       int            `yaml:"disk"`
+        For        string         `yaml:"for,omitempty"`
+        ClearFor   string         `yaml:"clear_for,omitempty"`
+        Repeat     string         `yaml:"repeat,omitempty"`
+        Disks      map[string]int `yaml:"disks,omitempty"`
+        Interfaces map[string]int `yaml:"interfaces,omitempty"`
+        Notify     []string       `yaml:"notify,omitempty"`
     } `yaml:"thresholds"`
+    Notify []Channel `yaml:"notify,omitempty"`
+    Agent struct {
+        Watchers []Watcher `yaml:"watchers"`
+    } `yaml:"agent"`
+    Pulse struct {
+        DryRun  bool          `yaml:"dry_run,omitempty"`
+        History string        `yaml:"history,omitempty"` // Go duration
+        Columns []string      `yaml:"columns,omitempty"`
+        Actions []PulseAction `yaml:"actions,omitempty"`
+    } `yaml:"pulse"`
+    Sysclean struct {
+        Protected []string    `yaml:"protected,omitempty"`
+        Rules     []CleanRule `yaml:"rules,omitempty"`
+    } `yaml:"sysclean"`
+}
+
+// CleanRule is a sysclean rule. A rule named like a built-in one overrides
+// the fields it sets; Disabled turns a rule off.
+type CleanRule struct {
+    Name     string   `yaml:"name"`
+    Paths    []string `yaml:"paths,omitempty"`    // globs
+    MinAge   string   `yaml:"min_age,omitempty"`  // duration, d and w allowed
+    Owner    string   `yaml:"owner,omitempty"`    // user name
+    MinSize  string   `yaml:"min_size,omitempty"` // e.g. 10M
+    MaxSize  string   `yaml:"max_size,omitempty"`
+    Exclude  []string `yaml:"exclude,omitempty"`  // globs on the path or base name
+    Disabled bool     `yaml:"disabled,omitempty"`
+}
+
+// PulseAction is a rule evaluated by the pulse dashboard on every refresh.
+type PulseAction struct {
+    Name     string  `yaml:"name"`
+    Process  string  `yaml:"process,omitempty"` // glob on process name
+    Cmdline  string  `yaml:"cmdline,omitempty"` // regexp on command line
+    User     string  `yaml:"user,omitempty"`    // glob on user name
+    CPU      float64 `yaml:"cpu,omitempty"`
+    Mem      float64 `yaml:"mem,omitempty"`
+    For      string  `yaml:"for,omitempty"`
+    Command  string  `yaml:"command"`
+    Cooldown string  `yaml:"cooldown,omitempty"`
+    DryRun   bool    `yaml:"dry_run,omitempty"`
+}
+
+// ThemeDef is a user-defined color theme. Colors maps roles (title,
+// header_bg, accent, selected_fg, selected_bg, muted, error, highlight, cpu,
+// mem, swap, disk, net, bar_from, bar_to) to colors.
+type ThemeDef struct {
+    Name   string            `yaml:"name"`
+    Base   string            `yaml:"base,omitempty"`
+    Colors map[string]string `yaml:"colors,omitempty"`
+}
+
+// Watcher declares one periodic check run by `syskit agent run`.
+type Watcher struct {
+    Name     string   `yaml:"name"`
+    Type     string   `yaml:"type"`
+    Interval string   `yaml:"interval"` // Go duration, e.g. 30s
+    Service  string   `yaml:"service,omitempty"`
+    Keywords []string `yaml:"keywords,omitempty"`
+    CPU      int      `yaml:"cpu,omitempty"`
+    RAM      int      `yaml:"ram,omitempty"`
+    Notify   []string `yaml:"notify,omitempty"` // channel names, default all
+}
+
+// Channel declares one named notification target. Which fields apply
+// depends on Type: smtp (to), webhook (url, headers), slack/mattermost
+// (url, channel, username), syslog (network, addr, tag), exec (command).
+type Channel struct {
+    Name     string            `yaml:"name"`
+    Type     string            `yaml:"type"`
+    To       string            `yaml:"to,omitempty"`
+    URL      string            `yaml:"url,omitempty"`
+    Headers  map[string]string `yaml:"headers,omitempty"`
+    Channel  string            `yaml:"channel,omitempty"`
+    Username string            `yaml:"username,omitempty"`
+    Network  string            `yaml:"network,omitempty"`
+    Addr     string            `yaml:"addr,omitempty"`
+    Tag      string            `yaml:"tag,omitempty"`
+    Command  []string          `yaml:"command,omitempty"`
 }
 
 var cfg *Config
@@ -62,18 +231,34 @@ func Load() *Config {
     if cfg != nil {
         return cfg
     }
-    cfg = &Config{}
-    cfg.Thresholds.CPU = 90
-    cfg.Thresholds.RAM = 90
-    cfg.Thresholds.Disk = 90
+    cfg, _ = Read()
+    return cfg
+}
+
+// Read parses the config file into a new Config without touching the one
+// cached by Load. A missing file yields the defaults; a malformed one is an
+// error, returned together with whatever could be decoded.
+func Read() (*Config, error) {
+    c := &Config{}
+    c.Thresholds.CPU = 90
+    c.Thresholds.RAM = 90
+    c.Thresholds.Disk = 90
 
     path := filePath()
     data, err := ioutil.ReadFile(path)
     if err != nil {
-        return cfg // defaults
+        return c, nil // defaults
     }
-    yaml.Unmarshal(data, cfg)
-    return cfg
+    if err := yaml.Unmarshal(data, c); err != nil {
+        return c, fmt.Errorf("%s: %w", path, err)
+    }
+    return c, nil
+}
+
+// Set replaces the config returned by Load. Callers must make sure nothing
+// reads it concurrently.
+func Set(c *Config) {
+    cfg = c
 }
 
 func filePath() string {
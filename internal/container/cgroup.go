@@ -0,0 +1,55 @@
+package container
+
+import (
+    "os"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// cgroupPatterns recognise the cgroup paths container runtimes create. The
+// first group is the runtime, the second the container ID or name.
+var cgroupPatterns = []*regexp.Regexp{
+    regexp.MustCompile(`(docker)[-/]([0-9a-f]{64})`),
+    regexp.MustCompile(`(libpod)-([0-9a-f]{64})`),
+    regexp.MustCompile(`(cri-containerd|crio)-([0-9a-f]{64})`),
+    regexp.MustCompile(`(kubepods).*/([0-9a-f]{64})$`),
+    regexp.MustCompile(`(lxc)(?:\.payload\.|/)([^/]+)`),
+}
+
+var runtimeNames = map[string]string{
+    "docker":         "docker",
+    "libpod":         "podman",
+    "cri-containerd": "containerd",
+    "crio":           "cri-o",
+    "kubepods":       "k8s",
+    "lxc":            "lxc",
+}
+
+// ForPID returns "runtime:id" for a process running inside a container,
+// e.g. "docker:3f2a9c1b7d4e", or "" for processes on the host. IDs are
+// shortened to 12 characters like docker ps does.
+func ForPID(pid int) string {
+    b, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+    if err != nil {
+        return ""
+    }
+    for _, line := range strings.Split(string(b), "\n") {
+        // hierarchy-ID:controllers:path
+        parts := strings.SplitN(line, ":", 3)
+        if len(parts) != 3 {
+            continue
+        }
+        for _, re := range cgroupPatterns {
+            if m := re.FindStringSubmatch(parts[2]); m != nil {
+                id := m[2]
+                if len(id) == 64 {
+                    id = id[:12]
+                }
+                return runtimeNames[m[1]] + ":" + id
+            }
+        }
+    }
+    return ""
+}
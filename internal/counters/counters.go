@@ -0,0 +1,130 @@
+// Package counters keeps monotonically increasing event counters in
+// ~/.syskit/counters.json so that one-shot commands (process-watch,
+// watchdog) and the exporter can share them across processes.
+package counters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	"syskit/internal/filelock"
+)
+
+// Counter is one labelled counter value.
+type Counter struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// mu serialises goroutines; the lock file serialises processes.
+var mu sync.Mutex
+
+// Path returns the counters file location.
+func Path() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".syskit", "counters.json")
+}
+
+// Add increases the counter identified by name and label pairs
+// (k1, v1, k2, v2, ...) by delta. The load-modify-write runs under an
+// exclusive lock on counters.json.lock so concurrent syskit processes do
+// not lose increments.
+func Add(delta float64, name string, labels ...string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := filelock.Lock(Path() + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	lbl := map[string]string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		lbl[labels[i]] = labels[i+1]
+	}
+	all, err := Load()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i := range all {
+		if all[i].Name == name && (len(all[i].Labels) == 0 && len(lbl) == 0 || reflect.DeepEqual(all[i].Labels, lbl)) {
+			all[i].Value += delta
+			found = true
+			break
+		}
+	}
+	if !found {
+		all = append(all, Counter{Name: name, Labels: lbl, Value: delta})
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write-then-rename so a concurrent reader never sees a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(Path()), "counters-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), Path())
+}
+
+// Inc increases the counter by one.
+func Inc(name string, labels ...string) error {
+	return Add(1, name, labels...)
+}
+
+// Load returns all counters. A missing file yields an empty list.
+func Load() ([]Counter, error) {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var all []Counter
+	err = json.Unmarshal(data, &all)
+	return all, err
+}
+
+// Named returns the counters called name.
+func Named(all []Counter, name string) []Counter {
+	var out []Counter
+	for _, c := range all {
+		if c.Name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// LabelPairs flattens c.Labels into sorted k1, v1, k2, v2 ... pairs.
+func (c Counter) LabelPairs() []string {
+	keys := make([]string, 0, len(c.Labels))
+	for k := range c.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []string
+	for _, k := range keys {
+		out = append(out, k, c.Labels[k])
+	}
+	return out
+}
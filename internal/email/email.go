@@ -1,52 +1,287 @@
 package email
 
 import (
+    "bytes"
+    "crypto/rand"
     "crypto/tls"
+    "crypto/x509"
+    "encoding/hex"
+    "errors"
     "fmt"
+    "io"
+    "mime"
+    "mime/multipart"
+    "mime/quotedprintable"
+    "net"
+    "net/mail"
     "net/smtp"
+    "net/textproto"
+    "os"
+    "strings"
+    "time"
 )
 
-// Send sends an email using given SMTP credentials.
-func Send(host string, port int, username, password, to, subject, body string) error {
-    addr := fmt.Sprintf("%s:%d", host, port)
-    auth := smtp.PlainAuth("", username, password, host)
+// Security modes for Config.Security.
+const (
+    SecurityAuto     = "auto"     // tls on port 465, starttls otherwise
+    SecurityStartTLS = "starttls" // plain connect, then mandatory STARTTLS
+    SecurityTLS      = "tls"      // implicit TLS (SMTPS)
+    SecurityPlain    = "plain"    // no encryption; credentials only sent to localhost
+)
+
+// Timeout bounds the whole SMTP conversation.
+var Timeout = 30 * time.Second
+
+// random feeds the Message-ID and the multipart boundary; tests replace it
+// to get reproducible output.
+var random io.Reader = rand.Reader
+
+// Config describes an SMTP account.
+type Config struct {
+    Host     string
+    Port     int
+    Username string
+    Password string
+    From     string   // optional, see from
+    To       []string
+    Security string   // one of the Security* modes, default auto
+    CAFile   string   // PEM bundle trusted in addition to the system roots
+    // InsecureSkipVerify disables certificate checks; only for testing.
+    InsecureSkipVerify bool
+}
+
+// Message is the content of one mail. HTML is optional; when set the mail is
+// sent as multipart/alternative with Text as the fallback part.
+type Message struct {
+    Subject string
+    Text    string
+    HTML    string
+}
+
+// SplitAddrs splits a comma or semicolon separated recipient list.
+func SplitAddrs(s string) []string {
+    var out []string
+    for _, a := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+        if a = strings.TrimSpace(a); a != "" {
+            out = append(out, a)
+        }
+    }
+    return out
+}
+
+// Mode resolves SecurityAuto against the port.
+func (c Config) Mode() string {
+    switch strings.ToLower(c.Security) {
+    case SecurityStartTLS, SecurityTLS, SecurityPlain:
+        return strings.ToLower(c.Security)
+    }
+    if c.Port == 465 {
+        return SecurityTLS
+    }
+    return SecurityStartTLS
+}
 
-    msg := []byte("To: " + to + "\r\n" +
-        "Subject: " + subject + "\r\n" +
-        "MIME-Version: 1.0\r\n" +
-        "Content-Type: text/plain; charset=\"utf-8\"\r\n" +
-        "\r\n" + body + "\r\n")
+// from returns the sender: From if set, else Username when it is a mail
+// address (many relays log in with a plain name or an API key), else the
+// first recipient, else syskit@<hostname>.
+func (c Config) from() string {
+    if c.From != "" {
+        return c.From
+    }
+    if _, err := mail.ParseAddress(c.Username); err == nil {
+        return c.Username
+    }
+    if len(c.To) > 0 {
+        if _, err := mail.ParseAddress(c.To[0]); err == nil {
+            return c.To[0]
+        }
+    }
+    host, err := os.Hostname()
+    if err != nil || host == "" {
+        host = "localhost"
+    }
+    return "syskit@" + host
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+    tc := &tls.Config{ServerName: c.Host, MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.InsecureSkipVerify}
+    if c.CAFile != "" {
+        pem, err := os.ReadFile(c.CAFile)
+        if err != nil {
+            return nil, err
+        }
+        pool, err := x509.SystemCertPool()
+        if err != nil {
+            pool = x509.NewCertPool()
+        }
+        if !pool.AppendCertsFromPEM(pem) {
+            return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+        }
+        tc.RootCAs = pool
+    }
+    return tc, nil
+}
 
-    // TLS configuration (skip verify for simplicity; user should trust)
-    tlsconfig := &tls.Config{InsecureSkipVerify: true, ServerName: host}
-    conn, err := tls.Dial("tcp", addr, tlsconfig)
+// Send delivers m to every recipient in c.To.
+func (c Config) Send(m Message) error {
+    if c.Host == "" {
+        return errors.New("smtp host not set")
+    }
+    if len(c.To) == 0 {
+        return errors.New("no recipients")
+    }
+    from, err := mail.ParseAddress(c.from())
+    if err != nil {
+        return fmt.Errorf("from address: %w", err)
+    }
+    var rcpts []*mail.Address
+    for _, t := range c.To {
+        a, err := mail.ParseAddress(t)
+        if err != nil {
+            return fmt.Errorf("recipient %q: %w", t, err)
+        }
+        rcpts = append(rcpts, a)
+    }
+    data, err := Build(from, rcpts, m, time.Now())
     if err != nil {
         return err
     }
-    c, err := smtp.NewClient(conn, host)
+
+    if c.Port == 0 {
+        c.Port = 587
+    }
+    addr := net.JoinHostPort(c.Host, fmt.Sprint(c.Port))
+    tc, err := c.tlsConfig()
     if err != nil {
         return err
     }
-    if err = c.Auth(auth); err != nil {
+    mode := c.Mode()
+
+    dialer := &net.Dialer{Timeout: Timeout}
+    var conn net.Conn
+    if mode == SecurityTLS {
+        conn, err = tls.DialWithDialer(dialer, "tcp", addr, tc)
+    } else {
+        conn, err = dialer.Dial("tcp", addr)
+    }
+    if err != nil {
         return err
     }
-    if err = c.Mail(username); err != nil {
+    conn.SetDeadline(time.Now().Add(Timeout))
+    cl, err := smtp.NewClient(conn, c.Host)
+    if err != nil {
+        conn.Close()
         return err
     }
-    if err = c.Rcpt(to); err != nil {
+    defer cl.Close()
+    if hn, err := os.Hostname(); err == nil {
+        if err := cl.Hello(hn); err != nil {
+            return err
+        }
+    }
+    if mode == SecurityStartTLS {
+        if ok, _ := cl.Extension("STARTTLS"); !ok {
+            return fmt.Errorf("%s does not offer STARTTLS; set smtp.security to tls or plain", addr)
+        }
+        if err := cl.StartTLS(tc); err != nil {
+            return err
+        }
+    }
+    if c.Username != "" {
+        if ok, _ := cl.Extension("AUTH"); !ok {
+            return fmt.Errorf("%s does not offer AUTH", addr)
+        }
+        // PlainAuth itself refuses to send credentials unencrypted to a
+        // remote host, which is what we want in plain mode.
+        if err := cl.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+            return err
+        }
+    }
+    if err := cl.Mail(from.Address); err != nil {
         return err
     }
-    w, err := c.Data()
+    for _, r := range rcpts {
+        if err := cl.Rcpt(r.Address); err != nil {
+            return fmt.Errorf("recipient %s: %w", r.Address, err)
+        }
+    }
+    w, err := cl.Data()
     if err != nil {
         return err
     }
-    _, err = w.Write(msg)
-    if err != nil {
+    if _, err := w.Write(data); err != nil {
         return err
     }
-    err = w.Close()
-    if err != nil {
+    if err := w.Close(); err != nil {
+        return err
+    }
+    return cl.Quit()
+}
+
+// Build renders an RFC 5322 message with From, To, Date, Message-ID and a
+// quoted-printable text (and optional HTML) body.
+func Build(from *mail.Address, to []*mail.Address, m Message, now time.Time) ([]byte, error) {
+    var buf bytes.Buffer
+    var tos []string
+    for _, a := range to {
+        tos = append(tos, a.String())
+    }
+    domain := "localhost"
+    if i := strings.LastIndex(from.Address, "@"); i >= 0 {
+        domain = from.Address[i+1:]
+    }
+    id := make([]byte, 16)
+    if _, err := io.ReadFull(random, id); err != nil {
+        return nil, err
+    }
+    token := hex.EncodeToString(id)
+
+    hdr := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+    hdr("From", from.String())
+    hdr("To", strings.Join(tos, ", "))
+    hdr("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+    hdr("Date", now.Format(time.RFC1123Z))
+    hdr("Message-ID", "<"+token+"@"+domain+">")
+    hdr("MIME-Version", "1.0")
+
+    if m.HTML == "" {
+        hdr("Content-Type", `text/plain; charset="utf-8"`)
+        hdr("Content-Transfer-Encoding", "quoted-printable")
+        buf.WriteString("\r\n")
+        if err := writeQP(&buf, m.Text); err != nil {
+            return nil, err
+        }
+        return buf.Bytes(), nil
+    }
+
+    mw := multipart.NewWriter(&buf)
+    if err := mw.SetBoundary("syskit-" + token); err != nil {
+        return nil, err
+    }
+    hdr("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+    buf.WriteString("\r\n")
+    for _, part := range []struct{ typ, body string }{{"text/plain", m.Text}, {"text/html", m.HTML}} {
+        pw, err := mw.CreatePart(textproto.MIMEHeader{
+            "Content-Type":              {part.typ + `; charset="utf-8"`},
+            "Content-Transfer-Encoding": {"quoted-printable"},
+        })
+        if err != nil {
+            return nil, err
+        }
+        if err := writeQP(pw, part.body); err != nil {
+            return nil, err
+        }
+    }
+    if err := mw.Close(); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+func writeQP(w io.Writer, s string) error {
+    qp := quotedprintable.NewWriter(w)
+    if _, err := qp.Write([]byte(strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n"))); err != nil {
         return err
     }
-    return c.Quit()
+    return qp.Close()
 }
@@ -0,0 +1,298 @@
+package email
+
+import (
+    "bufio"
+    "bytes"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/base64"
+    "encoding/pem"
+    "io"
+    "math/big"
+    "net"
+    "net/mail"
+    "net/textproto"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestBuild(t *testing.T) {
+    defer func(r io.Reader) { random = r }(random)
+    from := &mail.Address{Name: "Syskit Bot", Address: "syskit@example.com"}
+    to := []*mail.Address{{Address: "ops@example.com"}, {Name: "Zoë Ops", Address: "zoe@example.org"}}
+    now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("", 3600))
+
+    tests := []struct {
+        name string
+        to   []*mail.Address
+        m    Message
+        want string // with \n standing for CRLF
+    }{
+        {
+            name: "text",
+            to:   to[:1],
+            m:    Message{Subject: "plain", Text: "a\r\nb"},
+            want: `From: "Syskit Bot" <syskit@example.com>
+To: <ops@example.com>
+Subject: plain
+Date: Sun, 01 Mar 2026 12:00:00 +0100
+Message-ID: <abababababababababababababababab@example.com>
+MIME-Version: 1.0
+Content-Type: text/plain; charset="utf-8"
+Content-Transfer-Encoding: quoted-printable
+
+a
+b`,
+        },
+        {
+            name: "multipart",
+            to:   to,
+            m:    Message{Subject: "Disk ≥ 90% on web-1", Text: "line one\nüber = 100%\n", HTML: "<p>über</p>"},
+            want: `From: "Syskit Bot" <syskit@example.com>
+To: <ops@example.com>, =?utf-8?q?Zo=C3=AB_Ops?= <zoe@example.org>
+Subject: =?utf-8?q?Disk_=E2=89=A5_90%_on_web-1?=
+Date: Sun, 01 Mar 2026 12:00:00 +0100
+Message-ID: <abababababababababababababababab@example.com>
+MIME-Version: 1.0
+Content-Type: multipart/alternative; boundary="syskit-abababababababababababababababab"
+
+--syskit-abababababababababababababababab
+Content-Transfer-Encoding: quoted-printable
+Content-Type: text/plain; charset="utf-8"
+
+line one
+=C3=BCber =3D 100%
+
+--syskit-abababababababababababababababab
+Content-Transfer-Encoding: quoted-printable
+Content-Type: text/html; charset="utf-8"
+
+<p>=C3=BCber</p>
+--syskit-abababababababababababababababab--
+`,
+        },
+    }
+    for _, tt := range tests {
+        random = bytes.NewReader(bytes.Repeat([]byte{0xab}, 16))
+        got, err := Build(from, tt.to, tt.m, now)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if bytes.Contains(bytes.ReplaceAll(got, []byte("\r\n"), nil), []byte("\n")) {
+            t.Errorf("%s: bare LF in output", tt.name)
+        }
+        if want := strings.ReplaceAll(tt.want, "\n", "\r\n"); string(got) != want {
+            t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, want)
+        }
+    }
+}
+
+// session is what the fake server saw of one SMTP conversation.
+type session struct {
+    tls   bool
+    auth  string
+    from  string
+    rcpts []string
+    data  string
+    err   error
+}
+
+// fakeSMTP accepts one connection on 127.0.0.1. With implicit the
+// listener speaks TLS from the start; with starttls it offers STARTTLS.
+func fakeSMTP(t *testing.T, cert tls.Certificate, implicit, starttls bool) (int, <-chan session) {
+    t.Helper()
+    cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+    var ln net.Listener
+    var err error
+    if implicit {
+        ln, err = tls.Listen("tcp", "127.0.0.1:0", cfg)
+    } else {
+        ln, err = net.Listen("tcp", "127.0.0.1:0")
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { ln.Close() })
+    done := make(chan session, 1)
+    go func() {
+        var s session
+        defer func() { done <- s }()
+        conn, err := ln.Accept()
+        if err != nil {
+            s.err = err
+            return
+        }
+        defer conn.Close()
+        conn.SetDeadline(time.Now().Add(5 * time.Second))
+        s.tls = implicit
+        tp := textproto.NewConn(conn)
+        tp.PrintfLine("220 fake ESMTP")
+        for {
+            line, err := tp.ReadLine()
+            if err != nil {
+                s.err = err
+                return
+            }
+            verb, arg, _ := strings.Cut(line, " ")
+            switch strings.ToUpper(verb) {
+            case "EHLO":
+                tp.PrintfLine("250-fake")
+                if starttls && !s.tls {
+                    tp.PrintfLine("250-STARTTLS")
+                }
+                tp.PrintfLine("250 AUTH PLAIN")
+            case "STARTTLS":
+                tp.PrintfLine("220 go ahead")
+                tc := tls.Server(conn, cfg)
+                if err := tc.Handshake(); err != nil {
+                    s.err = err
+                    return
+                }
+                conn, s.tls = tc, true
+                tp = textproto.NewConn(conn)
+            case "AUTH":
+                b, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+                s.auth = string(b)
+                tp.PrintfLine("235 ok")
+            case "MAIL":
+                s.from = arg
+                tp.PrintfLine("250 ok")
+            case "RCPT":
+                s.rcpts = append(s.rcpts, arg)
+                tp.PrintfLine("250 ok")
+            case "DATA":
+                tp.PrintfLine("354 go ahead")
+                b, err := tp.ReadDotBytes()
+                if err != nil {
+                    s.err = err
+                    return
+                }
+                s.data = string(b)
+                tp.PrintfLine("250 queued")
+            case "QUIT":
+                tp.PrintfLine("221 bye")
+                return
+            default:
+                tp.PrintfLine("502 unknown command")
+            }
+        }
+    }()
+    return ln.Addr().(*net.TCPAddr).Port, done
+}
+
+// selfSigned returns a certificate for 127.0.0.1 and its PEM, written to
+// a file for Config.CAFile.
+func selfSigned(t *testing.T) (tls.Certificate, string) {
+    t.Helper()
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tmpl := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "fake smtp"},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(time.Hour),
+        IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+        KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+        ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+        BasicConstraintsValid: true,
+        IsCA:                  true,
+    }
+    der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    caFile := filepath.Join(t.TempDir(), "ca.pem")
+    if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestSend(t *testing.T) {
+    defer func(d time.Duration) { Timeout = d }(Timeout)
+    Timeout = 5 * time.Second
+    cert, caFile := selfSigned(t)
+
+    tests := []struct {
+        name     string
+        security string
+        implicit bool // server side
+        starttls bool // server side
+        trust    bool
+        user     string
+        wantTLS  bool
+        err      string
+    }{
+        {name: "starttls", security: SecurityStartTLS, starttls: true, trust: true, user: "bob", wantTLS: true},
+        {name: "implicit tls", security: SecurityTLS, implicit: true, trust: true, user: "bob", wantTLS: true},
+        {name: "plain", security: SecurityPlain},
+        // PlainAuth allows credentials in the clear to localhost only
+        {name: "plain localhost auth", security: SecurityPlain, user: "bob"},
+        {name: "no starttls offered", security: SecurityStartTLS, err: "does not offer STARTTLS"},
+        {name: "untrusted certificate", security: SecurityStartTLS, starttls: true, err: "certificate"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            port, done := fakeSMTP(t, cert, tt.implicit, tt.starttls)
+            c := Config{
+                Host:     "127.0.0.1",
+                Port:     port,
+                Username: tt.user,
+                Password: "secret",
+                From:     "Syskit <syskit@example.com>",
+                To:       []string{"ops@example.com", "Zoë <zoe@example.org>"},
+                Security: tt.security,
+            }
+            if tt.trust {
+                c.CAFile = caFile
+            }
+            err := c.Send(Message{Subject: "test", Text: "hello\n.\nworld"})
+            if tt.err != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.err) {
+                    t.Fatalf("Send() error = %v, want %q", err, tt.err)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Send(): %v", err)
+            }
+            s := <-done
+            if s.err != nil {
+                t.Fatalf("server: %v", s.err)
+            }
+            if s.tls != tt.wantTLS {
+                t.Errorf("tls = %v, want %v", s.tls, tt.wantTLS)
+            }
+            wantAuth := ""
+            if tt.user != "" {
+                wantAuth = "\x00bob\x00secret"
+            }
+            if s.auth != wantAuth {
+                t.Errorf("auth = %q, want %q", s.auth, wantAuth)
+            }
+            if s.from != "FROM:<syskit@example.com>" || strings.Join(s.rcpts, " ") != "TO:<ops@example.com> TO:<zoe@example.org>" {
+                t.Errorf("envelope = %s %v", s.from, s.rcpts)
+            }
+            msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(s.data)))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if msg.Header.Get("Subject") != "test" {
+                t.Errorf("Subject = %q", msg.Header.Get("Subject"))
+            }
+            // the lone dot must survive dot-stuffing
+            if body, _ := io.ReadAll(msg.Body); string(body) != "hello\n.\nworld\n" {
+                t.Errorf("body = %q", body)
+            }
+        })
+    }
+}
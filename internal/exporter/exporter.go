@@ -0,0 +1,242 @@
+// Package exporter renders host metrics in the OpenMetrics text format.
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syskit/internal/counters"
+	"syskit/internal/metrics"
+	"syskit/internal/procs"
+)
+
+// ContentType is the OpenMetrics 1.0 media type.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Counter families maintained by other commands via the counters package.
+const (
+	ProcessWatchHits = "syskit_process_watch_hits"
+	WatchdogRestarts = "syskit_watchdog_restarts"
+)
+
+type sample struct {
+	labels []string // k1, v1, k2, v2 ...
+	value  float64
+}
+
+type family struct {
+	name    string
+	typ     string // gauge | counter
+	unit    string
+	help    string
+	samples []sample
+}
+
+func (f *family) add(v float64, labels ...string) {
+	f.samples = append(f.samples, sample{labels: labels, value: v})
+}
+
+// Exporter collects metrics from procfs on every scrape.
+type Exporter struct {
+	FS metrics.FS
+}
+
+// New returns an exporter reading the live system.
+func New() *Exporter {
+	return &Exporter{FS: metrics.Default}
+}
+
+// ServeHTTP writes a scrape.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	if err := e.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Write renders all metric families followed by the # EOF marker.
+func (e *Exporter) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range e.collect() {
+		writeFamily(bw, f)
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+func (e *Exporter) collect() []*family {
+	var out []*family
+	newFamily := func(name, typ, unit, help string) *family {
+		f := &family{name: name, typ: typ, unit: unit, help: help}
+		out = append(out, f)
+		return f
+	}
+
+	if st, err := e.FS.Stat(); err == nil {
+		cpu := newFamily("syskit_cpu", "counter", "seconds", "Seconds the CPUs spent in each mode.")
+		for _, c := range st.CPUs {
+			id := strings.TrimPrefix(c.Name, "cpu")
+			modes := map[string]uint64{
+				"user": c.User, "nice": c.Nice, "system": c.System, "idle": c.Idle,
+				"iowait": c.IOWait, "irq": c.IRQ, "softirq": c.SoftIRQ, "steal": c.Steal,
+			}
+			for _, mode := range sortedKeys(modes) {
+				cpu.add(float64(modes[mode])/procs.ClockTicks, "cpu", id, "mode", mode)
+			}
+		}
+		newFamily("syskit_boot_time", "gauge", "seconds", "Unix time the system booted.").add(float64(st.BootTime.Unix()))
+		newFamily("syskit_context_switches", "counter", "", "Context switches since boot.").add(float64(st.ContextSwitches))
+		newFamily("syskit_forks", "counter", "", "Processes created since boot.").add(float64(st.Processes))
+	}
+
+	if l, err := e.FS.LoadAvg(); err == nil {
+		newFamily("syskit_load1", "gauge", "", "1 minute load average.").add(l.Load1)
+		newFamily("syskit_load5", "gauge", "", "5 minute load average.").add(l.Load5)
+		newFamily("syskit_load15", "gauge", "", "15 minute load average.").add(l.Load15)
+	}
+
+	if mi, err := e.FS.MemInfo(); err == nil {
+		mem := newFamily("syskit_memory", "gauge", "bytes", "Memory figures from /proc/meminfo.")
+		for _, k := range []struct {
+			kind string
+			v    uint64
+		}{
+			{"total", mi.MemTotal}, {"free", mi.MemFree}, {"available", mi.MemAvailable},
+			{"buffers", mi.Buffers}, {"cached", mi.Cached}, {"shmem", mi.Shmem}, {"slab", mi.Slab},
+			{"swap_total", mi.SwapTotal}, {"swap_free", mi.SwapFree},
+		} {
+			mem.add(float64(k.v), "kind", k.kind)
+		}
+	}
+
+	if mounts, err := e.FS.Mounts(); err == nil {
+		size := newFamily("syskit_filesystem_size", "gauge", "bytes", "Filesystem size.")
+		avail := newFamily("syskit_filesystem_avail", "gauge", "bytes", "Filesystem space available to non-root users.")
+		used := newFamily("syskit_filesystem_used", "gauge", "bytes", "Filesystem space in use.")
+		seen := map[string]bool{}
+		for _, m := range mounts {
+			if !m.IsDevice() || seen[m.MountPoint] {
+				continue
+			}
+			seen[m.MountPoint] = true
+			du, err := metrics.DiskUsage(m.MountPoint)
+			if err != nil {
+				continue
+			}
+			lbl := []string{"device", m.Device, "fstype", m.FSType, "mountpoint", m.MountPoint}
+			size.add(float64(du.Total), lbl...)
+			avail.add(float64(du.Free), lbl...)
+			used.add(float64(du.Used), lbl...)
+		}
+	}
+
+	if disks, err := e.FS.DiskStats(); err == nil {
+		rd := newFamily("syskit_disk_read", "counter", "bytes", "Bytes read from block devices.")
+		wr := newFamily("syskit_disk_written", "counter", "bytes", "Bytes written to block devices.")
+		rops := newFamily("syskit_disk_reads_completed", "counter", "", "Completed read requests.")
+		wops := newFamily("syskit_disk_writes_completed", "counter", "", "Completed write requests.")
+		iot := newFamily("syskit_disk_io_time", "counter", "seconds", "Time spent doing I/O.")
+		for _, d := range disks {
+			if strings.HasPrefix(d.Name, "loop") || strings.HasPrefix(d.Name, "ram") {
+				continue
+			}
+			rd.add(float64(d.ReadBytes()), "device", d.Name)
+			wr.add(float64(d.WrittenBytes()), "device", d.Name)
+			rops.add(float64(d.Reads), "device", d.Name)
+			wops.add(float64(d.Writes), "device", d.Name)
+			iot.add(float64(d.IOTimeMs)/1000, "device", d.Name)
+		}
+	}
+
+	if devs, err := e.FS.NetDev(); err == nil {
+		rx := newFamily("syskit_network_receive", "counter", "bytes", "Bytes received.")
+		tx := newFamily("syskit_network_transmit", "counter", "bytes", "Bytes transmitted.")
+		rxe := newFamily("syskit_network_receive_errs", "counter", "", "Receive errors.")
+		txe := newFamily("syskit_network_transmit_errs", "counter", "", "Transmit errors.")
+		rxd := newFamily("syskit_network_receive_drop", "counter", "", "Received packets dropped.")
+		txd := newFamily("syskit_network_transmit_drop", "counter", "", "Transmitted packets dropped.")
+		for _, d := range devs {
+			rx.add(float64(d.RxBytes), "device", d.Name)
+			tx.add(float64(d.TxBytes), "device", d.Name)
+			rxe.add(float64(d.RxErrs), "device", d.Name)
+			txe.add(float64(d.TxErrs), "device", d.Name)
+			rxd.add(float64(d.RxDrop), "device", d.Name)
+			txd.add(float64(d.TxDrop), "device", d.Name)
+		}
+	}
+
+	all, _ := counters.Load()
+	for _, c := range []struct{ name, help string }{
+		{ProcessWatchHits, "Suspicious processes reported by process-watch and the agent."},
+		{WatchdogRestarts, "Service restarts attempted by watchdog, by result."},
+	} {
+		f := newFamily(c.name, "counter", "", c.help)
+		for _, cnt := range counters.Named(all, c.name) {
+			f.add(cnt.Value, cnt.LabelPairs()...)
+		}
+	}
+	return out
+}
+
+func writeFamily(w *bufio.Writer, f *family) {
+	name := f.name
+	if f.unit != "" {
+		name += "_" + f.unit
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, f.typ)
+	if f.unit != "" {
+		fmt.Fprintf(w, "# UNIT %s %s\n", name, f.unit)
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n", name, f.help)
+	series := name
+	if f.typ == "counter" {
+		series += "_total"
+	}
+	for _, s := range f.samples {
+		w.WriteString(series)
+		w.WriteString(formatLabels(s.labels))
+		w.WriteByte(' ')
+		w.WriteString(formatValue(s.value))
+		w.WriteByte('\n')
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	var parts []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
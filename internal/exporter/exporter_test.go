@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"syskit/internal/metrics"
+)
+
+func TestWriteGolden(t *testing.T) {
+	home, err := filepath.Abs("testdata/home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	var buf bytes.Buffer
+	if err := (&Exporter{FS: metrics.NewFS("testdata/proc")}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("testdata/scrape.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("scrape differs from testdata/scrape.txt:\n%s", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	values := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{2.5, "2.5"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range values {
+		if got := formatValue(tt.v); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+	labels := []struct {
+		in   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"odd"}, ""},
+		{[]string{"a", "1", "b", "x\"y\\z\nw"}, `{a="1",b="x\"y\\z\nw"}`},
+	}
+	for _, tt := range labels {
+		if got := formatLabels(tt.in); got != tt.want {
+			t.Errorf("formatLabels(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
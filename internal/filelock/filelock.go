@@ -0,0 +1,29 @@
+// Package filelock serialises read-modify-write cycles on shared state
+// files between syskit processes with advisory locks.
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Lock takes an exclusive lock on path, creating it (and its directory) if
+// needed, and blocks until the lock is available. The returned function
+// releases it.
+func Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		unlock(f)
+		f.Close()
+	}, nil
+}
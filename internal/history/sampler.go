@@ -0,0 +1,87 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// Series recorded by the sampler. Net rates are bytes per second.
+const (
+	SeriesCPU   = "cpu"
+	SeriesMem   = "mem"
+	SeriesSwap  = "swap"
+	SeriesDisk  = "disk"
+	SeriesNetRx = "net_rx"
+	SeriesNetTx = "net_tx"
+)
+
+// Sampler periodically reads system metrics into a Store.
+type Sampler struct {
+	Store    *Store
+	Interval time.Duration
+	FS       metrics.FS
+	DiskPath string
+
+	prevCPU metrics.CPUStat
+	prevRx  uint64
+	prevTx  uint64
+	prevAt  time.Time
+}
+
+// NewSampler returns a sampler recording the live system every second.
+func NewSampler(st *Store) *Sampler {
+	return &Sampler{Store: st, Interval: time.Second, FS: metrics.Default, DiskPath: "/"}
+}
+
+// Run samples until ctx is cancelled.
+func (s *Sampler) Run(ctx context.Context) error {
+	t := time.NewTicker(s.Interval)
+	defer t.Stop()
+	for {
+		if err := s.Tick(time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// Tick takes one sample. Rates (CPU, net) need a previous sample and are
+// skipped on the first call.
+func (s *Sampler) Tick(now time.Time) error {
+	vals := map[string]float64{}
+	first := s.prevAt.IsZero()
+	if st, err := s.FS.Stat(); err == nil {
+		if !first {
+			vals[SeriesCPU] = metrics.Busy(s.prevCPU, st.Total)
+		}
+		s.prevCPU = st.Total
+	}
+	if mi, err := s.FS.MemInfo(); err == nil {
+		vals[SeriesMem] = mi.UsedPercent()
+		vals[SeriesSwap] = mi.SwapPercent()
+	}
+	if du, err := metrics.DiskUsage(s.DiskPath); err == nil {
+		vals[SeriesDisk] = du.UsedPercent()
+	}
+	if devs, err := s.FS.NetDev(); err == nil {
+		rx, tx := metrics.NetTotals(devs)
+		if secs := now.Sub(s.prevAt).Seconds(); !first && secs > 0 && rx >= s.prevRx && tx >= s.prevTx {
+			vals[SeriesNetRx] = float64(rx-s.prevRx) / secs
+			vals[SeriesNetTx] = float64(tx-s.prevTx) / secs
+		}
+		s.prevRx, s.prevTx = rx, tx
+	}
+	s.prevAt = now
+	for series, v := range vals {
+		if err := s.Store.Add(series, now, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package history persists metric samples in fixed-size ring files with
+// three downsampled tiers, so recent data is kept at full resolution and
+// older data as minute and hour averages.
+package history
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"syskit/internal/filelock"
+)
+
+// Tier is one resolution level of the store.
+type Tier struct {
+	Name  string
+	Step  time.Duration
+	Slots int
+}
+
+// Retention is how far back a tier reaches.
+func (t Tier) Retention() time.Duration { return t.Step * time.Duration(t.Slots) }
+
+// Tiers lists the resolutions from finest to coarsest: 1s for an hour,
+// 1m for a day and 1h for 30 days.
+var Tiers = []Tier{
+	{Name: "1s", Step: time.Second, Slots: 3600},
+	{Name: "1m", Step: time.Minute, Slots: 1440},
+	{Name: "1h", Step: time.Hour, Slots: 720},
+}
+
+// each slot holds a unix timestamp (0 = empty) and a float64 value
+const recordSize = 16
+
+// Point is one stored sample.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Store is a directory of ring files, one per series and tier. Access is
+// serialised between processes (the agent, "history record" and queries)
+// with an advisory lock on a .lock file in the directory.
+type Store struct {
+	dir  string
+	mu   sync.Mutex
+	last map[string]time.Time // last write per series, drives rollups
+}
+
+// DefaultDir returns ~/.syskit/history.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".syskit", "history")
+}
+
+// Open returns a store rooted at dir, creating it if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir, last: map[string]time.Time{}}, nil
+}
+
+func (s *Store) file(series string, t Tier) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s.%s.ring", series, t.Name))
+}
+
+func (s *Store) lock() (func(), error) {
+	s.mu.Lock()
+	unlock, err := filelock.Lock(filepath.Join(s.dir, ".lock"))
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		unlock()
+		s.mu.Unlock()
+	}, nil
+}
+
+// Add records value for series at time t in the finest tier. Whenever t
+// enters a new minute (hour) the previous one is averaged into the next tier.
+// The buckets still open are the finer samples already on disk, so after a
+// restart the newest of them resumes the rollup where it stopped.
+func (s *Store) Add(series string, t time.Time, value float64) error {
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	prev, ok := s.last[series]
+	if !ok {
+		if prev, err = s.newest(series, Tiers[0]); err != nil {
+			return err
+		}
+		ok = !prev.IsZero()
+	}
+	// roll up before writing t, which may reuse the slot of an old sample
+	for i := 1; ok && i < len(Tiers); i++ {
+		coarse := Tiers[i]
+		bucket := prev.Truncate(coarse.Step)
+		if !t.Truncate(coarse.Step).After(bucket) {
+			break
+		}
+		pts, err := s.read(series, Tiers[i-1], bucket, bucket.Add(coarse.Step-time.Nanosecond))
+		if err != nil {
+			return err
+		}
+		if len(pts) == 0 {
+			continue
+		}
+		var sum float64
+		for _, p := range pts {
+			sum += p.Value
+		}
+		if err := s.write(series, coarse, bucket, sum/float64(len(pts))); err != nil {
+			return err
+		}
+	}
+	if err := s.write(series, Tiers[0], t, value); err != nil {
+		return err
+	}
+	s.last[series] = t
+	return nil
+}
+
+func (s *Store) write(series string, tier Tier, t time.Time, value float64) error {
+	f, err := os.OpenFile(s.file(series, tier), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ts := t.Truncate(tier.Step).Unix()
+	slot := (ts / int64(tier.Step/time.Second)) % int64(tier.Slots)
+	var rec [recordSize]byte
+	binary.LittleEndian.PutUint64(rec[0:], uint64(ts))
+	binary.LittleEndian.PutUint64(rec[8:], math.Float64bits(value))
+	_, err = f.WriteAt(rec[:], slot*recordSize)
+	return err
+}
+
+func (s *Store) read(series string, tier Tier, since, until time.Time) ([]Point, error) {
+	b, err := os.ReadFile(s.file(series, tier))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var pts []Point
+	for off := 0; off+recordSize <= len(b); off += recordSize {
+		ts := int64(binary.LittleEndian.Uint64(b[off:]))
+		if ts == 0 {
+			continue
+		}
+		t := time.Unix(ts, 0)
+		if t.Before(since) || t.After(until) {
+			continue
+		}
+		pts = append(pts, Point{Time: t, Value: math.Float64frombits(binary.LittleEndian.Uint64(b[off+8:]))})
+	}
+	sort.Slice(pts, func(i, j int) bool { return pts[i].Time.Before(pts[j].Time) })
+	return pts, nil
+}
+
+// newest returns the time of the latest record of series in tier, or the
+// zero time when there is none.
+func (s *Store) newest(series string, tier Tier) (time.Time, error) {
+	pts, err := s.read(series, tier, time.Time{}, time.Unix(1<<62, 0)) // unbounded
+	if err != nil || len(pts) == 0 {
+		return time.Time{}, err
+	}
+	return pts[len(pts)-1].Time, nil
+}
+
+// TierFor picks the finest tier whose retention covers since. One extra
+// step of slack keeps "--since 1h" on the 1s tier despite clock drift
+// between parsing the flag and querying.
+func TierFor(since, now time.Time) Tier {
+	for _, t := range Tiers {
+		if now.Sub(since) <= t.Retention()+t.Step {
+			return t
+		}
+	}
+	return Tiers[len(Tiers)-1]
+}
+
+// Query returns points of series in [since, until] from the finest tier
+// that still covers since.
+func (s *Store) Query(series string, since, until time.Time) ([]Point, Tier, error) {
+	tier := TierFor(since, time.Now())
+	unlock, err := s.lock()
+	if err != nil {
+		return nil, tier, err
+	}
+	defer unlock()
+	pts, err := s.read(series, tier, since, until)
+	return pts, tier, err
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupAcrossRestart(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Unix(1709280000, 0) // on a minute and hour boundary
+	st, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []float64{10, 20, 30} {
+		if err := st.Add("cpu", t0.Add(time.Duration(i*20)*time.Second), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a new process picks up the open minute from the 1s ring
+	st, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Add("cpu", t0.Add(time.Hour), 90); err != nil {
+		t.Fatal(err)
+	}
+	minutes, err := st.read("cpu", Tiers[1], t0, t0.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(minutes) != 1 || !minutes[0].Time.Equal(t0) || minutes[0].Value != 20 {
+		t.Errorf("1m tier = %+v, want one point of 20 at %v", minutes, t0)
+	}
+	hours, err := st.read("cpu", Tiers[2], t0, t0.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 1 || hours[0].Value != 20 {
+		t.Errorf("1h tier = %+v, want one point of 20", hours)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	st, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		if err := st.Add("mem", now.Add(time.Duration(i-4)*time.Second), float64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pts, tier, err := st.Query("mem", now.Add(-2*time.Second), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tier.Name != "1s" || len(pts) != 3 || pts[0].Value != 2 || pts[2].Value != 4 {
+		t.Errorf("got %s %+v", tier.Name, pts)
+	}
+	if pts, _, err := st.Query("swap", now.Add(-time.Minute), now); err != nil || len(pts) != 0 {
+		t.Errorf("unknown series: %v %v", pts, err)
+	}
+}
@@ -0,0 +1,259 @@
+package inventory
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DMI is the SMBIOS identification exported under /sys/class/dmi/id.
+// Serial numbers and the UUID are only readable by root.
+type DMI struct {
+	SysVendor      string `json:"sys_vendor,omitempty" yaml:"sys_vendor,omitempty"`
+	ProductName    string `json:"product_name,omitempty" yaml:"product_name,omitempty"`
+	ProductVersion string `json:"product_version,omitempty" yaml:"product_version,omitempty"`
+	ProductSerial  string `json:"product_serial,omitempty" yaml:"product_serial,omitempty"`
+	ProductUUID    string `json:"product_uuid,omitempty" yaml:"product_uuid,omitempty"`
+	BoardVendor    string `json:"board_vendor,omitempty" yaml:"board_vendor,omitempty"`
+	BoardName      string `json:"board_name,omitempty" yaml:"board_name,omitempty"`
+	BoardSerial    string `json:"board_serial,omitempty" yaml:"board_serial,omitempty"`
+	BIOSVendor     string `json:"bios_vendor,omitempty" yaml:"bios_vendor,omitempty"`
+	BIOSVersion    string `json:"bios_version,omitempty" yaml:"bios_version,omitempty"`
+	BIOSDate       string `json:"bios_date,omitempty" yaml:"bios_date,omitempty"`
+	ChassisType    string `json:"chassis_type,omitempty" yaml:"chassis_type,omitempty"`
+}
+
+// chassisTypes names the SMBIOS chassis type codes most often seen.
+var chassisTypes = map[string]string{
+	"1": "Other", "2": "Unknown", "3": "Desktop", "4": "Low Profile Desktop",
+	"6": "Mini Tower", "7": "Tower", "8": "Portable", "9": "Laptop",
+	"10": "Notebook", "13": "All in One", "14": "Sub Notebook", "17": "Main Server Chassis",
+	"23": "Rack Mount Chassis", "30": "Tablet", "31": "Convertible", "32": "Detachable",
+	"35": "Mini PC", "36": "Stick PC",
+}
+
+func (fs FS) DMI() DMI {
+	id := func(name string) string { return readString(join(fs.Sys, "class", "dmi", "id", name)) }
+	d := DMI{
+		SysVendor:      id("sys_vendor"),
+		ProductName:    id("product_name"),
+		ProductVersion: id("product_version"),
+		ProductSerial:  id("product_serial"),
+		ProductUUID:    id("product_uuid"),
+		BoardVendor:    id("board_vendor"),
+		BoardName:      id("board_name"),
+		BoardSerial:    id("board_serial"),
+		BIOSVendor:     id("bios_vendor"),
+		BIOSVersion:    id("bios_version"),
+		BIOSDate:       id("bios_date"),
+		ChassisType:    id("chassis_type"),
+	}
+	if name, ok := chassisTypes[d.ChassisType]; ok {
+		d.ChassisType = name
+	}
+	return d
+}
+
+// BlockDevice is a whole disk from /sys/block. Loop and RAM devices are
+// left out.
+type BlockDevice struct {
+	Name       string `json:"name" yaml:"name"`
+	Model      string `json:"model,omitempty" yaml:"model,omitempty"`
+	Vendor     string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	Serial     string `json:"serial,omitempty" yaml:"serial,omitempty"`
+	Size       uint64 `json:"size_bytes" yaml:"size_bytes"`
+	Rotational bool   `json:"rotational" yaml:"rotational"`
+	Removable  bool   `json:"removable" yaml:"removable"`
+}
+
+func (fs FS) BlockDevices() []BlockDevice {
+	out := []BlockDevice{}
+	for _, name := range listDir(join(fs.Sys, "block")) {
+		if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+			continue
+		}
+		attr := func(elem ...string) string {
+			return readString(join(fs.Sys, append([]string{"block", name}, elem...)...))
+		}
+		b := BlockDevice{
+			Name:       name,
+			Model:      attr("device", "model"),
+			Vendor:     attr("device", "vendor"),
+			Serial:     attr("device", "serial"),
+			Size:       readUint(join(fs.Sys, "block", name, "size")) * 512, // always 512-byte sectors
+			Rotational: attr("queue", "rotational") == "1",
+			Removable:  attr("removable") == "1",
+		}
+		if b.Serial == "" {
+			b.Serial = attr("serial") // virtio
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// PCIDevice is one function on the PCI bus. Names come from pci.ids when
+// it is installed.
+type PCIDevice struct {
+	Slot     string `json:"slot" yaml:"slot"`
+	Class    string `json:"class" yaml:"class"`
+	VendorID string `json:"vendor_id" yaml:"vendor_id"`
+	DeviceID string `json:"device_id" yaml:"device_id"`
+	Vendor   string `json:"vendor,omitempty" yaml:"vendor,omitempty"`
+	Device   string `json:"device,omitempty" yaml:"device,omitempty"`
+	Driver   string `json:"driver,omitempty" yaml:"driver,omitempty"`
+}
+
+// pciClasses names the PCI base classes (the first byte of the class code).
+var pciClasses = map[string]string{
+	"00": "Unclassified", "01": "Storage controller", "02": "Network controller",
+	"03": "Display controller", "04": "Multimedia controller", "05": "Memory controller",
+	"06": "Bridge", "07": "Communication controller", "08": "System peripheral",
+	"09": "Input device", "0a": "Docking station", "0b": "Processor",
+	"0c": "Serial bus controller", "0d": "Wireless controller", "0e": "Intelligent controller",
+	"0f": "Satellite controller", "10": "Encryption controller", "11": "Signal processing controller",
+	"12": "Processing accelerator", "13": "Non-essential instrumentation", "40": "Coprocessor",
+}
+
+func (fs FS) PCIDevices() []PCIDevice {
+	out := []PCIDevice{}
+	ids := fs.loadIDs("pci.ids")
+	for _, slot := range listDir(join(fs.Sys, "bus", "pci", "devices")) {
+		dir := join(fs.Sys, "bus", "pci", "devices", slot)
+		hex := func(name string) string { return strings.TrimPrefix(readString(join(dir, name)), "0x") }
+		d := PCIDevice{Slot: slot, VendorID: hex("vendor"), DeviceID: hex("device"), Driver: linkBase(join(dir, "driver"))}
+		if class := hex("class"); len(class) >= 2 {
+			d.Class = pciClasses[class[:2]]
+			if d.Class == "" {
+				d.Class = class
+			}
+		}
+		d.Vendor, d.Device = ids.lookup(d.VendorID, d.DeviceID)
+		out = append(out, d)
+	}
+	return out
+}
+
+// USBDevice is a device on a USB bus, hubs included.
+type USBDevice struct {
+	Bus          int    `json:"bus" yaml:"bus"`
+	Device       int    `json:"device" yaml:"device"`
+	VendorID     string `json:"vendor_id" yaml:"vendor_id"`
+	ProductID    string `json:"product_id" yaml:"product_id"`
+	Manufacturer string `json:"manufacturer,omitempty" yaml:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty" yaml:"product,omitempty"`
+	SpeedMbps    string `json:"speed_mbps,omitempty" yaml:"speed_mbps,omitempty"`
+}
+
+func (fs FS) USBDevices() []USBDevice {
+	out := []USBDevice{}
+	ids := fs.loadIDs("usb.ids")
+	for _, name := range listDir(join(fs.Sys, "bus", "usb", "devices")) {
+		dir := join(fs.Sys, "bus", "usb", "devices", name)
+		vendor := readString(join(dir, "idVendor"))
+		if vendor == "" {
+			continue // interfaces have no idVendor
+		}
+		d := USBDevice{
+			VendorID:     vendor,
+			ProductID:    readString(join(dir, "idProduct")),
+			Manufacturer: readString(join(dir, "manufacturer")),
+			Product:      readString(join(dir, "product")),
+			SpeedMbps:    readString(join(dir, "speed")),
+		}
+		d.Bus, _ = strconv.Atoi(readString(join(dir, "busnum")))
+		d.Device, _ = strconv.Atoi(readString(join(dir, "devnum")))
+		v, p := ids.lookup(d.VendorID, d.ProductID)
+		if d.Manufacturer == "" {
+			d.Manufacturer = v
+		}
+		if d.Product == "" {
+			d.Product = p
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// NIC is a network interface from /sys/class/net. Speed is -1 or 0 when
+// the link is down or the driver does not report it.
+type NIC struct {
+	Name      string `json:"name" yaml:"name"`
+	MAC       string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	Driver    string `json:"driver,omitempty" yaml:"driver,omitempty"`
+	SpeedMbps int    `json:"speed_mbps" yaml:"speed_mbps"`
+	Duplex    string `json:"duplex,omitempty" yaml:"duplex,omitempty"`
+	State     string `json:"state" yaml:"state"`
+	MTU       int    `json:"mtu" yaml:"mtu"`
+	Virtual   bool   `json:"virtual" yaml:"virtual"`
+}
+
+func (fs FS) NICs() []NIC {
+	out := []NIC{}
+	for _, name := range listDir(join(fs.Sys, "class", "net")) {
+		dir := join(fs.Sys, "class", "net", name)
+		n := NIC{
+			Name:   name,
+			MAC:    readString(join(dir, "address")),
+			Driver: linkBase(join(dir, "device", "driver")),
+			Duplex: readString(join(dir, "duplex")),
+			State:  readString(join(dir, "operstate")),
+		}
+		if _, err := os.Stat(join(dir, "device")); err != nil {
+			n.Virtual = true
+		}
+		n.SpeedMbps, _ = strconv.Atoi(readString(join(dir, "speed")))
+		n.MTU, _ = strconv.Atoi(readString(join(dir, "mtu")))
+		out = append(out, n)
+	}
+	return out
+}
+
+// idDB is a parsed pci.ids or usb.ids: vendor names and, per vendor,
+// device names, all keyed by lower-case hex ID.
+type idDB struct {
+	vendors map[string]string
+	devices map[string]map[string]string
+}
+
+// idPaths are where distributions install the hwdata ID databases.
+var idPaths = []string{"usr/share/hwdata", "usr/share/misc", "usr/share"}
+
+func (fs FS) loadIDs(file string) idDB {
+	db := idDB{vendors: map[string]string{}, devices: map[string]map[string]string{}}
+	for _, dir := range idPaths {
+		f, err := os.Open(join(fs.Root, dir, file))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+		var vendor string
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case line == "" || line[0] == '#':
+			case strings.HasPrefix(line, "C "):
+				return db // device classes follow the vendor list
+			case line[0] == '\t' && (len(line) < 2 || line[1] != '\t'):
+				if id, name, ok := strings.Cut(strings.TrimSpace(line), "  "); ok && vendor != "" {
+					db.devices[vendor][strings.ToLower(id)] = strings.TrimSpace(name)
+				}
+			case line[0] != '\t':
+				if id, name, ok := strings.Cut(line, "  "); ok {
+					vendor = strings.ToLower(id)
+					db.vendors[vendor] = strings.TrimSpace(name)
+					db.devices[vendor] = map[string]string{}
+				}
+			}
+		}
+		return db
+	}
+	return db
+}
+
+func (db idDB) lookup(vendor, device string) (string, string) {
+	vendor, device = strings.ToLower(vendor), strings.ToLower(device)
+	return db.vendors[vendor], db.devices[vendor][device]
+}
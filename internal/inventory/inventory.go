@@ -0,0 +1,94 @@
+// Package inventory collects a hardware and software inventory from sysfs,
+// procfs and package databases for `syskit info --verbose`. Every section is
+// best effort: files that are missing or unreadable (DMI serials need root,
+// containers hide most of /sys) leave the section empty instead of failing.
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FS locates the filesystems the inventory is read from, so it can be
+// collected from a mounted image or a test fixture.
+type FS struct {
+	Sys  string // /sys
+	Proc string // /proc
+	Root string // /, for /etc, /var/lib and /usr/share
+}
+
+// Default reads from the live system.
+var Default = FS{Sys: "/sys", Proc: "/proc", Root: "/"}
+
+// Inventory is the complete verbose report.
+type Inventory struct {
+	DMI            DMI            `json:"dmi" yaml:"dmi"`
+	Block          []BlockDevice  `json:"block" yaml:"block"`
+	PCI            []PCIDevice    `json:"pci" yaml:"pci"`
+	USB            []USBDevice    `json:"usb" yaml:"usb"`
+	NICs           []NIC          `json:"nics" yaml:"nics"`
+	Kernel         Kernel         `json:"kernel" yaml:"kernel"`
+	Virtualization Virtualization `json:"virtualization" yaml:"virtualization"`
+	Packages       map[string]int `json:"packages" yaml:"packages"`
+}
+
+// Collect reads every section.
+func (fs FS) Collect() Inventory {
+	dmi := fs.DMI()
+	return Inventory{
+		DMI:            dmi,
+		Block:          fs.BlockDevices(),
+		PCI:            fs.PCIDevices(),
+		USB:            fs.USBDevices(),
+		NICs:           fs.NICs(),
+		Kernel:         fs.Kernel(),
+		Virtualization: fs.Virtualization(dmi),
+		Packages:       fs.Packages(),
+	}
+}
+
+// Collect reads the inventory of the live system.
+func Collect() Inventory { return Default.Collect() }
+
+func join(root string, elem ...string) string {
+	return filepath.Join(append([]string{root}, elem...)...)
+}
+
+// readString returns a trimmed file, or "" if it cannot be read.
+func readString(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readUint(path string) uint64 {
+	n, _ := strconv.ParseUint(readString(path), 0, 64)
+	return n
+}
+
+// linkBase returns the last element of a symlink target, e.g. the driver
+// name behind device/driver.
+func linkBase(path string) string {
+	t, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(t)
+}
+
+// listDir returns the entry names of dir, or nil.
+func listDir(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	return names
+}
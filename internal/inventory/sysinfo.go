@@ -0,0 +1,149 @@
+package inventory
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// SystemInfo is the document printed by `syskit info`. The quiet form
+// fills only the identification fields; the verbose form adds Inventory.
+type SystemInfo struct {
+	Hostname      string         `json:"hostname" yaml:"hostname"`
+	OS            string         `json:"os" yaml:"os"`
+	Kernel        string         `json:"kernel" yaml:"kernel"`
+	UptimeSeconds int64          `json:"uptime_seconds" yaml:"uptime_seconds"`
+	CPU           *CPUSummary    `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory        *MemorySummary `json:"memory,omitempty" yaml:"memory,omitempty"`
+	Disk          *DiskSummary   `json:"disk,omitempty" yaml:"disk,omitempty"`
+	GPU           string         `json:"gpu,omitempty" yaml:"gpu,omitempty"`
+	Interfaces    []Interface    `json:"interfaces,omitempty" yaml:"interfaces,omitempty"`
+	Inventory     *Inventory     `json:"inventory,omitempty" yaml:"inventory,omitempty"`
+}
+
+// CPUSummary is the processor model, logical CPU count and load averages.
+type CPUSummary struct {
+	Model  string  `json:"model" yaml:"model"`
+	Cores  int     `json:"cores" yaml:"cores"`
+	MHz    float64 `json:"mhz,omitempty" yaml:"mhz,omitempty"`
+	Load1  float64 `json:"load1" yaml:"load1"`
+	Load5  float64 `json:"load5" yaml:"load5"`
+	Load15 float64 `json:"load15" yaml:"load15"`
+}
+
+// MemorySummary is in bytes.
+type MemorySummary struct {
+	Total     uint64 `json:"total" yaml:"total"`
+	Available uint64 `json:"available" yaml:"available"`
+	SwapTotal uint64 `json:"swap_total" yaml:"swap_total"`
+	SwapUsed  uint64 `json:"swap_used" yaml:"swap_used"`
+}
+
+// DiskSummary is the usage of the root filesystem in bytes.
+type DiskSummary struct {
+	Path        string  `json:"path" yaml:"path"`
+	Total       uint64  `json:"total" yaml:"total"`
+	Used        uint64  `json:"used" yaml:"used"`
+	UsedPercent float64 `json:"used_percent" yaml:"used_percent"`
+}
+
+// Interface lists the addresses of one network interface.
+type Interface struct {
+	Name  string   `json:"name" yaml:"name"`
+	Addrs []string `json:"addrs" yaml:"addrs"`
+}
+
+// Basic returns the identification fields only, for `info --quiet`.
+func Basic() SystemInfo {
+	si := SystemInfo{OS: osRelease(), Kernel: kernelRelease()}
+	si.Hostname, _ = os.Hostname()
+	if d, err := metrics.Default.Uptime(); err == nil {
+		si.UptimeSeconds = int64(d / time.Second)
+	}
+	return si
+}
+
+// Gather returns the full summary, with the hardware and software
+// inventory when verbose is set.
+func Gather(verbose bool) SystemInfo {
+	si := Basic()
+
+	cpu := &CPUSummary{Cores: runtime.NumCPU()}
+	if cpus, err := metrics.Default.CPUInfo(); err == nil && len(cpus) > 0 {
+		cpu.Model, cpu.MHz = cpus[0].ModelName, cpus[0].MHz
+	}
+	if l, err := metrics.Default.LoadAvg(); err == nil {
+		cpu.Load1, cpu.Load5, cpu.Load15 = l.Load1, l.Load5, l.Load15
+	}
+	si.CPU = cpu
+
+	if mi, err := metrics.Default.MemInfo(); err == nil {
+		si.Memory = &MemorySummary{Total: mi.MemTotal, Available: mi.MemAvailable, SwapTotal: mi.SwapTotal, SwapUsed: mi.SwapUsed()}
+	}
+	if u, err := metrics.DiskUsage("/"); err == nil {
+		si.Disk = &DiskSummary{Path: "/", Total: u.Total, Used: u.Used, UsedPercent: u.UsedPercent()}
+	}
+
+	pci := Default.PCIDevices()
+	for _, p := range pci {
+		if p.Class == "Display controller" {
+			si.GPU = strings.TrimSpace(p.Vendor + " " + p.Device)
+			if si.GPU == "" {
+				si.GPU = p.VendorID + ":" + p.DeviceID
+			}
+			break
+		}
+	}
+
+	ifaces, _ := net.Interfaces()
+	for _, iface := range ifaces {
+		addrs, _ := iface.Addrs()
+		if len(addrs) == 0 {
+			continue
+		}
+		it := Interface{Name: iface.Name}
+		for _, a := range addrs {
+			it.Addrs = append(it.Addrs, a.String())
+		}
+		si.Interfaces = append(si.Interfaces, it)
+	}
+
+	if verbose {
+		inv := Collect()
+		si.Inventory = &inv
+	}
+	return si
+}
+
+func osRelease() string {
+	b, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return runtime.GOOS
+	}
+	var name, ver string
+	for _, line := range strings.Split(string(b), "\n") {
+		if v, ok := strings.CutPrefix(line, "NAME="); ok && name == "" {
+			name = strings.Trim(v, `"`)
+		}
+		if v, ok := strings.CutPrefix(line, "VERSION_ID="); ok && ver == "" {
+			ver = strings.Trim(v, `"`)
+		}
+	}
+	return strings.TrimSpace(name + " " + ver)
+}
+
+func kernelRelease() string {
+	if r := readString(join(Default.Proc, "sys", "kernel", "osrelease")); r != "" {
+		return r
+	}
+	out, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,219 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"syskit/internal/container"
+)
+
+// Module is a loaded kernel module from /proc/modules.
+type Module struct {
+	Name   string   `json:"name" yaml:"name"`
+	Size   uint64   `json:"size" yaml:"size"`
+	UsedBy []string `json:"used_by,omitempty" yaml:"used_by,omitempty"`
+}
+
+// Kernel describes the running kernel.
+type Kernel struct {
+	Release string   `json:"release" yaml:"release"`
+	Version string   `json:"version,omitempty" yaml:"version,omitempty"`
+	Cmdline string   `json:"cmdline" yaml:"cmdline"`
+	Modules []Module `json:"modules" yaml:"modules"`
+}
+
+func (fs FS) Kernel() Kernel {
+	k := Kernel{
+		Release: readString(join(fs.Proc, "sys", "kernel", "osrelease")),
+		Version: readString(join(fs.Proc, "sys", "kernel", "version")),
+		Cmdline: readString(join(fs.Proc, "cmdline")),
+		Modules: []Module{},
+	}
+	if mods, err := fs.Modules(); err == nil {
+		k.Modules = mods
+	}
+	return k
+}
+
+// Modules reads the loaded kernel modules from /proc/modules.
+func (fs FS) Modules() ([]Module, error) {
+	f, err := os.Open(join(fs.Proc, "modules"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	mods := []Module{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		// name size refcount used,by, state address
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		m := Module{Name: fields[0]}
+		m.Size, _ = strconv.ParseUint(fields[1], 10, 64)
+		for _, u := range strings.Split(fields[3], ",") {
+			if u != "" && u != "-" {
+				m.UsedBy = append(m.UsedBy, u)
+			}
+		}
+		mods = append(mods, m)
+	}
+	return mods, sc.Err()
+}
+
+// Virtualization names the hypervisor and container runtime the system runs
+// under; empty fields mean bare metal and no container.
+type Virtualization struct {
+	Hypervisor string `json:"hypervisor,omitempty" yaml:"hypervisor,omitempty"`
+	Container  string `json:"container,omitempty" yaml:"container,omitempty"`
+}
+
+// dmiHypervisors maps substrings of the DMI vendor or product name to a
+// hypervisor, as systemd-detect-virt does.
+var dmiHypervisors = []struct{ match, name string }{
+	{"KVM", "kvm"},
+	{"QEMU", "qemu"},
+	{"VMware", "vmware"},
+	{"VirtualBox", "oracle"},
+	{"innotek", "oracle"},
+	{"Xen", "xen"},
+	{"Microsoft Corporation", "microsoft"},
+	{"Amazon EC2", "amazon"},
+	{"Google Compute Engine", "google"},
+	{"Parallels", "parallels"},
+	{"BHYVE", "bhyve"},
+	{"Firecracker", "firecracker"},
+}
+
+func (fs FS) Virtualization(dmi DMI) Virtualization {
+	var v Virtualization
+	for _, s := range []string{dmi.SysVendor, dmi.ProductName, dmi.BoardVendor, dmi.BIOSVendor} {
+		for _, h := range dmiHypervisors {
+			if v.Hypervisor == "" && strings.Contains(s, h.match) {
+				v.Hypervisor = h.name
+			}
+		}
+	}
+	if v.Hypervisor == "" {
+		v.Hypervisor = readString(join(fs.Sys, "hypervisor", "type"))
+	}
+	if v.Hypervisor == "" && hasCPUFlag(join(fs.Proc, "cpuinfo"), "hypervisor") {
+		v.Hypervisor = "unknown"
+	}
+	if strings.Contains(strings.ToLower(readString(join(fs.Proc, "sys", "kernel", "osrelease"))), "microsoft") {
+		v.Hypervisor = "wsl"
+	}
+	v.Container = fs.containerRuntime()
+	return v
+}
+
+func (fs FS) containerRuntime() string {
+	if env, err := os.ReadFile(join(fs.Proc, "1", "environ")); err == nil {
+		for _, kv := range bytes.Split(env, []byte{0}) {
+			if name, ok := bytes.CutPrefix(kv, []byte("container=")); ok {
+				return string(name)
+			}
+		}
+	}
+	switch {
+	case exists(join(fs.Root, ".dockerenv")):
+		return "docker"
+	case exists(join(fs.Root, "run", ".containerenv")):
+		return "podman"
+	}
+	if fs.Proc == Default.Proc {
+		if id := container.ForPID(1); id != "" {
+			runtime, _, _ := strings.Cut(id, ":")
+			return runtime
+		}
+	}
+	return ""
+}
+
+func hasCPUFlag(cpuinfo, flag string) bool {
+	f, err := os.Open(cpuinfo)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if ok && strings.TrimSpace(key) == "flags" {
+			for _, fl := range strings.Fields(val) {
+				if fl == flag {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Packages counts installed packages per package manager found on the
+// system. rpm has no plain-text database, so it is queried via rpm(8).
+func (fs FS) Packages() map[string]int {
+	out := map[string]int{}
+	if n, ok := countLines(join(fs.Root, "var", "lib", "dpkg", "status"), func(l string) bool {
+		return strings.HasPrefix(l, "Status: ") && strings.HasSuffix(l, " installed")
+	}); ok {
+		out["dpkg"] = n
+	}
+	if n, ok := countLines(join(fs.Root, "lib", "apk", "db", "installed"), func(l string) bool {
+		return strings.HasPrefix(l, "P:")
+	}); ok {
+		out["apk"] = n
+	}
+	if names := listDir(join(fs.Root, "var", "lib", "pacman", "local")); names != nil {
+		n := 0
+		for _, name := range names {
+			if name != "ALPM_DB_VERSION" {
+				n++
+			}
+		}
+		out["pacman"] = n
+	}
+	if names := listDir(join(fs.Root, "var", "lib", "snapd", "snaps")); names != nil {
+		n := 0
+		for _, name := range names {
+			if strings.HasSuffix(name, ".snap") {
+				n++
+			}
+		}
+		out["snap"] = n
+	}
+	if fs.Root == Default.Root && exists("/var/lib/rpm") {
+		if b, err := exec.Command("rpm", "-qa").Output(); err == nil {
+			out["rpm"] = len(strings.Fields(string(b)))
+		}
+	}
+	return out
+}
+
+func countLines(path string, match func(string) bool) (int, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	n := 0
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if match(sc.Text()) {
+			n++
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CPUStat holds cumulative jiffies for one "cpu" line of /proc/stat.
+type CPUStat struct {
+	Name      string
+	User      uint64
+	Nice      uint64
+	System    uint64
+	Idle      uint64
+	IOWait    uint64
+	IRQ       uint64
+	SoftIRQ   uint64
+	Steal     uint64
+	Guest     uint64
+	GuestNice uint64
+}
+
+// Total returns all accounted jiffies. Guest time is already included in
+// User/Nice by the kernel and is therefore not added again.
+func (c CPUStat) Total() uint64 {
+	return c.User + c.Nice + c.System + c.Idle + c.IOWait + c.IRQ + c.SoftIRQ + c.Steal
+}
+
+// IdleTotal returns idle plus iowait jiffies.
+func (c CPUStat) IdleTotal() uint64 {
+	return c.Idle + c.IOWait
+}
+
+// CPUUsage is the share of time spent in each state between two samples, in percent.
+type CPUUsage struct {
+	Busy   float64 `json:"busy" yaml:"busy"`
+	User   float64 `json:"user" yaml:"user"`
+	System float64 `json:"system" yaml:"system"`
+	IOWait float64 `json:"iowait" yaml:"iowait"`
+	Steal  float64 `json:"steal" yaml:"steal"`
+	IRQ    float64 `json:"irq" yaml:"irq"`
+	Idle   float64 `json:"idle" yaml:"idle"`
+}
+
+// Usage computes percentages for the interval between prev and cur.
+// A zero-length interval yields all zeros.
+func Usage(prev, cur CPUStat) CPUUsage {
+	total := float64(delta(prev.Total(), cur.Total()))
+	if total == 0 {
+		return CPUUsage{}
+	}
+	u := CPUUsage{
+		User:   percent(float64(delta(prev.User+prev.Nice, cur.User+cur.Nice)), total),
+		System: percent(float64(delta(prev.System, cur.System)), total),
+		IOWait: percent(float64(delta(prev.IOWait, cur.IOWait)), total),
+		Steal:  percent(float64(delta(prev.Steal, cur.Steal)), total),
+		IRQ:    percent(float64(delta(prev.IRQ+prev.SoftIRQ, cur.IRQ+cur.SoftIRQ)), total),
+		Idle:   percent(float64(delta(prev.Idle, cur.Idle)), total),
+	}
+	u.Busy = percent(total-float64(delta(prev.IdleTotal(), cur.IdleTotal())), total)
+	return u
+}
+
+// Busy is a shorthand for Usage(prev, cur).Busy.
+func Busy(prev, cur CPUStat) float64 {
+	return Usage(prev, cur).Busy
+}
+
+func delta(a, b uint64) uint64 {
+	if b < a { // counter reset (e.g. CPU hotplug)
+		return 0
+	}
+	return b - a
+}
+
+// Stat is a snapshot of /proc/stat.
+type Stat struct {
+	Total           CPUStat
+	CPUs            []CPUStat
+	BootTime        time.Time
+	ContextSwitches uint64
+	Processes       uint64
+	ProcsRunning    uint64
+	ProcsBlocked    uint64
+}
+
+// Stat parses /proc/stat.
+func (fs FS) Stat() (Stat, error) {
+	lines, err := fs.readLines("stat")
+	if err != nil {
+		return Stat{}, err
+	}
+	var st Stat
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch {
+		case fields[0] == "cpu":
+			st.Total = parseCPUStat(fields)
+		case strings.HasPrefix(fields[0], "cpu"):
+			st.CPUs = append(st.CPUs, parseCPUStat(fields))
+		case fields[0] == "btime":
+			st.BootTime = time.Unix(int64(parseUint(fields[1])), 0)
+		case fields[0] == "ctxt":
+			st.ContextSwitches = parseUint(fields[1])
+		case fields[0] == "processes":
+			st.Processes = parseUint(fields[1])
+		case fields[0] == "procs_running":
+			st.ProcsRunning = parseUint(fields[1])
+		case fields[0] == "procs_blocked":
+			st.ProcsBlocked = parseUint(fields[1])
+		}
+	}
+	if len(st.CPUs) == 0 {
+		return st, fmt.Errorf("%s: no cpu lines", fs.path("stat"))
+	}
+	return st, nil
+}
+
+func parseCPUStat(fields []string) CPUStat {
+	c := CPUStat{Name: fields[0]}
+	dst := []*uint64{&c.User, &c.Nice, &c.System, &c.Idle, &c.IOWait, &c.IRQ, &c.SoftIRQ, &c.Steal, &c.Guest, &c.GuestNice}
+	for i, f := range fields[1:] {
+		if i >= len(dst) {
+			break
+		}
+		*dst[i] = parseUint(f)
+	}
+	return c
+}
+
+// CPUInfo describes one logical processor from /proc/cpuinfo.
+type CPUInfo struct {
+	Processor  int
+	ModelName  string
+	MHz        float64
+	PhysicalID int
+	CoreID     int
+	Flags      []string
+}
+
+// CPUInfo parses /proc/cpuinfo.
+func (fs FS) CPUInfo() ([]CPUInfo, error) {
+	lines, err := fs.readLines("cpuinfo")
+	if err != nil {
+		return nil, err
+	}
+	var out []CPUInfo
+	for _, line := range lines {
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key, val := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if key == "processor" {
+			n, _ := strconv.Atoi(val)
+			out = append(out, CPUInfo{Processor: n})
+			continue
+		}
+		if len(out) == 0 {
+			continue
+		}
+		cur := &out[len(out)-1]
+		switch key {
+		case "model name", "Model":
+			cur.ModelName = val
+		case "cpu MHz":
+			cur.MHz, _ = strconv.ParseFloat(val, 64)
+		case "physical id":
+			cur.PhysicalID, _ = strconv.Atoi(val)
+		case "core id":
+			cur.CoreID, _ = strconv.Atoi(val)
+		case "flags", "Features":
+			cur.Flags = strings.Fields(val)
+		}
+	}
+	return out, nil
+}
+
+// Uptime parses /proc/uptime.
+func (fs FS) Uptime() (time.Duration, error) {
+	lines, err := fs.readLines("uptime")
+	if err != nil {
+		return 0, err
+	}
+	var fields []string
+	if len(lines) > 0 {
+		fields = strings.Fields(lines[0])
+	}
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("%s: empty", fs.path("uptime"))
+	}
+	sec, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(sec * float64(time.Second)), nil
+}
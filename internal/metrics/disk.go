@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SectorSize is the fixed unit of the sector counters in /proc/diskstats.
+const SectorSize = 512
+
+// DiskStats holds cumulative I/O counters for one block device.
+type DiskStats struct {
+	Major          int
+	Minor          int
+	Name           string
+	Reads          uint64
+	ReadsMerged    uint64
+	SectorsRead    uint64
+	ReadTimeMs     uint64
+	Writes         uint64
+	WritesMerged   uint64
+	SectorsWritten uint64
+	WriteTimeMs    uint64
+	InProgress     uint64
+	IOTimeMs       uint64
+	WeightedIOMs   uint64
+}
+
+// ReadBytes returns SectorsRead in bytes.
+func (d DiskStats) ReadBytes() uint64 { return d.SectorsRead * SectorSize }
+
+// WrittenBytes returns SectorsWritten in bytes.
+func (d DiskStats) WrittenBytes() uint64 { return d.SectorsWritten * SectorSize }
+
+// DiskStats parses /proc/diskstats.
+func (fs FS) DiskStats() ([]DiskStats, error) {
+	lines, err := fs.readLines("diskstats")
+	if err != nil {
+		return nil, err
+	}
+	var out []DiskStats
+	for _, line := range lines {
+		f := strings.Fields(line)
+		if len(f) < 14 {
+			continue
+		}
+		major, _ := strconv.Atoi(f[0])
+		minor, _ := strconv.Atoi(f[1])
+		out = append(out, DiskStats{
+			Major:          major,
+			Minor:          minor,
+			Name:           f[2],
+			Reads:          parseUint(f[3]),
+			ReadsMerged:    parseUint(f[4]),
+			SectorsRead:    parseUint(f[5]),
+			ReadTimeMs:     parseUint(f[6]),
+			Writes:         parseUint(f[7]),
+			WritesMerged:   parseUint(f[8]),
+			SectorsWritten: parseUint(f[9]),
+			WriteTimeMs:    parseUint(f[10]),
+			InProgress:     parseUint(f[11]),
+			IOTimeMs:       parseUint(f[12]),
+			WeightedIOMs:   parseUint(f[13]),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,14 @@
+package metrics
+
+// FSUsage describes capacity of a mounted filesystem in bytes.
+type FSUsage struct {
+	Path  string
+	Total uint64
+	Free  uint64 // available to unprivileged users
+	Used  uint64
+}
+
+// UsedPercent returns Used as a percentage of Used+Free, matching df(1).
+func (u FSUsage) UsedPercent() float64 {
+	return percent(float64(u.Used), float64(u.Used+u.Free))
+}
@@ -0,0 +1,21 @@
+//go:build linux
+// +build linux
+
+package metrics
+
+import "syscall"
+
+// DiskUsage returns capacity figures for the filesystem containing path.
+func DiskUsage(path string) (FSUsage, error) {
+	var s syscall.Statfs_t
+	if err := syscall.Statfs(path, &s); err != nil {
+		return FSUsage{}, err
+	}
+	bs := uint64(s.Bsize)
+	return FSUsage{
+		Path:  path,
+		Total: s.Blocks * bs,
+		Free:  s.Bavail * bs,
+		Used:  (s.Blocks - s.Bfree) * bs,
+	}, nil
+}
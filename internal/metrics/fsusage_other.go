@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package metrics
+
+import "errors"
+
+// DiskUsage is only implemented on Linux.
+func DiskUsage(path string) (FSUsage, error) {
+	return FSUsage{}, errors.New("disk usage not supported on this platform")
+}
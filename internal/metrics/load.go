@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadAvg is a snapshot of /proc/loadavg.
+type LoadAvg struct {
+	Load1   float64
+	Load5   float64
+	Load15  float64
+	Running int
+	Total   int
+	LastPID int
+}
+
+// String formats the three load averages like uptime(1).
+func (l LoadAvg) String() string {
+	return fmt.Sprintf("%.2f %.2f %.2f", l.Load1, l.Load5, l.Load15)
+}
+
+// LoadAvg parses /proc/loadavg.
+func (fs FS) LoadAvg() (LoadAvg, error) {
+	lines, err := fs.readLines("loadavg")
+	if err != nil {
+		return LoadAvg{}, err
+	}
+	if len(lines) == 0 {
+		return LoadAvg{}, fmt.Errorf("%s: empty", fs.path("loadavg"))
+	}
+	f := strings.Fields(lines[0])
+	if len(f) < 5 {
+		return LoadAvg{}, fmt.Errorf("%s: malformed", fs.path("loadavg"))
+	}
+	var l LoadAvg
+	l.Load1, _ = strconv.ParseFloat(f[0], 64)
+	l.Load5, _ = strconv.ParseFloat(f[1], 64)
+	l.Load15, _ = strconv.ParseFloat(f[2], 64)
+	if rt := strings.SplitN(f[3], "/", 2); len(rt) == 2 {
+		l.Running, _ = strconv.Atoi(rt[0])
+		l.Total, _ = strconv.Atoi(rt[1])
+	}
+	l.LastPID, _ = strconv.Atoi(f[4])
+	return l, nil
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MemInfo is a snapshot of /proc/meminfo. All values are in bytes.
+type MemInfo struct {
+	MemTotal     uint64
+	MemFree      uint64
+	MemAvailable uint64
+	Buffers      uint64
+	Cached       uint64
+	SwapCached   uint64
+	SwapTotal    uint64
+	SwapFree     uint64
+	Shmem        uint64
+	Slab         uint64
+	SReclaimable uint64
+	// Raw holds every field by its /proc/meminfo name, in bytes
+	// (HugePages_* counts are kept as plain numbers).
+	Raw map[string]uint64
+}
+
+// Used returns memory not available to new allocations (Total - Available).
+func (m MemInfo) Used() uint64 {
+	if m.MemAvailable > m.MemTotal {
+		return 0
+	}
+	return m.MemTotal - m.MemAvailable
+}
+
+// UsedPercent returns Used as a percentage of MemTotal.
+func (m MemInfo) UsedPercent() float64 {
+	return percent(float64(m.Used()), float64(m.MemTotal))
+}
+
+// SwapUsed returns used swap in bytes.
+func (m MemInfo) SwapUsed() uint64 {
+	if m.SwapFree > m.SwapTotal {
+		return 0
+	}
+	return m.SwapTotal - m.SwapFree
+}
+
+// SwapPercent returns used swap as a percentage of SwapTotal.
+func (m MemInfo) SwapPercent() float64 {
+	return percent(float64(m.SwapUsed()), float64(m.SwapTotal))
+}
+
+// MemInfo parses /proc/meminfo.
+func (fs FS) MemInfo() (MemInfo, error) {
+	lines, err := fs.readLines("meminfo")
+	if err != nil {
+		return MemInfo{}, err
+	}
+	m := MemInfo{Raw: map[string]uint64{}}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		key := strings.TrimSuffix(fields[0], ":")
+		val := parseUint(fields[1])
+		if len(fields) > 2 && fields[2] == "kB" {
+			val *= 1024
+		}
+		m.Raw[key] = val
+	}
+	if _, ok := m.Raw["MemTotal"]; !ok {
+		return m, fmt.Errorf("%s: MemTotal missing", fs.path("meminfo"))
+	}
+	m.MemTotal = m.Raw["MemTotal"]
+	m.MemFree = m.Raw["MemFree"]
+	m.MemAvailable = m.Raw["MemAvailable"]
+	m.Buffers = m.Raw["Buffers"]
+	m.Cached = m.Raw["Cached"]
+	m.SwapCached = m.Raw["SwapCached"]
+	m.SwapTotal = m.Raw["SwapTotal"]
+	m.SwapFree = m.Raw["SwapFree"]
+	m.Shmem = m.Raw["Shmem"]
+	m.Slab = m.Raw["Slab"]
+	m.SReclaimable = m.Raw["SReclaimable"]
+	if _, ok := m.Raw["MemAvailable"]; !ok {
+		// kernels before 3.14 lack MemAvailable
+		m.MemAvailable = m.MemFree + m.Buffers + m.Cached
+	}
+	return m, nil
+}
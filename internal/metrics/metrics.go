@@ -0,0 +1,61 @@
+// Package metrics parses kernel statistics from procfs into typed snapshots.
+// Every reader works against a configurable root so the same code can be
+// pointed at /proc or at a captured copy of it.
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FS reads metrics from a procfs tree.
+type FS struct {
+	Root string
+}
+
+// Default reads from the live /proc.
+var Default = FS{Root: "/proc"}
+
+// NewFS returns an FS rooted at root; an empty root means /proc.
+func NewFS(root string) FS {
+	if root == "" {
+		root = "/proc"
+	}
+	return FS{Root: root}
+}
+
+func (fs FS) path(elem ...string) string {
+	return filepath.Join(append([]string{fs.Root}, elem...)...)
+}
+
+// readLines returns the lines of a procfs file.
+func (fs FS) readLines(elem ...string) ([]string, error) {
+	f, err := os.Open(fs.path(elem...))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines, sc.Err()
+}
+
+func parseUint(s string) uint64 {
+	v, _ := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	return v
+}
+
+// percent returns part/total*100, or 0 when total is zero.
+func percent(part, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return part / total * 100
+}
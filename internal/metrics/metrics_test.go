@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	fixture = NewFS("testdata/proc")
+	broken  = NewFS("testdata/broken")
+)
+
+func TestStat(t *testing.T) {
+	st, err := fixture.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CPUStat{Name: "cpu", User: 10132153, Nice: 290696, System: 3084719, Idle: 46828483,
+		IOWait: 16683, SoftIRQ: 25195, Guest: 175628}
+	if st.Total != want {
+		t.Errorf("total = %+v, want %+v", st.Total, want)
+	}
+	if len(st.CPUs) != 2 || st.CPUs[1].Name != "cpu1" || st.CPUs[1].Idle != 13363006 {
+		t.Errorf("cpus = %+v", st.CPUs)
+	}
+	if !st.BootTime.Equal(time.Unix(1709280000, 0)) {
+		t.Errorf("btime = %v", st.BootTime)
+	}
+	if st.ContextSwitches != 837863 || st.Processes != 14562 || st.ProcsRunning != 3 || st.ProcsBlocked != 1 {
+		t.Errorf("counters = %+v", st)
+	}
+	if _, err := broken.Stat(); err == nil {
+		t.Error("empty stat: expected error")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	prev := CPUStat{User: 100, System: 50, Idle: 800, IOWait: 50}
+	tests := []struct {
+		name string
+		cur  CPUStat
+		want CPUUsage
+	}{
+		{"busy", CPUStat{User: 200, System: 75, Idle: 850, IOWait: 75},
+			CPUUsage{Busy: 62.5, User: 50, System: 12.5, IOWait: 12.5, Idle: 25}},
+		{"idle interval", prev, CPUUsage{}},
+		{"counter reset", CPUStat{User: 10}, CPUUsage{}},
+	}
+	for _, tt := range tests {
+		if got := Usage(prev, tt.cur); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMemInfo(t *testing.T) {
+	m, err := fixture.MemInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MemTotal != 16303428*1024 || m.MemAvailable != 9211272*1024 || m.SwapFree != 1572860*1024 {
+		t.Errorf("got %+v", m)
+	}
+	if m.Raw["HugePages_Total"] != 4 {
+		t.Errorf("HugePages_Total = %d, want 4", m.Raw["HugePages_Total"])
+	}
+	if got, want := m.Used(), uint64(16303428-9211272)*1024; got != want {
+		t.Errorf("used = %d, want %d", got, want)
+	}
+	if got := m.SwapPercent(); got < 24.99 || got > 25.01 {
+		t.Errorf("swap percent = %f, want 25", got)
+	}
+	if _, err := broken.MemInfo(); err == nil {
+		t.Error("missing MemTotal: expected error")
+	}
+}
+
+func TestLoadAvg(t *testing.T) {
+	l, err := fixture.LoadAvg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := LoadAvg{Load1: 0.52, Load5: 0.58, Load15: 0.59, Running: 3, Total: 771, LastPID: 12345}
+	if l != want {
+		t.Errorf("got %+v, want %+v", l, want)
+	}
+	if l.String() != "0.52 0.58 0.59" {
+		t.Errorf("String() = %q", l.String())
+	}
+	if _, err := broken.LoadAvg(); err == nil {
+		t.Error("short loadavg: expected error")
+	}
+}
+
+func TestNetDev(t *testing.T) {
+	devs, err := fixture.NetDev()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []NetDev{
+		{Name: "lo", RxBytes: 2776770, RxPackets: 11307, TxBytes: 2776770, TxPackets: 11307},
+		{Name: "eth0", RxBytes: 1215645, RxPackets: 2751, RxErrs: 1, RxDrop: 2,
+			TxBytes: 1782404, TxPackets: 4324, TxErrs: 3, TxDrop: 4},
+		// no space after the colon once counters get wide
+		{Name: "wlan0", RxBytes: 12345, RxPackets: 10, TxBytes: 67890, TxPackets: 20},
+	}
+	if !reflect.DeepEqual(devs, want) {
+		t.Errorf("got %+v\nwant %+v", devs, want)
+	}
+	if rx, tx := NetTotals(devs); rx != 1215645+12345 || tx != 1782404+67890 {
+		t.Errorf("totals = %d/%d", rx, tx)
+	}
+	if _, err := broken.NetDev(); err == nil {
+		t.Error("missing file: expected error")
+	}
+}
+
+func TestDiskStats(t *testing.T) {
+	disks, err := fixture.DiskStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disks) != 3 {
+		t.Fatalf("got %d devices, want 3", len(disks))
+	}
+	sda := DiskStats{Major: 8, Name: "sda", Reads: 446216, ReadsMerged: 784926, SectorsRead: 9550688,
+		ReadTimeMs: 4274688, Writes: 1073532, WritesMerged: 2264928, SectorsWritten: 33573064,
+		WriteTimeMs: 15339020, IOTimeMs: 2512748, WeightedIOMs: 19677096}
+	if disks[0] != sda {
+		t.Errorf("sda:\n got %+v\nwant %+v", disks[0], sda)
+	}
+	nvme := disks[1]
+	if nvme.Major != 259 || nvme.Name != "nvme0n1" || nvme.InProgress != 2 || nvme.ReadBytes() != 4000000*SectorSize {
+		t.Errorf("nvme0n1 = %+v", nvme)
+	}
+}
+
+func TestUptime(t *testing.T) {
+	d, err := fixture.Uptime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 350735*time.Second + 470*time.Millisecond; d != want {
+		t.Errorf("got %v, want %v", d, want)
+	}
+	// a blank line must be an error, not a panic
+	if _, err := broken.Uptime(); err == nil {
+		t.Error("blank uptime: expected error")
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Mount is one entry of /proc/mounts.
+type Mount struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    string
+}
+
+// IsDevice reports whether the mount is backed by a block device rather
+// than a pseudo filesystem (proc, sysfs, cgroup, overlay, ...).
+func (m Mount) IsDevice() bool {
+	return strings.HasPrefix(m.Device, "/dev/")
+}
+
+// Mounts parses /proc/mounts.
+func (fs FS) Mounts() ([]Mount, error) {
+	lines, err := fs.readLines("mounts")
+	if err != nil {
+		return nil, err
+	}
+	var out []Mount
+	for _, line := range lines {
+		f := strings.Fields(line)
+		if len(f) < 4 {
+			continue
+		}
+		out = append(out, Mount{
+			Device:     unescapeMount(f[0]),
+			MountPoint: unescapeMount(f[1]),
+			FSType:     f[2],
+			Options:    f[3],
+		})
+	}
+	return out, nil
+}
+
+// unescapeMount decodes the octal escapes (\040 for space etc.) the kernel
+// uses for whitespace in mount fields.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
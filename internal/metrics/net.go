@@ -0,0 +1,59 @@
+package metrics
+
+import "strings"
+
+// NetDev holds cumulative counters for one interface from /proc/net/dev.
+type NetDev struct {
+	Name      string
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrs    uint64
+	RxDrop    uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrs    uint64
+	TxDrop    uint64
+}
+
+// NetDev parses /proc/net/dev.
+func (fs FS) NetDev() ([]NetDev, error) {
+	lines, err := fs.readLines("net", "dev")
+	if err != nil {
+		return nil, err
+	}
+	var out []NetDev
+	for _, line := range lines {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		f := strings.Fields(parts[1])
+		if len(f) < 16 {
+			continue
+		}
+		out = append(out, NetDev{
+			Name:      strings.TrimSpace(parts[0]),
+			RxBytes:   parseUint(f[0]),
+			RxPackets: parseUint(f[1]),
+			RxErrs:    parseUint(f[2]),
+			RxDrop:    parseUint(f[3]),
+			TxBytes:   parseUint(f[8]),
+			TxPackets: parseUint(f[9]),
+			TxErrs:    parseUint(f[10]),
+			TxDrop:    parseUint(f[11]),
+		})
+	}
+	return out, nil
+}
+
+// NetTotals sums byte counters over all interfaces except loopback.
+func NetTotals(devs []NetDev) (rx, tx uint64) {
+	for _, d := range devs {
+		if d.Name == "lo" {
+			continue
+		}
+		rx += d.RxBytes
+		tx += d.TxBytes
+	}
+	return rx, tx
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PSILine is one line of a /proc/pressure file: the share of wall time in
+// which tasks stalled on the resource, averaged over 10s, 60s and 300s.
+type PSILine struct {
+	Avg10  float64 `json:"avg10" yaml:"avg10"`
+	Avg60  float64 `json:"avg60" yaml:"avg60"`
+	Avg300 float64 `json:"avg300" yaml:"avg300"`
+	Total  uint64  `json:"total_us" yaml:"total_us"` // cumulative stall time in microseconds
+}
+
+// Pressure is the pressure-stall information of one resource. Some counts
+// time in which at least one task stalled, Full time in which all non-idle
+// tasks did; the kernel reports Full as zero for cpu.
+type Pressure struct {
+	Some PSILine `json:"some" yaml:"some"`
+	Full PSILine `json:"full" yaml:"full"`
+}
+
+// Pressure parses /proc/pressure/<resource> (cpu, memory or io). It fails
+// on kernels without CONFIG_PSI or when PSI is disabled at boot.
+func (fs FS) Pressure(resource string) (Pressure, error) {
+	lines, err := fs.readLines("pressure", resource)
+	if err != nil {
+		return Pressure{}, err
+	}
+	var p Pressure
+	for _, line := range lines {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		var dst *PSILine
+		switch f[0] {
+		case "some":
+			dst = &p.Some
+		case "full":
+			dst = &p.Full
+		default:
+			return p, fmt.Errorf("%s: unexpected line %q", fs.path("pressure", resource), line)
+		}
+		for _, kv := range f[1:] {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "avg10":
+				dst.Avg10, _ = strconv.ParseFloat(v, 64)
+			case "avg60":
+				dst.Avg60, _ = strconv.ParseFloat(v, 64)
+			case "avg300":
+				dst.Avg300, _ = strconv.ParseFloat(v, 64)
+			case "total":
+				dst.Total = parseUint(v)
+			}
+		}
+	}
+	return p, nil
+}
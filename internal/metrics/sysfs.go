@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SysFS reads device attributes from a sysfs tree.
+type SysFS struct {
+	Root string
+}
+
+// DefaultSys reads from the live /sys.
+var DefaultSys = SysFS{Root: "/sys"}
+
+// NewSysFS returns a SysFS rooted at root; an empty root means /sys.
+func NewSysFS(root string) SysFS {
+	if root == "" {
+		root = "/sys"
+	}
+	return SysFS{Root: root}
+}
+
+func (s SysFS) path(elem ...string) string {
+	return filepath.Join(append([]string{s.Root}, elem...)...)
+}
+
+// readString returns a trimmed attribute, or "" if it cannot be read.
+func (s SysFS) readString(elem ...string) string {
+	b, err := os.ReadFile(s.path(elem...))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func (s SysFS) readUint(elem ...string) (uint64, bool) {
+	v := s.readString(elem...)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	return n, err == nil
+}
+
+// CPUCore describes one logical CPU from /sys/devices/system/cpu/cpuN.
+// Fields whose files are missing (no cpufreq driver, no thermal_throttle
+// on virtual machines and non-x86) stay zero.
+type CPUCore struct {
+	CPU     int    `json:"cpu" yaml:"cpu"`
+	Online  bool   `json:"online" yaml:"online"`
+	Package int    `json:"package" yaml:"package"`
+	Core    int    `json:"core" yaml:"core"`
+	Threads string `json:"thread_siblings,omitempty" yaml:"thread_siblings,omitempty"` // cpu list, e.g. "0,4"
+
+	CurKHz   uint64 `json:"cur_khz,omitempty" yaml:"cur_khz,omitempty"`
+	MinKHz   uint64 `json:"min_khz,omitempty" yaml:"min_khz,omitempty"`
+	MaxKHz   uint64 `json:"max_khz,omitempty" yaml:"max_khz,omitempty"`
+	Governor string `json:"governor,omitempty" yaml:"governor,omitempty"`
+	Driver   string `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	CoreThrottles    uint64 `json:"core_throttles" yaml:"core_throttles"`
+	PackageThrottles uint64 `json:"package_throttles" yaml:"package_throttles"`
+}
+
+// CPUCores reads every cpuN directory, ordered by CPU number.
+func (s SysFS) CPUCores() ([]CPUCore, error) {
+	base := s.path("devices", "system", "cpu")
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil, err
+	}
+	var out []CPUCore
+	for _, e := range entries {
+		n, err := strconv.Atoi(strings.TrimPrefix(e.Name(), "cpu"))
+		if err != nil || !strings.HasPrefix(e.Name(), "cpu") {
+			continue
+		}
+		dir := []string{"devices", "system", "cpu", e.Name()}
+		at := func(elem ...string) []string { return append(append([]string{}, dir...), elem...) }
+		c := CPUCore{CPU: n, Online: true}
+		// cpu0 usually has no online file because it cannot be unplugged
+		if v := s.readString(at("online")...); v == "0" {
+			c.Online = false
+		}
+		pkg, _ := s.readUint(at("topology", "physical_package_id")...)
+		core, _ := s.readUint(at("topology", "core_id")...)
+		c.Package, c.Core = int(pkg), int(core)
+		c.Threads = s.readString(at("topology", "thread_siblings_list")...)
+		c.CurKHz, _ = s.readUint(at("cpufreq", "scaling_cur_freq")...)
+		c.MinKHz, _ = s.readUint(at("cpufreq", "scaling_min_freq")...)
+		c.MaxKHz, _ = s.readUint(at("cpufreq", "scaling_max_freq")...)
+		c.Governor = s.readString(at("cpufreq", "scaling_governor")...)
+		c.Driver = s.readString(at("cpufreq", "scaling_driver")...)
+		c.CoreThrottles, _ = s.readUint(at("thermal_throttle", "core_throttle_count")...)
+		c.PackageThrottles, _ = s.readUint(at("thermal_throttle", "package_throttle_count")...)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CPU < out[j].CPU })
+	return out, nil
+}
+
+// Topology counts sockets, physical cores and hardware threads among the
+// online CPUs.
+func Topology(cores []CPUCore) (sockets, physical, threads int) {
+	pkgs := map[int]bool{}
+	phys := map[[2]int]bool{}
+	for _, c := range cores {
+		if !c.Online {
+			continue
+		}
+		pkgs[c.Package] = true
+		phys[[2]int{c.Package, c.Core}] = true
+		threads++
+	}
+	return len(pkgs), len(phys), threads
+}
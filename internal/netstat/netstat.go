@@ -0,0 +1,242 @@
+// Package netstat reads the kernel socket tables from /proc/net and maps
+// sockets to the processes holding them.
+package netstat
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Socket is one row of a /proc/net socket table.
+type Socket struct {
+	Proto      string `json:"proto" yaml:"proto"`           // tcp, tcp6, udp, udp6 or unix
+	LocalAddr  string `json:"local_addr" yaml:"local_addr"` // IP address, or the path of a unix socket
+	LocalPort  int    `json:"local_port" yaml:"local_port"`
+	RemoteAddr string `json:"remote_addr,omitempty" yaml:"remote_addr,omitempty"`
+	RemotePort int    `json:"remote_port,omitempty" yaml:"remote_port,omitempty"`
+	State      string `json:"state" yaml:"state"` // LISTEN, ESTABLISHED, …; UNCONN for unbound/unconnected
+	UID        int    `json:"uid" yaml:"uid"`     // -1 for unix sockets, whose table has no owner
+	Inode      uint64 `json:"inode" yaml:"inode"`
+	RxQueue    uint64 `json:"rx_queue" yaml:"rx_queue"`
+	TxQueue    uint64 `json:"tx_queue" yaml:"tx_queue"`
+}
+
+// Local formats the local endpoint like ss does, e.g. "[::1]:631".
+func (s Socket) Local() string { return endpoint(s.Proto, s.LocalAddr, s.LocalPort) }
+
+// Remote formats the remote endpoint; "*" stands for an unset port.
+func (s Socket) Remote() string { return endpoint(s.Proto, s.RemoteAddr, s.RemotePort) }
+
+func endpoint(proto, addr string, port int) string {
+	if proto == "unix" {
+		if addr == "" {
+			return "*"
+		}
+		return addr
+	}
+	p := "*"
+	if port != 0 {
+		p = strconv.Itoa(port)
+	}
+	if strings.Contains(addr, ":") {
+		return "[" + addr + "]:" + p
+	}
+	return addr + ":" + p
+}
+
+// Protos lists the tables Sockets can read.
+var Protos = []string{"tcp", "tcp6", "udp", "udp6", "unix"}
+
+// FS reads socket tables from a procfs tree.
+type FS struct {
+	Root string
+}
+
+// Default reads from the live /proc.
+var Default = FS{Root: "/proc"}
+
+func (fs FS) path(elem ...string) string {
+	return filepath.Join(append([]string{fs.Root}, elem...)...)
+}
+
+// Sockets reads the given tables, or all of Protos when none are given.
+// Tables missing from the kernel (e.g. tcp6 with IPv6 disabled) are skipped.
+func (fs FS) Sockets(protos ...string) ([]Socket, error) {
+	if len(protos) == 0 {
+		protos = Protos
+	}
+	var out []Socket
+	for _, proto := range protos {
+		b, err := os.ReadFile(fs.path("net", proto))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+		for _, line := range lines[1:] {
+			var s Socket
+			var err error
+			if proto == "unix" {
+				s, err = parseUnix(line)
+			} else {
+				s, err = parseInet(proto, line)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fs.path("net", proto), err)
+			}
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED", "02": "SYN_SENT", "03": "SYN_RECV", "04": "FIN_WAIT1",
+	"05": "FIN_WAIT2", "06": "TIME_WAIT", "07": "CLOSE", "08": "CLOSE_WAIT",
+	"09": "LAST_ACK", "0A": "LISTEN", "0B": "CLOSING", "0C": "NEW_SYN_RECV",
+}
+
+// parseInet parses a line of /proc/net/{tcp,tcp6,udp,udp6}:
+//
+//	sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode …
+func parseInet(proto, line string) (Socket, error) {
+	f := strings.Fields(line)
+	if len(f) < 10 {
+		return Socket{}, fmt.Errorf("short line %q", line)
+	}
+	s := Socket{Proto: proto}
+	var err error
+	if s.LocalAddr, s.LocalPort, err = parseHexAddr(f[1]); err != nil {
+		return s, err
+	}
+	if s.RemoteAddr, s.RemotePort, err = parseHexAddr(f[2]); err != nil {
+		return s, err
+	}
+	s.State = tcpStates[f[3]]
+	if strings.HasPrefix(proto, "udp") {
+		// UDP reuses the TCP codes: 01 for connected sockets, 07 otherwise
+		if f[3] == "01" {
+			s.State = "ESTABLISHED"
+		} else {
+			s.State = "UNCONN"
+		}
+	}
+	if tx, rx, ok := strings.Cut(f[4], ":"); ok {
+		s.TxQueue, _ = strconv.ParseUint(tx, 16, 64)
+		s.RxQueue, _ = strconv.ParseUint(rx, 16, 64)
+	}
+	s.UID, _ = strconv.Atoi(f[7])
+	s.Inode, _ = strconv.ParseUint(f[9], 10, 64)
+	return s, nil
+}
+
+// parseHexAddr decodes "0100007F:0277". The address is in network order
+// within each 32-bit word but the words are printed in host order, which
+// is little-endian on every platform Linux usually runs on.
+func parseHexAddr(s string) (string, int, error) {
+	a, p, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("bad address %q", s)
+	}
+	raw, err := hex.DecodeString(a)
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, fmt.Errorf("bad address %q", s)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	port, err := strconv.ParseUint(p, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("bad port in %q", s)
+	}
+	return ip.String(), int(port), nil
+}
+
+// __SO_ACCEPTCON in the Flags column marks a listening unix socket.
+const unixAcceptCon = 0x10000
+
+// parseUnix parses a line of /proc/net/unix:
+//
+//	Num RefCount Protocol Flags Type St Inode [Path]
+func parseUnix(line string) (Socket, error) {
+	f := strings.Fields(line)
+	if len(f) < 7 {
+		return Socket{}, fmt.Errorf("short line %q", line)
+	}
+	s := Socket{Proto: "unix", UID: -1}
+	flags, _ := strconv.ParseUint(f[3], 16, 32)
+	switch {
+	case flags&unixAcceptCon != 0:
+		s.State = "LISTEN"
+	case f[5] == "03":
+		s.State = "ESTABLISHED"
+	default:
+		s.State = "UNCONN"
+	}
+	s.Inode, _ = strconv.ParseUint(f[6], 10, 64)
+	if len(f) > 7 {
+		s.LocalAddr = strings.Join(f[7:], " ")
+	}
+	return s, nil
+}
+
+// Owner is a process holding a socket open.
+type Owner struct {
+	PID  int
+	Name string
+}
+
+// Owners maps socket inodes to the processes that hold them by scanning
+// /proc/*/fd. Without root only the caller's own processes are visible.
+// A socket shared by several processes maps to the lowest PID.
+func (fs FS) Owners() (map[uint64]Owner, error) {
+	entries, err := os.ReadDir(fs.Root)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	out := map[uint64]Owner{}
+	for _, pid := range pids {
+		dir := fs.path(strconv.Itoa(pid), "fd")
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		var name string
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(dir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			if _, seen := out[inode]; seen {
+				continue
+			}
+			if name == "" {
+				b, _ := os.ReadFile(fs.path(strconv.Itoa(pid), "comm"))
+				name = strings.TrimSpace(string(b))
+			}
+			out[inode] = Owner{PID: pid, Name: name}
+		}
+	}
+	return out, nil
+}
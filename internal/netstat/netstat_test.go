@@ -0,0 +1,184 @@
+package netstat
+
+import (
+	"reflect"
+	"testing"
+)
+
+var fixture = FS{Root: "testdata/proc"}
+
+func TestSockets(t *testing.T) {
+	socks, err := fixture.Sockets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// udp6 is missing from the fixture, as on a kernel without IPv6
+	want := []Socket{
+		{Proto: "tcp", LocalAddr: "127.0.0.1", LocalPort: 631, RemoteAddr: "0.0.0.0", State: "LISTEN", Inode: 1001},
+		{Proto: "tcp", LocalAddr: "0.0.0.0", LocalPort: 80, RemoteAddr: "0.0.0.0", State: "LISTEN", UID: 33, Inode: 1003, RxQueue: 5},
+		{Proto: "tcp", LocalAddr: "10.0.2.15", LocalPort: 22, RemoteAddr: "192.0.2.7", RemotePort: 51234, State: "ESTABLISHED", UID: 1000, Inode: 1002, TxQueue: 0x24},
+		{Proto: "tcp", LocalAddr: "10.0.2.15", LocalPort: 80, RemoteAddr: "192.0.2.7", RemotePort: 51235, State: "TIME_WAIT"},
+		{Proto: "tcp6", LocalAddr: "::1", LocalPort: 631, RemoteAddr: "::", State: "LISTEN", Inode: 1004},
+		// a v4-mapped peer prints in dotted form
+		{Proto: "tcp6", LocalAddr: "2001:db8::1", LocalPort: 443, RemoteAddr: "192.0.2.9", RemotePort: 50000, State: "ESTABLISHED", UID: 1000, Inode: 1005},
+		{Proto: "udp", LocalAddr: "0.0.0.0", LocalPort: 68, RemoteAddr: "0.0.0.0", State: "UNCONN", Inode: 1006},
+		{Proto: "udp", LocalAddr: "10.0.2.15", LocalPort: 41394, RemoteAddr: "8.8.8.8", RemotePort: 53, State: "ESTABLISHED", UID: 1000, Inode: 1007},
+		{Proto: "udp", LocalAddr: "0.0.0.0", RemoteAddr: "0.0.0.0", State: "UNCONN", UID: 1000, Inode: 1008},
+		{Proto: "unix", LocalAddr: "/run/systemd/notify", State: "LISTEN", UID: -1, Inode: 2001},
+		{Proto: "unix", State: "ESTABLISHED", UID: -1, Inode: 2002},
+		{Proto: "unix", LocalAddr: "@abstract name", State: "UNCONN", UID: -1, Inode: 2003},
+	}
+	if len(socks) != len(want) {
+		t.Fatalf("got %d sockets, want %d: %+v", len(socks), len(want), socks)
+	}
+	for i := range want {
+		if socks[i] != want[i] {
+			t.Errorf("socket %d:\n got %+v\nwant %+v", i, socks[i], want[i])
+		}
+	}
+
+	if socks, err := fixture.Sockets("unix"); err != nil || len(socks) != 3 {
+		t.Errorf("Sockets(unix) = %d sockets, %v; want 3", len(socks), err)
+	}
+	if _, err := (FS{Root: "testdata/none"}).Sockets(); err != nil {
+		t.Errorf("missing tables: %v", err)
+	}
+}
+
+func TestParseHexAddr(t *testing.T) {
+	tests := []struct {
+		in   string
+		addr string
+		port int
+		err  bool
+	}{
+		{"0100007F:0277", "127.0.0.1", 631, false},
+		{"00000000:0000", "0.0.0.0", 0, false},
+		{"00000000000000000000000001000000:0016", "::1", 22, false},
+		{"B80D0120000000000000000001000000:01BB", "2001:db8::1", 443, false},
+		{"0000000000000000FFFF0000090200C0:C350", "192.0.2.9", 50000, false},
+		{"0100007F", "", 0, true},
+		{"0100007F00:0277", "", 0, true},
+		{"XX00007F:0277", "", 0, true},
+		{"0100007F:10000", "", 0, true},
+	}
+	for _, tt := range tests {
+		addr, port, err := parseHexAddr(tt.in)
+		if (err != nil) != tt.err || addr != tt.addr || port != tt.port {
+			t.Errorf("parseHexAddr(%q) = %q, %d, %v; want %q, %d", tt.in, addr, port, err, tt.addr, tt.port)
+		}
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	tests := []struct {
+		s             Socket
+		local, remote string
+	}{
+		{Socket{Proto: "tcp", LocalAddr: "127.0.0.1", LocalPort: 631, RemoteAddr: "0.0.0.0"}, "127.0.0.1:631", "0.0.0.0:*"},
+		{Socket{Proto: "tcp6", LocalAddr: "::1", LocalPort: 631, RemoteAddr: "::"}, "[::1]:631", "[::]:*"},
+		{Socket{Proto: "unix", LocalAddr: "/run/x.sock"}, "/run/x.sock", "*"},
+		{Socket{Proto: "unix"}, "*", "*"},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Local(); got != tt.local {
+			t.Errorf("%+v Local() = %q, want %q", tt.s, got, tt.local)
+		}
+		if got := tt.s.Remote(); got != tt.remote {
+			t.Errorf("%+v Remote() = %q, want %q", tt.s, got, tt.remote)
+		}
+	}
+}
+
+func TestOwners(t *testing.T) {
+	owners, err := fixture.Owners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1002 is shared by pids 99 and 1234; the lower one wins
+	want := map[uint64]Owner{
+		1001: {PID: 1234, Name: "cupsd"},
+		1002: {PID: 99, Name: "sshd"},
+	}
+	if !reflect.DeepEqual(owners, want) {
+		t.Errorf("Owners() = %+v, want %+v", owners, want)
+	}
+}
+
+func TestFilterTables(t *testing.T) {
+	tests := []struct {
+		protos []string
+		want   []string
+	}{
+		{nil, []string{"tcp", "tcp6", "udp", "udp6"}},
+		{[]string{"TCP"}, []string{"tcp", "tcp6"}},
+		{[]string{"tcp4", "udp6"}, []string{"tcp", "udp6"}},
+		{[]string{"tcp", "tcp6", "unix"}, []string{"tcp", "tcp6", "unix"}},
+	}
+	for _, tt := range tests {
+		if got := (Filter{Protos: tt.protos}).tables(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tables(%v) = %v, want %v", tt.protos, got, tt.want)
+		}
+	}
+	if err := (Filter{Protos: []string{"sctp"}}).Validate(); err == nil {
+		t.Error("Validate accepted sctp")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	socks, err := fixture.Sockets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := make([]Entry, len(socks))
+	for i, s := range socks {
+		entries[i] = Entry{Socket: s}
+	}
+	entries[2].PID = 99
+
+	tests := []struct {
+		name   string
+		f      Filter
+		inodes []uint64
+	}{
+		// listening TCP, bound UDP and bound unix sockets, but not the
+		// unbound UDP socket on port 0
+		{"default", Filter{}, []uint64{1001, 1003, 1004, 1006, 2001, 2003}},
+		{"all", Filter{All: true}, []uint64{1001, 1003, 1002, 0, 1004, 1005, 1006, 1007, 1008, 2001, 2002, 2003}},
+		{"states", Filter{States: []string{"established", "TIME_WAIT"}}, []uint64{1002, 0, 1005, 1007, 2002}},
+		{"port", Filter{Port: 80, All: true}, []uint64{1003, 0}},
+		{"remote port", Filter{Port: 53, All: true}, []uint64{1007}},
+		{"pid", Filter{PID: 99, All: true}, []uint64{1002}},
+		{"pid and state", Filter{PID: 99, States: []string{"LISTEN"}}, nil},
+	}
+	for _, tt := range tests {
+		var got []uint64
+		for _, e := range entries {
+			if tt.f.match(e) {
+				got = append(got, e.Inode)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.inodes) {
+			t.Errorf("%s: matched %v, want %v", tt.name, got, tt.inodes)
+		}
+	}
+}
+
+func TestRemotes(t *testing.T) {
+	entries := []Entry{
+		{Socket: Socket{Proto: "tcp", RemoteAddr: "192.0.2.7", LocalPort: 443}, Process: "nginx"},
+		{Socket: Socket{Proto: "tcp", RemoteAddr: "192.0.2.7", LocalPort: 22}, Process: "sshd"},
+		{Socket: Socket{Proto: "tcp", RemoteAddr: "192.0.2.7", LocalPort: 443}, Process: "nginx"},
+		{Socket: Socket{Proto: "tcp6", RemoteAddr: "2001:db8::2", LocalPort: 443}},
+		{Socket: Socket{Proto: "udp", RemoteAddr: "8.8.8.8", LocalPort: 41394}, Process: "resolved"},
+		{Socket: Socket{Proto: "unix", LocalAddr: "/run/x.sock"}, Process: "dbus"},
+	}
+	want := []RemoteCount{
+		{Remote: "192.0.2.7", Count: 3, Ports: []int{22, 443}, Processes: []string{"nginx", "sshd"}},
+		{Remote: "2001:db8::2", Count: 1, Ports: []int{443}},
+		{Remote: "8.8.8.8", Count: 1, Ports: []int{41394}, Processes: []string{"resolved"}},
+	}
+	if got := Remotes(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remotes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
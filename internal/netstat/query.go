@@ -0,0 +1,195 @@
+package netstat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"syskit/internal/container"
+	"syskit/internal/procs"
+)
+
+// Entry is a socket together with the process holding it. PID is zero
+// when the owner is not visible (another user's process without root).
+type Entry struct {
+	Socket    `yaml:",inline"`
+	PID       int    `json:"pid,omitempty" yaml:"pid,omitempty"`
+	Process   string `json:"process,omitempty" yaml:"process,omitempty"`
+	User      string `json:"user,omitempty" yaml:"user,omitempty"`
+	Container string `json:"container,omitempty" yaml:"container,omitempty"`
+}
+
+// Filter selects sockets. Zero fields match everything, except that
+// without All or States only listening sockets are shown, like ss -l.
+type Filter struct {
+	// Protos holds tcp, udp or unix; a 4 or 6 suffix (tcp6, udp4)
+	// restricts the address family. Empty means tcp and udp.
+	Protos []string
+	States []string // e.g. LISTEN, ESTABLISHED, TIME_WAIT (case-insensitive)
+	Port   int      // local or remote port
+	PID    int
+	All    bool
+}
+
+// Validate reports unknown protocols.
+func (f Filter) Validate() error {
+	for _, p := range f.Protos {
+		switch strings.ToLower(p) {
+		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix":
+		default:
+			return fmt.Errorf("unknown protocol %q (tcp, tcp4, tcp6, udp, udp4, udp6, unix)", p)
+		}
+	}
+	return nil
+}
+
+// tables returns the /proc/net files the protocols need.
+func (f Filter) tables() []string {
+	protos := f.Protos
+	if len(protos) == 0 {
+		protos = []string{"tcp", "udp"}
+	}
+	seen := map[string]bool{}
+	var out []string
+	add := func(t string) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	for _, p := range protos {
+		switch p = strings.ToLower(p); p {
+		case "tcp", "udp":
+			add(p)
+			add(p + "6")
+		case "tcp4", "udp4":
+			add(p[:3])
+		default:
+			add(p)
+		}
+	}
+	return out
+}
+
+func (f Filter) match(e Entry) bool {
+	if f.Port != 0 && e.LocalPort != f.Port && e.RemotePort != f.Port {
+		return false
+	}
+	if f.PID != 0 && e.PID != f.PID {
+		return false
+	}
+	if len(f.States) > 0 {
+		for _, st := range f.States {
+			if strings.EqualFold(st, e.State) {
+				return true
+			}
+		}
+		return false
+	}
+	if f.All {
+		return true
+	}
+	// bound UDP and unix sockets have no LISTEN state; an unbound UDP
+	// socket still reports 0.0.0.0, so only its port tells it apart
+	if e.State != "UNCONN" {
+		return e.State == "LISTEN"
+	}
+	if e.Proto == "unix" {
+		return e.LocalAddr != ""
+	}
+	return e.LocalPort != 0
+}
+
+// List returns the sockets matching f with their owners, ordered by
+// protocol and local port.
+func List(f Filter) ([]Entry, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	socks, err := Default.Sockets(f.tables()...)
+	if err != nil {
+		return nil, err
+	}
+	owners, err := Default.Owners()
+	if err != nil {
+		return nil, err
+	}
+	containers := map[int]string{}
+	var out []Entry
+	for _, s := range socks {
+		e := Entry{Socket: s}
+		if o, ok := owners[s.Inode]; ok && s.Inode != 0 {
+			e.PID, e.Process = o.PID, o.Name
+			if _, ok := containers[o.PID]; !ok {
+				containers[o.PID] = container.ForPID(o.PID)
+			}
+			e.Container = containers[o.PID]
+		}
+		if s.UID >= 0 {
+			e.User = procs.UserName(s.UID)
+		}
+		if f.match(e) {
+			out = append(out, e)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Proto != out[j].Proto {
+			return out[i].Proto < out[j].Proto
+		}
+		if out[i].LocalPort != out[j].LocalPort {
+			return out[i].LocalPort < out[j].LocalPort
+		}
+		return out[i].LocalAddr < out[j].LocalAddr
+	})
+	return out, nil
+}
+
+// RemoteCount aggregates the connections to one remote address.
+type RemoteCount struct {
+	Remote    string   `json:"remote" yaml:"remote"`
+	Count     int      `json:"count" yaml:"count"`
+	Ports     []int    `json:"local_ports" yaml:"local_ports"`
+	Processes []string `json:"processes" yaml:"processes"`
+}
+
+// Remotes groups entries by remote address, busiest first.
+func Remotes(entries []Entry) []RemoteCount {
+	byAddr := map[string]*RemoteCount{}
+	for _, e := range entries {
+		if e.Proto == "unix" {
+			continue
+		}
+		rc := byAddr[e.RemoteAddr]
+		if rc == nil {
+			rc = &RemoteCount{Remote: e.RemoteAddr}
+			byAddr[e.RemoteAddr] = rc
+		}
+		rc.Count++
+		rc.Ports = appendUnique(rc.Ports, e.LocalPort)
+		if e.Process != "" {
+			rc.Processes = appendUnique(rc.Processes, e.Process)
+		}
+	}
+	out := make([]RemoteCount, 0, len(byAddr))
+	for _, rc := range byAddr {
+		sort.Ints(rc.Ports)
+		sort.Strings(rc.Processes)
+		out = append(out, *rc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Remote < out[j].Remote
+	})
+	return out
+}
+
+func appendUnique[T comparable](list []T, v T) []T {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Exec runs a local command for every message. The message body is written
+// to stdin and the fields are exported as SYSKIT_* environment variables.
+type Exec struct {
+	ID      string
+	Command []string
+}
+
+func (e *Exec) Name() string { return e.ID }
+
+// Notify runs the command and fails if it exits non-zero.
+func (e *Exec) Notify(ctx context.Context, m Message) error {
+	cmd := exec.CommandContext(ctx, e.Command[0], e.Command[1:]...)
+	cmd.Stdin = strings.NewReader(m.Body)
+	cmd.Env = append(os.Environ(),
+		"SYSKIT_SUBJECT="+m.Subject,
+		"SYSKIT_SEVERITY="+m.Severity,
+		"SYSKIT_SOURCE="+m.Source,
+		"SYSKIT_HOST="+m.Host,
+		"SYSKIT_TIME="+m.Time.Format(time.RFC3339),
+	)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package notify delivers alert messages to the channels configured under
+// "notify" in config.yaml: SMTP, generic JSON webhooks, Slack/Mattermost
+// incoming webhooks, syslog and local exec hooks.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"syskit/internal/config"
+)
+
+// Severity levels carried by a Message.
+const (
+	Info     = "info"
+	Warning  = "warning"
+	Critical = "critical"
+)
+
+// Message is one notification.
+type Message struct {
+	Subject  string    `json:"subject"`
+	Body     string    `json:"body"`
+	Severity string    `json:"severity"`
+	Source   string    `json:"source"` // emitting command, e.g. "process-watch"
+	Host     string    `json:"host"`
+	Time     time.Time `json:"time"`
+}
+
+// Notifier delivers messages to one channel.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, m Message) error
+}
+
+// DefaultTimeout bounds a single delivery.
+const DefaultTimeout = 15 * time.Second
+
+// FromConfig builds notifiers for the configured channels. When no channels
+// are configured but smtp.host is set, a single implicit "email" channel is
+// returned so existing configs keep working.
+func FromConfig(cfg *config.Config) ([]Notifier, error) {
+	if len(cfg.Notify) == 0 {
+		if cfg.SMTP.Host == "" {
+			return nil, nil
+		}
+		return []Notifier{&SMTP{ID: "email", Server: smtpConfig(cfg, "")}}, nil
+	}
+	var out []Notifier
+	seen := map[string]bool{}
+	for i, ch := range cfg.Notify {
+		name := ch.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", ch.Type, i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("notify: duplicate channel name %q", name)
+		}
+		seen[name] = true
+		n, err := build(name, ch, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("notify channel %s: %w", name, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func build(name string, ch config.Channel, cfg *config.Config) (Notifier, error) {
+	switch ch.Type {
+	case "smtp", "email":
+		// unset fields fall back to the top-level smtp block
+		s := &SMTP{ID: name, Server: smtpConfig(cfg, ch.To)}
+		if s.Server.Host == "" || len(s.Server.To) == 0 {
+			return nil, errors.New("smtp host and recipient are required")
+		}
+		return s, nil
+	case "webhook":
+		if ch.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		return &Webhook{ID: name, URL: ch.URL, Headers: ch.Headers}, nil
+	case "slack", "mattermost":
+		if ch.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		return &Slack{ID: name, URL: ch.URL, Channel: ch.Channel, Username: ch.Username}, nil
+	case "syslog":
+		return &Syslog{ID: name, Network: ch.Network, Addr: ch.Addr, Tag: ch.Tag}, nil
+	case "exec":
+		if len(ch.Command) == 0 {
+			return nil, errors.New("command is required")
+		}
+		return &Exec{ID: name, Command: ch.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", ch.Type)
+	}
+}
+
+// Select returns the notifiers whose names are listed; an empty list selects
+// all of them.
+func Select(ns []Notifier, names []string) ([]Notifier, error) {
+	if len(names) == 0 {
+		return ns, nil
+	}
+	byName := map[string]Notifier{}
+	for _, n := range ns {
+		byName[n.Name()] = n
+	}
+	var out []Notifier
+	for _, name := range names {
+		n, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("notify: unknown channel %q", name)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// Send fills in Host and Time when unset and delivers m to every notifier,
+// continuing past failures. The returned error joins all failures.
+func Send(ctx context.Context, ns []Notifier, m Message) error {
+	if m.Host == "" {
+		m.Host, _ = os.Hostname()
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	if m.Severity == "" {
+		m.Severity = Warning
+	}
+	var errs []error
+	for _, n := range ns {
+		cctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+		if err := n.Notify(cctx, m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// built caches the notifiers of the last config passed to Dispatch. Every
+// config load yields a new *config.Config, so a reload rebuilds them.
+var built struct {
+	sync.Mutex
+	cfg *config.Config
+	ns  []Notifier
+	err error
+}
+
+func cached(cfg *config.Config) ([]Notifier, error) {
+	built.Lock()
+	defer built.Unlock()
+	if built.cfg != cfg {
+		built.ns, built.err = FromConfig(cfg)
+		built.cfg = cfg
+	}
+	return built.ns, built.err
+}
+
+// Dispatch sends m to the configured channels named in channels, or to all
+// of them when channels is empty. The channels are built once per config.
+func Dispatch(cfg *config.Config, channels []string, m Message) error {
+	ns, err := cached(cfg)
+	if err != nil {
+		return err
+	}
+	if ns, err = Select(ns, channels); err != nil {
+		return err
+	}
+	return Send(context.Background(), ns, m)
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"syskit/internal/config"
+)
+
+var testMsg = Message{
+	Subject:  "disk full",
+	Body:     "/var at 97%",
+	Severity: Critical,
+	Source:   "thresholds",
+	Host:     "web1",
+	Time:     time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+}
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// recorder serves status and records every request it receives.
+func recorder(t *testing.T, status int) (*httptest.Server, *[]request) {
+	t.Helper()
+	var reqs []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		body, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, request{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+func buildOne(t *testing.T, ch config.Channel) Notifier {
+	t.Helper()
+	cfg := &config.Config{Notify: []config.Channel{ch}}
+	ns, err := FromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ns[0]
+}
+
+func TestWebhook(t *testing.T) {
+	srv, reqs := recorder(t, http.StatusNoContent)
+	n := buildOne(t, config.Channel{Name: "hook", Type: "webhook", URL: srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer secret", "X-Team": "ops"}})
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*reqs))
+	}
+	r := (*reqs)[0]
+	for k, want := range map[string]string{
+		"Content-Type":  "application/json",
+		"User-Agent":    "syskit",
+		"Authorization": "Bearer secret",
+		"X-Team":        "ops",
+	} {
+		if got := r.header.Get(k); got != want {
+			t.Errorf("header %s = %q, want %q", k, got, want)
+		}
+	}
+	var got Message
+	if err := json.Unmarshal(r.body, &got); err != nil {
+		t.Fatalf("payload %s: %v", r.body, err)
+	}
+	if got != testMsg {
+		t.Errorf("payload = %+v, want %+v", got, testMsg)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	for _, typ := range []string{"slack", "mattermost"} {
+		srv, reqs := recorder(t, http.StatusOK)
+		n := buildOne(t, config.Channel{Type: typ, URL: srv.URL, Channel: "#alerts", Username: "syskit"})
+		if err := n.Notify(context.Background(), testMsg); err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		var got map[string]string
+		if err := json.Unmarshal((*reqs)[0].body, &got); err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		want := map[string]string{
+			"text":     "*disk full* (web1)\n```\n/var at 97%\n```",
+			"channel":  "#alerts",
+			"username": "syskit",
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: payload = %v, want %v", typ, got, want)
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %q, want %q", typ, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		typ    string
+		status int
+	}{
+		{"webhook", http.StatusInternalServerError},
+		{"webhook", http.StatusMovedPermanently},
+		{"slack", http.StatusForbidden},
+		{"mattermost", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		srv, _ := recorder(t, tt.status)
+		n := buildOne(t, config.Channel{Type: tt.typ, URL: srv.URL})
+		err := n.Notify(context.Background(), testMsg)
+		if err == nil || !strings.Contains(err.Error(), http.StatusText(tt.status)) {
+			t.Errorf("%s %d: err = %v", tt.typ, tt.status, err)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	script := `{ echo "$SYSKIT_SUBJECT|$SYSKIT_SEVERITY|$SYSKIT_SOURCE|$SYSKIT_HOST|$SYSKIT_TIME"; cat; } > "$0"`
+	n := buildOne(t, config.Channel{Type: "exec", Command: []string{"sh", "-c", script, out}})
+	if err := n.Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "disk full|critical|thresholds|web1|2024-03-01T08:00:00Z\n/var at 97%"
+	if string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+
+	fail := buildOne(t, config.Channel{Type: "exec", Command: []string{"sh", "-c", "echo broken >&2; exit 3"}})
+	err = fail.Notify(context.Background(), testMsg)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("failing command: err = %v", err)
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	var withSMTP config.Config
+	withSMTP.SMTP.Host = "mail.example.com"
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "implicit email", cfg: withSMTP, want: []string{"email"}},
+		{name: "default names", cfg: config.Config{Notify: []config.Channel{
+			{Type: "syslog"}, {Type: "webhook", URL: "http://x"}}}, want: []string{"syslog-1", "webhook-2"}},
+		{name: "duplicate", cfg: config.Config{Notify: []config.Channel{
+			{Name: "a", Type: "syslog"}, {Name: "a", Type: "syslog"}}}, wantErr: true},
+		{name: "webhook without url", cfg: config.Config{Notify: []config.Channel{{Type: "webhook"}}}, wantErr: true},
+		{name: "unknown type", cfg: config.Config{Notify: []config.Channel{{Type: "pigeon"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		ns, err := FromConfig(&tt.cfg)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v", tt.name, err)
+			continue
+		}
+		var names []string
+		for _, n := range ns {
+			names = append(names, n.Name())
+		}
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	srv, reqs := recorder(t, http.StatusOK)
+	cfg := &config.Config{Notify: []config.Channel{
+		{Name: "a", Type: "webhook", URL: srv.URL},
+		{Name: "b", Type: "webhook", URL: srv.URL + "/b"},
+	}}
+	if err := Dispatch(cfg, []string{"b"}, Message{Subject: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*reqs))
+	}
+	var got Message
+	json.Unmarshal((*reqs)[0].body, &got)
+	if got.Severity != Warning || got.Host == "" || got.Time.IsZero() {
+		t.Errorf("defaults not filled in: %+v", got)
+	}
+	if err := Dispatch(cfg, []string{"c"}, Message{}); err == nil {
+		t.Error("unknown channel: expected error")
+	}
+
+	// notifiers are built once per config, not on every call
+	first, _ := cached(cfg)
+	cfg.Notify[0].URL = "http://changed"
+	if again, _ := cached(cfg); again[0] != first[0] {
+		t.Error("notifiers rebuilt for the same config")
+	}
+	reloaded := &config.Config{Notify: cfg.Notify}
+	if ns, _ := cached(reloaded); ns[0].(*Webhook).URL != "http://changed" {
+		t.Error("notifiers not rebuilt for a new config")
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"syskit/internal/config"
+	"syskit/internal/email"
+)
+
+// SMTP mails messages through an SMTP account.
+type SMTP struct {
+	ID     string
+	Server email.Config
+}
+
+func (s *SMTP) Name() string { return s.ID }
+
+// Notify sends m as a text+HTML mail. The context is not honoured by the
+// underlying SMTP client, which applies email.Timeout instead.
+func (s *SMTP) Notify(_ context.Context, m Message) error {
+	return s.Server.Send(email.Message{Subject: m.Subject, Text: m.Body, HTML: renderHTML(m)})
+}
+
+// smtpConfig converts the smtp block of config.yaml; to overrides the
+// recipients when non-empty.
+func smtpConfig(cfg *config.Config, to string) email.Config {
+	c := cfg.SMTP
+	if to == "" {
+		to = c.To
+	}
+	return email.Config{
+		Host: c.Host, Port: c.Port, Username: c.Username, Password: c.Password,
+		From: c.From, To: email.SplitAddrs(to), Security: c.Security,
+		CAFile: c.CAFile, InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+}
+
+var severityColor = map[string]string{Info: "#2e7d32", Warning: "#ef6c00", Critical: "#c62828"}
+
+func renderHTML(m Message) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><body style=\"font-family:sans-serif\">")
+	fmt.Fprintf(&b, "<h2 style=\"color:%s\">%s</h2>", severityColor[m.Severity], html.EscapeString(m.Subject))
+	if m.Body != "" {
+		fmt.Fprintf(&b, "<pre style=\"background:#f5f5f5;padding:8px\">%s</pre>", html.EscapeString(m.Body))
+	}
+	fmt.Fprintf(&b, "<p style=\"color:#777;font-size:small\">%s &middot; %s &middot; %s</p>",
+		html.EscapeString(m.Host), html.EscapeString(m.Source), m.Time.Format("2006-01-02 15:04:05 MST"))
+	b.WriteString("</body></html>")
+	return b.String()
+}
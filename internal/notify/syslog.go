@@ -0,0 +1,19 @@
+package notify
+
+// Syslog writes messages to the system logger, or to a remote one when
+// Network and Addr are set (e.g. "udp", "loghost:514").
+type Syslog struct {
+	ID      string
+	Network string
+	Addr    string
+	Tag     string // defaults to "syskit"
+}
+
+func (s *Syslog) Name() string { return s.ID }
+
+func (s *Syslog) tag() string {
+	if s.Tag == "" {
+		return "syskit"
+	}
+	return s.Tag
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Notify is unsupported: log/syslog is not available on this platform.
+func (s *Syslog) Notify(context.Context, Message) error {
+	return errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,30 @@
+//go:build !windows && !plan9
+
+package notify
+
+import (
+	"context"
+	"log/syslog"
+	"strings"
+)
+
+// Notify logs m with a priority derived from its severity.
+func (s *Syslog) Notify(_ context.Context, m Message) error {
+	w, err := syslog.Dial(s.Network, s.Addr, syslog.LOG_DAEMON|syslog.LOG_WARNING, s.tag())
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	line := m.Subject
+	if m.Body != "" {
+		line += ": " + strings.Join(strings.Fields(m.Body), " ")
+	}
+	switch m.Severity {
+	case Critical:
+		return w.Crit(line)
+	case Info:
+		return w.Info(line)
+	default:
+		return w.Warning(line)
+	}
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Webhook POSTs the message as a JSON object to URL.
+type Webhook struct {
+	ID      string
+	URL     string
+	Headers map[string]string
+	Client  *http.Client // nil uses http.DefaultClient
+}
+
+func (w *Webhook) Name() string { return w.ID }
+
+// Notify posts m encoded as JSON.
+func (w *Webhook) Notify(ctx context.Context, m Message) error {
+	return postJSON(ctx, w.Client, w.URL, w.Headers, m)
+}
+
+// Slack posts to a Slack or Mattermost incoming webhook.
+type Slack struct {
+	ID       string
+	URL      string
+	Channel  string // optional override of the webhook's default channel
+	Username string
+	Client   *http.Client
+}
+
+func (s *Slack) Name() string { return s.ID }
+
+type slackPayload struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// Notify posts m as a text message with a bold subject line.
+func (s *Slack) Notify(ctx context.Context, m Message) error {
+	text := "*" + m.Subject + "*"
+	if m.Host != "" {
+		text += " (" + m.Host + ")"
+	}
+	if m.Body != "" {
+		text += "\n```\n" + m.Body + "\n```"
+	}
+	return postJSON(ctx, s.Client, s.URL, nil, slackPayload{Text: text, Channel: s.Channel, Username: s.Username})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "syskit")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return checkStatus(resp)
+}
@@ -0,0 +1,84 @@
+//go:build linux
+
+package procs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// maxCPUs is the size of the affinity mask passed to the kernel.
+const maxCPUs = 1024
+
+type cpuMask [maxCPUs / 64]uint64
+
+// Signal sends sig to pid.
+func Signal(pid int, sig syscall.Signal) error {
+	return syscall.Kill(pid, sig)
+}
+
+// tids lists the threads of pid; nice values and affinity are per thread on
+// Linux, so changes must be applied to each of them.
+func (fs FS) tids(pid int) []int {
+	entries, err := os.ReadDir(fs.path(pid, "task"))
+	if err != nil {
+		return []int{pid}
+	}
+	var out []int
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			out = append(out, tid)
+		}
+	}
+	return out
+}
+
+// Renice sets the nice value of every thread of pid.
+func (fs FS) Renice(pid, nice int) error {
+	if nice < -20 || nice > 19 {
+		return fmt.Errorf("nice value %d out of range -20..19", nice)
+	}
+	for _, tid := range fs.tids(pid) {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+			return fmt.Errorf("tid %d: %w", tid, err)
+		}
+	}
+	return nil
+}
+
+// Affinity returns the CPUs pid may run on.
+func Affinity(pid int) ([]int, error) {
+	var mask cpuMask
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, uintptr(pid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return nil, errno
+	}
+	var cpus []int
+	for i := 0; i < maxCPUs; i++ {
+		if mask[i/64]&(1<<(uint(i)%64)) != 0 {
+			cpus = append(cpus, i)
+		}
+	}
+	return cpus, nil
+}
+
+// SetAffinity pins every thread of pid to cpus.
+func (fs FS) SetAffinity(pid int, cpus []int) error {
+	var mask cpuMask
+	for _, c := range cpus {
+		if c < 0 || c >= maxCPUs {
+			return fmt.Errorf("cpu %d out of range", c)
+		}
+		mask[c/64] |= 1 << (uint(c) % 64)
+	}
+	for _, tid := range fs.tids(pid) {
+		_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+		if errno != 0 {
+			return fmt.Errorf("tid %d: %w", tid, errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package procs
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+var errUnsupported = errors.New("not supported on this platform")
+
+// Signal sends sig to pid.
+func Signal(pid int, sig syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
+
+// Renice is only implemented on Linux.
+func (fs FS) Renice(pid, nice int) error { return errUnsupported }
+
+// Affinity is only implemented on Linux.
+func Affinity(pid int) ([]int, error) { return nil, errUnsupported }
+
+// SetAffinity is only implemented on Linux.
+func (fs FS) SetAffinity(pid int, cpus []int) error { return errUnsupported }
@@ -0,0 +1,82 @@
+package procs
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCPUList parses the kernel's cpu list syntax, e.g. "0-3,6,8-9". CPUs
+// the kernel could never bring online are rejected.
+func ParseCPUList(s string) ([]int, error) {
+	n := possibleCPUs()
+	seen := map[int]bool{}
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi := part, part
+		if i := strings.IndexByte(part, '-'); i >= 0 {
+			lo, hi = part[:i], part[i+1:]
+		}
+		a, err1 := strconv.Atoi(lo)
+		b, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || a < 0 || b < a {
+			return nil, fmt.Errorf("invalid cpu range %q", part)
+		}
+		if b >= n {
+			return nil, fmt.Errorf("cpu %d out of range 0-%d", b, n-1)
+		}
+		for c := a; c <= b; c++ {
+			seen[c] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("empty cpu list")
+	}
+	out := make([]int, 0, len(seen))
+	for c := range seen {
+		out = append(out, c)
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+// possibleCPUs is the number of CPU ids the kernel supports, from the last id
+// in /sys/devices/system/cpu/possible (e.g. "0-7"). Without sysfs it falls
+// back to the CPUs usable by this process.
+func possibleCPUs() int {
+	b, err := os.ReadFile("/sys/devices/system/cpu/possible")
+	if err == nil {
+		s := strings.TrimSpace(string(b))
+		if i := strings.LastIndexAny(s, ",-"); i >= 0 {
+			s = s[i+1:]
+		}
+		if last, err := strconv.Atoi(s); err == nil && last >= 0 {
+			return last + 1
+		}
+	}
+	return runtime.NumCPU()
+}
+
+// FormatCPUList is the inverse of ParseCPUList; cpus must be sorted.
+func FormatCPUList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,133 @@
+package procs
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FD is one open file descriptor.
+type FD struct {
+	Num    int
+	Target string // readlink of /proc/<pid>/fd/<n>, e.g. "socket:[1234]"
+}
+
+// OpenFiles lists the file descriptors of pid sorted by number. Reading
+// another user's descriptors requires privileges.
+func (fs FS) OpenFiles(pid int) ([]FD, error) {
+	dir := fs.path(pid, "fd")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []FD
+	for _, e := range entries {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		target, err := os.Readlink(fs.path(pid, "fd", e.Name()))
+		if err != nil {
+			continue
+		}
+		out = append(out, FD{Num: n, Target: target})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Num < out[j].Num })
+	return out, nil
+}
+
+// Environ returns the initial environment of pid as KEY=VALUE strings.
+func (fs FS) Environ(pid int) ([]string, error) {
+	b, err := os.ReadFile(fs.path(pid, "environ"))
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, kv := range strings.Split(string(b), "\x00") {
+		if kv != "" {
+			out = append(out, kv)
+		}
+	}
+	return out, nil
+}
+
+// Limit is one row of /proc/<pid>/limits.
+type Limit struct {
+	Name  string
+	Soft  string
+	Hard  string
+	Units string
+}
+
+// Limits parses /proc/<pid>/limits. The columns are fixed width, but the
+// values never contain spaces, so the last two or three fields are the
+// values and the rest is the name.
+func (fs FS) Limits(pid int) ([]Limit, error) {
+	b, err := os.ReadFile(fs.path(pid, "limits"))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	var out []Limit
+	for _, line := range lines[1:] {
+		f := strings.Fields(line)
+		if len(f) < 3 {
+			continue
+		}
+		l := Limit{}
+		// units are absent for e.g. "Max nice priority"
+		if last := f[len(f)-1]; !isLimitValue(last) {
+			l.Units = last
+			f = f[:len(f)-1]
+		}
+		if len(f) < 3 {
+			continue
+		}
+		l.Soft, l.Hard = f[len(f)-2], f[len(f)-1]
+		l.Name = strings.Join(f[:len(f)-2], " ")
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func isLimitValue(s string) bool {
+	if s == "unlimited" {
+		return true
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// Thread is one task of a process.
+type Thread struct {
+	TID   int
+	Name  string
+	State string
+	UTime uint64 // jiffies
+	STime uint64 // jiffies
+}
+
+// Threads lists the tasks under /proc/<pid>/task.
+func (fs FS) Threads(pid int) ([]Thread, error) {
+	entries, err := os.ReadDir(fs.path(pid, "task"))
+	if err != nil {
+		return nil, err
+	}
+	taskFS := FS{Root: fs.path(pid, "task")}
+	var out []Thread
+	for _, e := range entries {
+		tid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		p := Process{PID: tid}
+		if err := taskFS.parseStat(&p, time.Time{}); err != nil {
+			continue
+		}
+		out = append(out, Thread{TID: tid, Name: p.Name, State: p.State, UTime: p.UTime, STime: p.STime})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TID < out[j].TID })
+	return out, nil
+}
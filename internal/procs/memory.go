@@ -0,0 +1,86 @@
+package procs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MemUsage is the memory accounting of one process. All values are bytes.
+type MemUsage struct {
+	RSS     uint64
+	PSS     uint64 // RSS with shared pages divided among their users
+	Swap    uint64
+	SwapPSS uint64
+	// Rollup is false when smaps_rollup could not be read (kernels before
+	// 4.14, or another user's process); PSS and SwapPSS are zero then and
+	// RSS and Swap come from status.
+	Rollup bool
+}
+
+// MemUsage reads /proc/<pid>/smaps_rollup, falling back to the VmRSS and
+// VmSwap lines of /proc/<pid>/status.
+func (fs FS) MemUsage(pid int) (MemUsage, error) {
+	var u MemUsage
+	if b, err := os.ReadFile(fs.path(pid, "smaps_rollup")); err == nil && len(b) > 0 {
+		u.Rollup = true
+		for _, line := range strings.Split(string(b), "\n") {
+			k, v, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			switch k {
+			case "Rss":
+				u.RSS = parseKB(v)
+			case "Pss":
+				u.PSS = parseKB(v)
+			case "Swap":
+				u.Swap = parseKB(v)
+			case "SwapPss":
+				u.SwapPSS = parseKB(v)
+			}
+		}
+		return u, nil
+	}
+	b, err := os.ReadFile(fs.path(pid, "status"))
+	if err != nil {
+		return u, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "VmRSS":
+			u.RSS = parseKB(v)
+		case "VmSwap":
+			u.Swap = parseKB(v)
+		}
+	}
+	return u, nil
+}
+
+// parseKB parses a "  1234 kB" value into bytes.
+func parseKB(v string) uint64 {
+	f := strings.Fields(v)
+	if len(f) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseUint(f[0], 10, 64)
+	return n * 1024
+}
+
+// OOMScore returns the kernel's current OOM badness score (0-1000, higher
+// is killed first) and the user-set oom_score_adj (-1000..1000).
+func (fs FS) OOMScore(pid int) (score, adj int, err error) {
+	b, err := os.ReadFile(fs.path(pid, "oom_score"))
+	if err != nil {
+		return 0, 0, err
+	}
+	score, _ = strconv.Atoi(strings.TrimSpace(string(b)))
+	if b, err := os.ReadFile(fs.path(pid, "oom_score_adj")); err == nil {
+		adj, _ = strconv.Atoi(strings.TrimSpace(string(b)))
+	}
+	return score, adj, nil
+}
@@ -0,0 +1,240 @@
+// Package procs reads the process table directly from procfs.
+package procs
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// ClockTicks is USER_HZ, the unit of the utime/stime/starttime fields.
+// It is 100 on every mainstream Linux architecture.
+const ClockTicks = 100
+
+// Process is one entry of the process table.
+type Process struct {
+	PID       int
+	PPID      int
+	Name      string   // comm, at most 15 characters
+	Cmdline   []string // empty for kernel threads
+	UID       int
+	User      string
+	State     string
+	Nice      int
+	Threads   int
+	RSS       uint64 // bytes
+	VSZ       uint64 // bytes
+	StartTime time.Time
+	UTime     uint64 // jiffies
+	STime     uint64 // jiffies
+
+	// Filled in by Sampler.
+	CPU float64 // percent of one core since the previous sample
+	Mem float64 // RSS as percent of MemTotal
+
+	// Storage I/O from /proc/<pid>/io, only read when Sampler.IO is set.
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadRate   float64 // bytes/s
+	WriteRate  float64 // bytes/s
+}
+
+// CommandLine returns the full command line, or the bracketed comm for
+// kernel threads like ps does.
+func (p Process) CommandLine() string {
+	if len(p.Cmdline) == 0 {
+		return "[" + p.Name + "]"
+	}
+	return strings.Join(p.Cmdline, " ")
+}
+
+// FS reads processes from a procfs tree.
+type FS struct {
+	Root string
+}
+
+// Default reads from the live /proc.
+var Default = FS{Root: "/proc"}
+
+// NewFS returns an FS rooted at root; an empty root means /proc.
+func NewFS(root string) FS {
+	if root == "" {
+		root = "/proc"
+	}
+	return FS{Root: root}
+}
+
+func (fs FS) path(pid int, elem ...string) string {
+	return filepath.Join(append([]string{fs.Root, strconv.Itoa(pid)}, elem...)...)
+}
+
+// PIDs lists the numeric entries of the procfs root.
+func (fs FS) PIDs() ([]int, error) {
+	entries, err := os.ReadDir(fs.Root)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, e := range entries {
+		if pid, err := strconv.Atoi(e.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// List reads every process. Processes that exit while being read are skipped.
+func (fs FS) List() ([]Process, error) {
+	pids, err := fs.PIDs()
+	if err != nil {
+		return nil, err
+	}
+	boot := fs.bootTime()
+	out := make([]Process, 0, len(pids))
+	for _, pid := range pids {
+		p, err := fs.read(pid, boot)
+		if err != nil {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Read reads a single process.
+func (fs FS) Read(pid int) (Process, error) {
+	return fs.read(pid, fs.bootTime())
+}
+
+// StartTime reads when pid was started. Comparing it with an earlier reading
+// tells whether the pid has since been reused by another process.
+func (fs FS) StartTime(pid int) (time.Time, error) {
+	p := Process{PID: pid}
+	err := fs.parseStat(&p, fs.bootTime())
+	return p.StartTime, err
+}
+
+func (fs FS) bootTime() time.Time {
+	st, _ := metrics.NewFS(fs.Root).Stat()
+	return st.BootTime
+}
+
+func (fs FS) read(pid int, boot time.Time) (Process, error) {
+	p := Process{PID: pid}
+	if err := fs.parseStat(&p, boot); err != nil {
+		return p, err
+	}
+	fs.parseStatus(&p)
+	if b, err := os.ReadFile(fs.path(pid, "cmdline")); err == nil {
+		for _, arg := range strings.Split(strings.TrimRight(string(b), "\x00"), "\x00") {
+			if arg != "" {
+				p.Cmdline = append(p.Cmdline, arg)
+			}
+		}
+	}
+	p.User = UserName(p.UID)
+	return p, nil
+}
+
+// parseStat reads /proc/<pid>/stat. The comm field is enclosed in
+// parentheses and may itself contain spaces or ')', so everything up to the
+// last ')' belongs to it.
+func (fs FS) parseStat(p *Process, boot time.Time) error {
+	b, err := os.ReadFile(fs.path(p.PID, "stat"))
+	if err != nil {
+		return err
+	}
+	s := string(b)
+	open, end := strings.IndexByte(s, '('), strings.LastIndexByte(s, ')')
+	if open == -1 || end < open {
+		return fmt.Errorf("%s: malformed stat", fs.path(p.PID, "stat"))
+	}
+	p.Name = s[open+1 : end]
+	f := strings.Fields(s[end+1:])
+	// f[0] is field 3 (state) of proc(5)
+	if len(f) < 22 {
+		return fmt.Errorf("%s: short stat", fs.path(p.PID, "stat"))
+	}
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(f[n-3], 10, 64)
+		return v
+	}
+	p.State = f[0]
+	p.PPID = int(field(4))
+	p.UTime = field(14)
+	p.STime = field(15)
+	p.Nice, _ = strconv.Atoi(f[19-3])
+	p.Threads = int(field(20))
+	// scale the tick unit first; ticks * time.Second overflows after ~3 years
+	p.StartTime = boot.Add(time.Duration(field(22)) * (time.Second / ClockTicks))
+	p.VSZ = field(23)
+	p.RSS = field(24) * uint64(os.Getpagesize())
+	return nil
+}
+
+// parseStatus fills the real UID from /proc/<pid>/status.
+func (fs FS) parseStatus(p *Process) {
+	b, err := os.ReadFile(fs.path(p.PID, "status"))
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "Uid:") {
+			if f := strings.Fields(line); len(f) > 1 {
+				p.UID, _ = strconv.Atoi(f[1])
+			}
+			return
+		}
+	}
+}
+
+// ReadIO fills ReadBytes and WriteBytes from /proc/<pid>/io. The file is
+// only readable for one's own processes unless running as root.
+func (fs FS) ReadIO(p *Process) error {
+	b, err := os.ReadFile(fs.path(p.PID, "io"))
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+		switch k {
+		case "read_bytes":
+			p.ReadBytes = n
+		case "write_bytes":
+			p.WriteBytes = n
+		}
+	}
+	return nil
+}
+
+var (
+	userMu    sync.Mutex
+	userCache = map[int]string{}
+)
+
+// UserName resolves a UID to a login name, falling back to the number.
+// Results are cached for the life of the process.
+func UserName(uid int) string {
+	userMu.Lock()
+	defer userMu.Unlock()
+	if name, ok := userCache[uid]; ok {
+		return name
+	}
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	userCache[uid] = name
+	return name
+}
@@ -0,0 +1,80 @@
+package procs
+
+import (
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// Sampler reads the process table repeatedly and derives CPU usage from the
+// jiffy deltas between consecutive samples.
+type Sampler struct {
+	// IO additionally reads /proc/<pid>/io and derives read/write rates.
+	IO bool
+
+	fs     FS
+	prev   map[sampleKey]uint64
+	prevIO map[sampleKey][2]uint64
+	last   time.Time
+}
+
+// processes are keyed by pid and start time so a recycled pid does not
+// inherit the counters of its predecessor
+type sampleKey struct {
+	pid   int
+	start time.Time
+}
+
+// NewSampler returns a sampler reading from fs.
+func NewSampler(fs FS) *Sampler {
+	return &Sampler{fs: fs}
+}
+
+// Sample reads all processes. CPU is zero for processes first seen in this
+// sample (and for everything on the first call).
+func (s *Sampler) Sample() ([]Process, error) {
+	list, err := s.fs.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var memTotal uint64
+	if mi, err := metrics.NewFS(s.fs.Root).MemInfo(); err == nil {
+		memTotal = mi.MemTotal
+	}
+	secs := now.Sub(s.last).Seconds()
+	elapsed := secs * ClockTicks
+	cur := make(map[sampleKey]uint64, len(list))
+	curIO := map[sampleKey][2]uint64{}
+	for i := range list {
+		p := &list[i]
+		key := sampleKey{p.PID, p.StartTime}
+		ticks := p.UTime + p.STime
+		cur[key] = ticks
+		if prev, ok := s.prev[key]; ok && elapsed > 0 && ticks >= prev {
+			p.CPU = float64(ticks-prev) / elapsed * 100
+		}
+		if memTotal > 0 {
+			p.Mem = float64(p.RSS) / float64(memTotal) * 100
+		}
+		if s.IO && s.fs.ReadIO(p) == nil {
+			curIO[key] = [2]uint64{p.ReadBytes, p.WriteBytes}
+			if prev, ok := s.prevIO[key]; ok && secs > 0 && p.ReadBytes >= prev[0] && p.WriteBytes >= prev[1] {
+				p.ReadRate = float64(p.ReadBytes-prev[0]) / secs
+				p.WriteRate = float64(p.WriteBytes-prev[1]) / secs
+			}
+		}
+	}
+	s.prev, s.prevIO, s.last = cur, curIO, now
+	return list, nil
+}
+
+// SampleOver takes two samples interval apart and returns the second, so
+// one-shot callers get meaningful CPU figures.
+func (s *Sampler) SampleOver(interval time.Duration) ([]Process, error) {
+	if _, err := s.Sample(); err != nil {
+		return nil, err
+	}
+	time.Sleep(interval)
+	return s.Sample()
+}
@@ -0,0 +1,254 @@
+package pulse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"syskit/internal/config"
+	"syskit/internal/procs"
+)
+
+// CustomAction runs Command against processes matching all of its
+// conditions for at least For.
+type CustomAction struct {
+	Name     string
+	Process  string         // glob on the process name, "" or "*" for any
+	Cmdline  *regexp.Regexp // nil matches any command line
+	User     string         // glob on the user name
+	CPU      float64        // minimum CPU%, 0 to ignore
+	Mem      float64        // minimum MEM%, 0 to ignore
+	For      time.Duration
+	Command  string // shell command with {pid} {name} {user} {cpu} {mem}
+	Cooldown time.Duration
+	DryRun   bool
+}
+
+// defaultCooldown applies when an action does not set one.
+const defaultCooldown = time.Minute
+
+// actionTimeout bounds a single command run.
+const actionTimeout = 30 * time.Second
+
+// LoadActions converts the pulse.actions config entries.
+func LoadActions(cfg []config.PulseAction) ([]CustomAction, error) {
+	var out []CustomAction
+	for i, c := range cfg {
+		a := CustomAction{Name: c.Name, Process: c.Process, User: c.User, CPU: c.CPU, Mem: c.Mem,
+			Command: c.Command, Cooldown: defaultCooldown, DryRun: c.DryRun}
+		if a.Name == "" {
+			a.Name = fmt.Sprintf("action-%d", i+1)
+		}
+		if strings.TrimSpace(a.Command) == "" {
+			return nil, fmt.Errorf("pulse action %s: command required", a.Name)
+		}
+		for _, g := range []string{a.Process, a.User} {
+			if _, err := filepath.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("pulse action %s: bad pattern %q", a.Name, g)
+			}
+		}
+		if c.Cmdline != "" {
+			re, err := regexp.Compile(c.Cmdline)
+			if err != nil {
+				return nil, fmt.Errorf("pulse action %s: cmdline: %w", a.Name, err)
+			}
+			a.Cmdline = re
+		}
+		var err error
+		if c.For != "" {
+			if a.For, err = time.ParseDuration(c.For); err != nil {
+				return nil, fmt.Errorf("pulse action %s: for: %w", a.Name, err)
+			}
+		}
+		if c.Cooldown != "" {
+			if a.Cooldown, err = time.ParseDuration(c.Cooldown); err != nil {
+				return nil, fmt.Errorf("pulse action %s: cooldown: %w", a.Name, err)
+			}
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// Matches reports whether p satisfies every condition except the duration.
+func (a CustomAction) Matches(p procs.Process) bool {
+	if a.Process != "" && a.Process != "*" {
+		if ok, _ := filepath.Match(a.Process, p.Name); !ok {
+			return false
+		}
+	}
+	if a.User != "" && a.User != "*" {
+		if ok, _ := filepath.Match(a.User, p.User); !ok {
+			return false
+		}
+	}
+	if a.Cmdline != nil && !a.Cmdline.MatchString(p.CommandLine()) {
+		return false
+	}
+	return p.CPU >= a.CPU && p.Mem >= a.Mem
+}
+
+// Expand substitutes process fields into the command. Values are quoted for
+// sh because process names and users are not trusted input.
+func (a CustomAction) Expand(p procs.Process) string {
+	return strings.NewReplacer(
+		"{pid}", strconv.Itoa(p.PID),
+		"{name}", shellQuote(p.Name),
+		"{user}", shellQuote(p.User),
+		"{cpu}", fmt.Sprintf("%.1f", p.CPU),
+		"{mem}", fmt.Sprintf("%.1f", p.Mem),
+	).Replace(a.Command)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// actionEntry is one line of the action log.
+type actionEntry struct {
+	At     time.Time
+	Action string
+	PID    int
+	Name   string
+	Cmd    string
+	Status string // dry-run | running | ok | failed: ...
+}
+
+func (e actionEntry) String() string {
+	return fmt.Sprintf("%s %-16s pid=%d %s  %s  [%s]", e.At.Format("15:04:05"), e.Action, e.PID, e.Name, e.Cmd, e.Status)
+}
+
+type breachKey struct {
+	action int
+	pid    int
+	start  int64 // process start time, guards against pid reuse
+}
+
+// actionRunner evaluates actions over successive process samples.
+type actionRunner struct {
+	actions []CustomAction
+	dryRun  bool
+	since   map[breachKey]time.Time
+	fired   map[breachKey]time.Time
+	log     []actionEntry
+}
+
+func newActionRunner(actions []CustomAction, dryRun bool) *actionRunner {
+	return &actionRunner{actions: actions, dryRun: dryRun,
+		since: map[breachKey]time.Time{}, fired: map[breachKey]time.Time{}}
+}
+
+// maxActionLog bounds the in-memory action log.
+const maxActionLog = 200
+
+// Evaluate returns the actions that fire for list at now and records them
+// in the log. Dry-run triggers are logged but not returned.
+func (r *actionRunner) Evaluate(list []procs.Process, now time.Time) []actionEntry {
+	var out []actionEntry
+	seen := map[breachKey]bool{}
+	for ai, a := range r.actions {
+		for _, p := range list {
+			if !a.Matches(p) {
+				continue
+			}
+			k := breachKey{ai, p.PID, p.StartTime.UnixNano()}
+			seen[k] = true
+			start, ok := r.since[k]
+			if !ok {
+				start = now
+				r.since[k] = start
+			}
+			if now.Sub(start) < a.For {
+				continue
+			}
+			if last, ok := r.fired[k]; ok && now.Sub(last) < a.Cooldown {
+				continue
+			}
+			r.fired[k] = now
+			e := actionEntry{At: now, Action: a.Name, PID: p.PID, Name: p.Name, Cmd: a.Expand(p), Status: "running"}
+			if r.dryRun || a.DryRun {
+				e.Status = "dry-run"
+				r.append(e)
+				continue
+			}
+			r.append(e)
+			out = append(out, e)
+		}
+	}
+	for k := range r.since {
+		if !seen[k] {
+			delete(r.since, k)
+		}
+	}
+	// forget cooldowns of processes that stopped matching long ago
+	for k, t := range r.fired {
+		if !seen[k] && now.Sub(t) > r.actions[k.action].Cooldown {
+			delete(r.fired, k)
+		}
+	}
+	return out
+}
+
+func (r *actionRunner) append(e actionEntry) {
+	writeActionLog(e)
+	r.log = append(r.log, e)
+	if len(r.log) > maxActionLog {
+		r.log = r.log[len(r.log)-maxActionLog:]
+	}
+}
+
+// finish updates the status of a running entry. Entries are matched by
+// content because the log may have been trimmed in the meantime.
+func (r *actionRunner) finish(done actionEntry, status string) {
+	done.Status = status
+	writeActionLog(done)
+	for i := len(r.log) - 1; i >= 0; i-- {
+		e := r.log[i]
+		if e.At.Equal(done.At) && e.Action == done.Action && e.PID == done.PID && e.Status == "running" {
+			r.log[i].Status = status
+			return
+		}
+	}
+}
+
+// tail returns the last n log entries, newest last.
+func (r *actionRunner) tail(n int) []actionEntry {
+	if len(r.log) <= n {
+		return r.log
+	}
+	return r.log[len(r.log)-n:]
+}
+
+// runAction executes the entry's command through sh.
+func runAction(e actionEntry) string {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "sh", "-c", e.Cmd).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "failed: " + msg
+	}
+	return "ok"
+}
+
+// writeActionLog appends e to ~/.syskit/pulse_actions.log.
+func writeActionLog(e actionEntry) {
+	home, _ := os.UserHomeDir()
+	path := filepath.Join(home, ".syskit", "pulse_actions.log")
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s action=%q pid=%d name=%q cmd=%q status=%q\n", e.At.Format(time.RFC3339), e.Action, e.PID, e.Name, e.Cmd, e.Status)
+}
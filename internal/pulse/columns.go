@@ -0,0 +1,246 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"syskit/internal/theme"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// column is one selectable field of the process table.
+type column struct {
+	key   string
+	title string
+	width int  // 0 stretches to the remaining terminal width
+	desc  bool // sort descending by default (largest first)
+	text  func(p proc) string
+	cmp   func(a, b proc) int
+}
+
+func cmpNum[T int | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+var allColumns = []column{
+	{key: "pid", title: "PID", width: 7,
+		text: func(p proc) string { return strconv.Itoa(p.pid) },
+		cmp:  func(a, b proc) int { return cmpNum(a.pid, b.pid) }},
+	{key: "ppid", title: "PPID", width: 7,
+		text: func(p proc) string { return strconv.Itoa(p.ppid) },
+		cmp:  func(a, b proc) int { return cmpNum(a.ppid, b.ppid) }},
+	{key: "user", title: "USER", width: 9,
+		text: func(p proc) string { return p.user },
+		cmp:  func(a, b proc) int { return strings.Compare(a.user, b.user) }},
+	{key: "name", title: "NAME", width: 15,
+		text: func(p proc) string { return p.name },
+		cmp:  func(a, b proc) int { return strings.Compare(a.name, b.name) }},
+	{key: "state", title: "S", width: 2,
+		text: func(p proc) string { return p.state },
+		cmp:  func(a, b proc) int { return strings.Compare(a.state, b.state) }},
+	{key: "nice", title: "NI", width: 3,
+		text: func(p proc) string { return strconv.Itoa(p.nice) },
+		cmp:  func(a, b proc) int { return cmpNum(a.nice, b.nice) }},
+	{key: "threads", title: "THR", width: 4, desc: true,
+		text: func(p proc) string { return strconv.Itoa(p.threads) },
+		cmp:  func(a, b proc) int { return cmpNum(a.threads, b.threads) }},
+	{key: "cpu", title: "CPU%", width: 6, desc: true,
+		text: func(p proc) string { return fmt.Sprintf("%.1f", p.cpu) },
+		cmp:  func(a, b proc) int { return cmpNum(a.cpu, b.cpu) }},
+	{key: "mem", title: "MEM%", width: 6, desc: true,
+		text: func(p proc) string { return fmt.Sprintf("%.1f", p.mem) },
+		cmp:  func(a, b proc) int { return cmpNum(a.mem, b.mem) }},
+	{key: "rss", title: "RSS", width: 10, desc: true,
+		text: func(p proc) string { return humanBytes(p.rss) },
+		cmp:  func(a, b proc) int { return cmpNum(a.rss, b.rss) }},
+	{key: "vsz", title: "VSZ", width: 10, desc: true,
+		text: func(p proc) string { return humanBytes(p.vsz) },
+		cmp:  func(a, b proc) int { return cmpNum(a.vsz, b.vsz) }},
+	{key: "start", title: "START", width: 11,
+		text: func(p proc) string { return formatStart(p.start, time.Now()) },
+		cmp:  func(a, b proc) int { return a.start.Compare(b.start) }},
+	{key: "io_read", title: "READ/s", width: 10, desc: true,
+		text: func(p proc) string { return humanRate(p.ioRead) },
+		cmp:  func(a, b proc) int { return cmpNum(a.ioRead, b.ioRead) }},
+	{key: "io_write", title: "WRITE/s", width: 10, desc: true,
+		text: func(p proc) string { return humanRate(p.ioWrite) },
+		cmp:  func(a, b proc) int { return cmpNum(a.ioWrite, b.ioWrite) }},
+	{key: "cmd", title: "CMD",
+		text: func(p proc) string { return p.prefix + p.cmd },
+		cmp:  func(a, b proc) int { return strings.Compare(a.cmd, b.cmd) }},
+}
+
+var defaultColumns = []string{"pid", "user", "cpu", "mem", "cmd"}
+
+// columnKeys lists every selectable column key, for flag help.
+func columnKeys() []string {
+	keys := make([]string, len(allColumns))
+	for i, c := range allColumns {
+		keys[i] = c.key
+	}
+	return keys
+}
+
+func columnByKey(key string) (column, bool) {
+	for _, c := range allColumns {
+		if c.key == key {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// parseColumns resolves keys in the given order; an empty list yields the
+// defaults.
+func parseColumns(keys []string) ([]column, error) {
+	if len(keys) == 0 {
+		keys = defaultColumns
+	}
+	var out []column
+	seen := map[string]bool{}
+	for _, k := range keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		c, ok := columnByKey(k)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", k, strings.Join(columnKeys(), ", "))
+		}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// sortProcList sorts list in place by the column key, breaking ties by PID so
+// rows with equal values keep a stable order between refreshes.
+func sortProcList(list []proc, key string, desc bool) {
+	less := procLess(key, desc)
+	sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+}
+
+func procLess(key string, desc bool) func(a, b proc) bool {
+	c, ok := columnByKey(key)
+	if !ok {
+		c, _ = columnByKey("cpu")
+	}
+	return func(a, b proc) bool {
+		r := c.cmp(a, b)
+		if desc {
+			r = -r
+		}
+		if r == 0 {
+			return a.pid < b.pid
+		}
+		return r < 0
+	}
+}
+
+// tableColumns sizes cols for a terminal of the given width and marks the
+// sort column in its title.
+func tableColumns(cols []column, width int, sortKey string, desc bool) []table.Column {
+	fixed := 0
+	for _, c := range cols {
+		fixed += c.width + 2 // default cell padding
+	}
+	flex := width - fixed - 2
+	if flex < 20 {
+		flex = 20
+	}
+	out := make([]table.Column, len(cols))
+	for i, c := range cols {
+		title := c.title
+		if c.key == sortKey {
+			if desc {
+				title += "▼"
+			} else {
+				title += "▲"
+			}
+		}
+		w := c.width
+		if w == 0 {
+			w = flex
+		}
+		if lipgloss.Width(title) > w {
+			w = lipgloss.Width(title)
+		}
+		out[i] = table.Column{Title: title, Width: w}
+	}
+	return out
+}
+
+func formatStart(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	if now.Sub(t) < 24*time.Hour && t.Day() == now.Day() {
+		return t.Format("15:04:05")
+	}
+	return t.Format("Jan02 15:04")
+}
+
+func humanRate(bps float64) string {
+	if bps <= 0 {
+		return "0"
+	}
+	return humanBytes(uint64(bps)) + "/s"
+}
+
+// columnMenu toggles the visible columns.
+type columnMenu struct {
+	idx     int
+	enabled map[string]bool
+}
+
+func newColumnMenu(cols []column) *columnMenu {
+	cm := &columnMenu{enabled: map[string]bool{}}
+	for _, c := range cols {
+		cm.enabled[c.key] = true
+	}
+	return cm
+}
+
+// selected returns the enabled columns in canonical order.
+func (cm *columnMenu) selected() []column {
+	var out []column
+	for _, c := range allColumns {
+		if cm.enabled[c.key] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (cm *columnMenu) view() string {
+	th := theme.Current()
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(th.Color(th.Title)).Padding(0, 1)
+	sel := th.Selected()
+	lines := []string{"Columns:"}
+	for i, c := range allColumns {
+		mark := "[ ]"
+		if cm.enabled[c.key] {
+			mark = "[x]"
+		}
+		row := fmt.Sprintf(" %s %-8s %s", mark, c.key, c.title)
+		if i == cm.idx {
+			row = sel.Render(row)
+		}
+		lines = append(lines, row)
+	}
+	lines = append(lines, "↑↓ move  Space toggle  Esc/Enter done")
+	return box.Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,179 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"syskit/internal/procs"
+	"syskit/internal/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var signalChoices = []struct {
+	name string
+	sig  syscall.Signal
+}{
+	{"TERM", syscall.SIGTERM},
+	{"HUP", syscall.SIGHUP},
+	{"INT", syscall.SIGINT},
+	{"STOP", syscall.SIGSTOP},
+	{"CONT", syscall.SIGCONT},
+	{"KILL", syscall.SIGKILL},
+}
+
+type menuKind int
+
+const (
+	menuSignal menuKind = iota
+	menuConfirm
+	menuRenice
+	menuAffinity
+)
+
+// procMenu is the modal opened on the selected process to send a signal,
+// renice it or change its CPU affinity. target keeps the start time seen when
+// the menu opened so a pid reused in the meantime is left alone.
+type procMenu struct {
+	kind   menuKind
+	target proc
+	idx    int    // selected signal
+	input  string // renice / affinity text entry
+	err    string
+}
+
+func newProcMenu(kind menuKind, p proc) *procMenu {
+	pm := &procMenu{kind: kind, target: p}
+	switch kind {
+	case menuRenice:
+		pm.input = strconv.Itoa(p.nice)
+	case menuAffinity:
+		if cpus, err := procs.Affinity(p.pid); err == nil {
+			pm.input = procs.FormatCPUList(cpus)
+		}
+	}
+	return pm
+}
+
+// update handles a key while the menu is open. It returns the status line
+// to show once the menu closes and whether it did close.
+func (pm *procMenu) update(msg tea.KeyMsg) (status string, done bool) {
+	key := msg.String()
+	if key == "esc" || key == "ctrl+c" {
+		return "", true
+	}
+	switch pm.kind {
+	case menuSignal:
+		switch key {
+		case "up", "k":
+			if pm.idx > 0 {
+				pm.idx--
+			}
+		case "down", "j":
+			if pm.idx < len(signalChoices)-1 {
+				pm.idx++
+			}
+		case "enter":
+			pm.kind = menuConfirm
+		}
+	case menuConfirm:
+		switch key {
+		case "y", "Y":
+			sc := signalChoices[pm.idx]
+			if err := pm.verify(); err != nil {
+				return err.Error(), true
+			}
+			if err := procs.Signal(pm.target.pid, sc.sig); err != nil {
+				return fmt.Sprintf("SIG%s to %d failed: %s", sc.name, pm.target.pid, err), true
+			}
+			return fmt.Sprintf("Sent SIG%s to %d (%s)", sc.name, pm.target.pid, pm.target.name), true
+		case "n", "N":
+			return "", true
+		}
+	case menuRenice, menuAffinity:
+		switch msg.Type {
+		case tea.KeyEnter:
+			return pm.apply()
+		case tea.KeyBackspace:
+			if pm.input != "" {
+				pm.input = pm.input[:len(pm.input)-1]
+			}
+		case tea.KeyRunes:
+			pm.input += string(msg.Runes)
+		}
+	}
+	return "", false
+}
+
+// verify re-reads the start time of the target so that an action confirmed
+// after the process exited never reaches a new process with the same pid.
+func (pm *procMenu) verify() error {
+	start, err := procs.Default.StartTime(pm.target.pid)
+	if err != nil || !start.Equal(pm.target.start) {
+		return fmt.Errorf("PID %d (%s) has exited, nothing done", pm.target.pid, pm.target.name)
+	}
+	return nil
+}
+
+func (pm *procMenu) apply() (string, bool) {
+	pid := pm.target.pid
+	if err := pm.verify(); err != nil {
+		return err.Error(), true
+	}
+	if pm.kind == menuRenice {
+		n, err := strconv.Atoi(strings.TrimSpace(pm.input))
+		if err != nil {
+			pm.err = "not a number"
+			return "", false
+		}
+		if err := procs.Default.Renice(pid, n); err != nil {
+			return fmt.Sprintf("renice %d failed: %s", pid, err), true
+		}
+		return fmt.Sprintf("Reniced %d (%s) to %d", pid, pm.target.name, n), true
+	}
+	cpus, err := procs.ParseCPUList(pm.input)
+	if err != nil {
+		pm.err = err.Error()
+		return "", false
+	}
+	if err := procs.Default.SetAffinity(pid, cpus); err != nil {
+		return fmt.Sprintf("affinity %d failed: %s", pid, err), true
+	}
+	return fmt.Sprintf("Pinned %d (%s) to CPUs %s", pid, pm.target.name, procs.FormatCPUList(cpus)), true
+}
+
+func (pm *procMenu) view() string {
+	th := theme.Current()
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(th.Color(th.Title)).Padding(0, 1)
+	sel := th.Selected()
+	title := fmt.Sprintf("PID %d  %s", pm.target.pid, pm.target.name)
+	var lines []string
+	switch pm.kind {
+	case menuSignal:
+		lines = append(lines, title, "Send signal:")
+		for i, sc := range signalChoices {
+			row := fmt.Sprintf("  SIG%-5s (%d)", sc.name, int(sc.sig))
+			if i == pm.idx {
+				row = sel.Render(row)
+			}
+			lines = append(lines, row)
+		}
+		lines = append(lines, "↑↓ choose  Enter select  Esc cancel")
+	case menuConfirm:
+		lines = append(lines, title, fmt.Sprintf("Send SIG%s? [y/N]", signalChoices[pm.idx].name))
+	case menuRenice:
+		lines = append(lines, title, "Nice value (-20..19): "+pm.input+"█", "Enter apply  Esc cancel")
+	case menuAffinity:
+		lines = append(lines, title, "CPUs (e.g. 0-3,6): "+pm.input+"█", "Enter apply  Esc cancel")
+	}
+	if pm.err != "" {
+		lines = append(lines, th.Fg(th.Error).Render(pm.err))
+	}
+	return box.Render(strings.Join(lines, "\n"))
+}
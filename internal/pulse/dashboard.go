@@ -4,16 +4,16 @@
 package pulse
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"os/exec"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
-	"sort"
+	"syskit/internal/alert"
+	"syskit/internal/config"
+	"syskit/internal/metrics"
+	"syskit/internal/procs"
+	"syskit/internal/theme"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/table"
@@ -25,27 +25,36 @@ type tickMsg time.Time
 
 // proc represents a process row.
 type proc struct {
-	pid  string
-	user string
-	name string
-	cpu  string
-	mem  string
+	pid     int
+	ppid    int
+	user    string
+	name    string
+	cmd     string
+	state   string
+	nice    int
+	threads int
+	cpu     float64
+	mem     float64
+	rss     uint64
+	vsz     uint64
+	start   time.Time
+	ioRead  float64 // bytes/s
+	ioWrite float64
+
+	// tree view decoration, set by buildTree
+	prefix   string
+	children int
 }
 
-type sortField int
-
-const (
-	sortCPU sortField = iota
-	sortMEM
-	sortPID
-)
+// alertRoutedMsg reports the delivery of threshold alerts.
+type alertRoutedMsg struct {
+	err error
+}
 
-type CustomAction struct {
-	Name      string
-	Process   string  // process name or "*" for any
-	CPUThresh float64 // threshold, e.g. 90.0
-	Action    string  // shell command, e.g. "kill -9 {pid}"
-	Triggered bool    // to avoid repeat
+// actionDoneMsg reports the result of a custom action command.
+type actionDoneMsg struct {
+	entry  actionEntry
+	status string
 }
 
 type Model struct {
@@ -58,66 +67,123 @@ type Model struct {
 	netTx    float64
 
 	// prev values for delta calculations
-	prevIdle  []uint64
-	prevTotal []uint64
+	prevCPU   []metrics.CPUStat
 	prevRx    uint64
 	prevTx    uint64
+	prevNetAt time.Time
 
 	// process table
 	procs      []proc
 	tbl        table.Model
-	sortBy     sortField
+	columns    []column
+	colMenu    *columnMenu
+	sortKey    string
+	sortDesc   bool
 	filter     string
 	filterMode bool
 	selected   int
 
 	// ui helpers
 	progress progress.Model
+	sampler  *procs.Sampler
 
 	// kill process feedback
 	killMsg string
 
 	// custom actions
-	customActions []CustomAction
-	actionMsg     string
+	actions   *actionRunner
+	actionErr string
+	lastList  []procs.Process
+
+	// threshold alerts from config.yaml
+	cfg          *config.Config
+	alerts       *alert.Engine
+	alertSampler *alert.Sampler
 
 	// help menu
 	helpMode bool
+
+	// rolling history for graph mode
+	hist       *metricHistory
+	histWindow time.Duration
+	graphMode  bool
+
+	// process tree and per-process menu
+	treeMode  bool
+	collapsed map[int]bool
+	menu      *procMenu
+
+	// tabs and process detail view
+	tab    tab
+	io     ioState
+	detail *procDetail
+	height int
+	width  int
+
+	// playback of a recording; nil when showing the live system
+	replay *replayState
 }
 
 // Initial returns initialised model.
 func Initial() Model {
-	prg := progress.New(progress.WithDefaultGradient())
-	columns := []table.Column{
-		{Title: "PID", Width: 6},
-		{Title: "USER", Width: 8},
-		{Title: "CPU%", Width: 5},
-		{Title: "MEM%", Width: 5},
-		{Title: "CMD", Width: 20},
-	}
-	tbl := table.New(table.WithColumns(columns), table.WithFocused(true))
-
-	// Örnek custom action: "python" adlı süreç %90 CPU'ya ulaşırsa öldür
-	actions := []CustomAction{
-		{
-			Name:      "Kill High CPU Python",
-			Process:   "python",
-			CPUThresh: 90.0,
-			Action:    "kill -9 {pid}",
-			Triggered: false,
-		},
-	}
-
-	return Model{
-		progress:      prg,
-		tbl:           tbl,
-		sortBy:        sortCPU,
-		customActions: actions,
+	return New(Options{})
+}
+
+// New returns a model configured by opts and config.yaml.
+func New(opts Options) Model {
+	m := Model{
+		progress:  theme.Current().Progress(),
+		sampler:   procs.NewSampler(procs.Default),
+		tbl:       table.New(table.WithFocused(true)),
+		sortKey:   "cpu",
+		sortDesc:  true,
+		cfg:       config.Load(),
+		collapsed: map[int]bool{},
+	}
+	th := theme.Current()
+	st := table.DefaultStyles()
+	st.Header = st.Header.BorderForeground(th.Color(th.Muted))
+	st.Selected = th.Fg(th.Title).Bold(true)
+	m.tbl.SetStyles(st)
+	keys := opts.Columns
+	if len(keys) == 0 {
+		keys = m.cfg.Pulse.Columns
+	}
+	cols, err := parseColumns(keys)
+	if err != nil {
+		m.killMsg = err.Error()
+		cols, _ = parseColumns(nil)
+	}
+	m.setColumns(cols)
+	actions, err := LoadActions(m.cfg.Pulse.Actions)
+	if err != nil {
+		m.actionErr = err.Error()
+	}
+	m.actions = newActionRunner(actions, opts.DryRun || m.cfg.Pulse.DryRun)
+	window := opts.History
+	if window <= 0 {
+		if d, err := time.ParseDuration(m.cfg.Pulse.History); err == nil && d > 0 {
+			window = d
+		} else {
+			window = DefaultHistory
+		}
+	}
+	m.histWindow = window
+	m.setStep(time.Second)
+	// invalid threshold settings only disable alerting; the dashboard still runs
+	if rules, err := alert.RulesFromConfig(m.cfg); err == nil && len(rules) > 0 {
+		m.alerts = alert.NewEngine(rules)
+		m.alerts.Repeat, _ = alert.RepeatFromConfig(m.cfg)
+		m.alertSampler = alert.NewSampler()
 	}
+	return m
 }
 
 // Init starts ticker.
 func (m Model) Init() tea.Cmd {
+	if m.replay != nil {
+		return tea.Tick(replayTick, func(t time.Time) tea.Msg { return replayTickMsg(t) })
+	}
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
 }
 
@@ -126,8 +192,86 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tickMsg:
 		m.refreshMetrics()
-		return m, tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+		cmds := []tea.Cmd{tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })}
+		if cmd := m.routeAlerts(time.Time(msg)); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		for _, e := range m.actions.Evaluate(m.lastList, time.Time(msg)) {
+			cmds = append(cmds, func() tea.Msg { return actionDoneMsg{entry: e, status: runAction(e)} })
+		}
+		return m, tea.Batch(cmds...)
+	case replayTickMsg:
+		m.advance()
+		return m, tea.Tick(replayTick, func(t time.Time) tea.Msg { return replayTickMsg(t) })
+	case alertRoutedMsg:
+		if msg.err != nil {
+			m.killMsg = "alert delivery failed: " + msg.err.Error()
+		}
+		return m, nil
+	case actionDoneMsg:
+		m.actions.finish(msg.entry, msg.status)
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.height, m.width = msg.Height, msg.Width
+		return m, nil
 	case tea.KeyMsg:
+		if m.detail != nil {
+			rows := m.detailRows()
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			case "esc", "enter", "backspace":
+				m.detail = nil
+			case "right", "tab", "l":
+				m.detail.switchSection(1)
+			case "left", "shift+tab", "h":
+				m.detail.switchSection(-1)
+			case "up", "k":
+				m.detail.scroll(-1, rows)
+			case "down", "j":
+				m.detail.scroll(1, rows)
+			case "pgup":
+				m.detail.scroll(-rows, rows)
+			case "pgdown", " ":
+				m.detail.scroll(rows, rows)
+			case "r":
+				section := m.detail.section
+				m.detail = loadDetail(procs.Default, m.detail.pid)
+				m.detail.section = section
+			}
+			return m, nil
+		}
+		if m.colMenu != nil {
+			switch msg.String() {
+			case "up", "k":
+				if m.colMenu.idx > 0 {
+					m.colMenu.idx--
+				}
+			case "down", "j":
+				if m.colMenu.idx < len(allColumns)-1 {
+					m.colMenu.idx++
+				}
+			case " ", "x":
+				key := allColumns[m.colMenu.idx].key
+				m.colMenu.enabled[key] = !m.colMenu.enabled[key]
+				if len(m.colMenu.selected()) == 0 {
+					m.colMenu.enabled[key] = true
+				}
+				m.setColumns(m.colMenu.selected())
+			case "esc", "enter", "C", "q":
+				m.colMenu = nil
+			}
+			return m, nil
+		}
+		if m.menu != nil {
+			if status, done := m.menu.update(msg); done {
+				m.menu = nil
+				if status != "" {
+					m.killMsg = status
+				}
+			}
+			return m, nil
+		}
 		if m.helpMode {
 			if msg.String() == "h" || msg.String() == "?" || msg.String() == "esc" {
 				m.helpMode = false
@@ -153,15 +297,40 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		default:
+			if m.replay != nil {
+				if m.replayKey(msg.String()) {
+					return m, nil
+				}
+				// recorded processes may no longer exist, or be someone else
+				switch msg.String() {
+				case "enter", "K", "s", "n", "a":
+					m.killMsg = "not available during replay"
+					return m, nil
+				}
+			}
 			switch msg.String() {
 			case "q", "ctrl+c":
 				return m, tea.Quit
 			case "f3":
-				m.sortBy = sortCPU
+				m.sortBy("cpu")
 			case "f4":
-				m.sortBy = sortMEM
+				m.sortBy("mem")
 			case "f5":
-				m.sortBy = sortPID
+				m.sortBy("pid")
+			case ">", "<":
+				m.cycleSort(msg.String() == ">")
+			case "r":
+				m.sortDesc = !m.sortDesc
+			case "C":
+				m.colMenu = newColumnMenu(m.columns)
+			case "tab", "right":
+				m.tab = (m.tab + 1) % numTabs
+			case "shift+tab", "left":
+				m.tab = (m.tab + numTabs - 1) % numTabs
+			case "enter":
+				if p, ok := m.selectedProc(); ok {
+					m.detail = loadDetail(procs.Default, p.pid)
+				}
 			case "/":
 				m.filterMode = true
 				m.filter = ""
@@ -170,21 +339,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selected--
 				}
 			case "down", "j":
-				if m.selected < len(m.sortProcs())-1 {
+				if m.selected < len(m.visibleProcs())-1 {
 					m.selected++
 				}
-			case "K":
-				// Kill selected process
-				procs := m.sortProcs()
-				if m.selected < len(procs) {
-					pid := procs[m.selected].pid
-					err := exec.Command("kill", "-9", pid).Run()
-					if err != nil {
-						m.killMsg = fmt.Sprintf("Failed to kill process: %s", err)
-					} else {
-						m.killMsg = fmt.Sprintf("Process %s killed", pid)
-					}
+			case "K", "s":
+				if p, ok := m.selectedProc(); ok {
+					m.menu = newProcMenu(menuSignal, p)
+				}
+			case "n":
+				if p, ok := m.selectedProc(); ok {
+					m.menu = newProcMenu(menuRenice, p)
+				}
+			case "a":
+				if p, ok := m.selectedProc(); ok {
+					m.menu = newProcMenu(menuAffinity, p)
+				}
+			case "t":
+				m.treeMode = !m.treeMode
+				m.selected = 0
+			case " ", "c":
+				if p, ok := m.selectedProc(); ok && m.treeMode && p.children > 0 {
+					m.collapsed[p.pid] = !m.collapsed[p.pid]
 				}
+			case "g":
+				m.graphMode = !m.graphMode
 			case "h", "?":
 				m.helpMode = true
 			}
@@ -196,24 +374,51 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View renders UI.
 func (m Model) View() string {
 	// Styles
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Background(lipgloss.Color("236")).Padding(0, 1)
+	th := theme.Current()
+	headerStyle := th.Header().Padding(0, 1)
 	panelStyle := lipgloss.NewStyle().Width(40).Padding(0, 1)
-	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Bold(true)
-	killMsgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("160")).Bold(true)
+	selectedStyle := th.Selected()
+	killMsgStyle := th.Fg(th.Error).Bold(true)
 
 	// Header
-	header := headerStyle.Render(" Syskit Pulse — q:quit  ↑↓:Navigate  K:Kill  /:Search  F3 CPU  F4 MEM  F5 PID ")
+	header := headerStyle.Render(" Syskit Pulse — q:quit  Tab/←→:Tabs  ↑↓:Navigate  Enter:Details  g:Graphs  t:Tree  K:Signal  n:Nice  a:Affinity  /:Search  F3/F4/F5 <> r:Sort  C:Columns ")
+	if m.replay != nil {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, headerStyle.Render(m.replay.status()))
+	}
+	if m.detail != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.detail.view(m.detailRows()))
+	}
 
 	// Panels
+	sw := m.hist.width()
 	var cpuPanels []string
 	for i, p := range m.cpuPerc {
+		if m.graphMode && i < len(m.hist.cpu) {
+			cpuPanels = append(cpuPanels, fmt.Sprintf("CPU%-2d %3d%% %s", i, p, sparkline(m.hist.cpu[i].values(), sw, 100)))
+			continue
+		}
 		bar := m.progress.ViewAs(float64(p) / 100)
 		cpuPanels = append(cpuPanels, fmt.Sprintf("CPU%-2d %3d%% %s", i, p, bar))
 	}
-	cpuPanel := panelStyle.Foreground(lipgloss.Color("39")).Render(lipgloss.JoinVertical(lipgloss.Left, cpuPanels...))
-	memPanel := panelStyle.Foreground(lipgloss.Color("45")).Render(fmt.Sprintf("MEM  %3d%% %s", m.memPerc, m.progress.ViewAs(float64(m.memPerc)/100)))
-	diskPanel := panelStyle.Foreground(lipgloss.Color("99")).Render(fmt.Sprintf("DISK %3d%% %s", m.diskPerc, m.progress.ViewAs(float64(m.diskPerc)/100)))
-	netPanel := panelStyle.Foreground(lipgloss.Color("81")).Render(fmt.Sprintf("NET  RX %.1f KB/s  TX %.1f KB/s", m.netRx, m.netTx))
+	cpuPanel := panelStyle.Foreground(th.Color(th.CPU)).Render(lipgloss.JoinVertical(lipgloss.Left, cpuPanels...))
+	pctLine := func(label string, v int, s *series) string {
+		if m.graphMode {
+			return fmt.Sprintf("%-5s %3d%% %s", label, v, sparkline(s.values(), sw, 100))
+		}
+		return fmt.Sprintf("%-5s%3d%% %s", label, v, m.progress.ViewAs(float64(v)/100))
+	}
+	memPanel := panelStyle.Foreground(th.Color(th.Mem)).Render(pctLine("MEM", m.memPerc, m.hist.mem))
+	swapPanel := panelStyle.Foreground(th.Color(th.Swap)).Render(pctLine("SWAP", m.swapPerc, m.hist.swap))
+	diskPanel := panelStyle.Foreground(th.Color(th.Disk)).Render(pctLine("DISK", m.diskPerc, m.hist.disk))
+	netText := fmt.Sprintf("NET  RX %.1f KB/s  TX %.1f KB/s", m.netRx, m.netTx)
+	if m.graphMode {
+		// network rates are scaled to the window's peak, shown in the label
+		rx, tx := m.hist.rx.values(), m.hist.tx.values()
+		netText = fmt.Sprintf("RX %6.0fK %s\nTX %6.0fK %s\npeak %.1f / %.1f KB/s over %s",
+			m.netRx, sparkline(rx, sw, 0), m.netTx, sparkline(tx, sw, 0),
+			peak(rx), peak(tx), time.Duration(m.hist.size)*time.Second)
+	}
+	netPanel := panelStyle.Foreground(th.Color(th.Net)).Render(netText)
 
 	// All metrics in a single vertical box, equal width, no iç içe border
 	metricsBox := lipgloss.NewStyle().
@@ -226,6 +431,7 @@ func (m Model) View() string {
 				lipgloss.Left,
 				cpuPanel,
 				memPanel,
+				swapPanel,
 				diskPanel,
 				netPanel,
 			),
@@ -234,25 +440,16 @@ func (m Model) View() string {
 	// Filter bar
 	filterBar := ""
 	if m.filterMode {
-		filterBar = panelStyle.Foreground(lipgloss.Color("220")).Render(fmt.Sprintf("Search: %s", m.filter))
+		filterBar = panelStyle.Foreground(th.Color(th.Highlight)).Render(fmt.Sprintf("Search: %s", m.filter))
 	}
 
 	// Table with selection and filter
 	rows := []table.Row{}
-	procs := m.sortProcs()
-	if m.filter != "" {
-		var filtered []proc
-		for _, p := range procs {
-			if strings.Contains(strings.ToLower(p.name), strings.ToLower(m.filter)) ||
-				strings.Contains(strings.ToLower(p.user), strings.ToLower(m.filter)) ||
-				strings.Contains(strings.ToLower(p.pid), strings.ToLower(m.filter)) {
-				filtered = append(filtered, p)
-			}
+	for i, p := range m.visibleProcs() {
+		row := make(table.Row, len(m.columns))
+		for j, c := range m.columns {
+			row[j] = c.text(p)
 		}
-		procs = filtered
-	}
-	for i, p := range procs {
-		row := table.Row{p.pid, p.user, p.cpu, p.mem, p.name}
 		if i == m.selected {
 			for j := range row {
 				row[j] = selectedStyle.Render(row[j])
@@ -260,11 +457,11 @@ func (m Model) View() string {
 		}
 		rows = append(rows, row)
 	}
+	m.tbl.SetColumns(tableColumns(m.columns, m.width, m.sortKey, m.sortDesc))
 	m.tbl.SetRows(rows)
 	if m.selected >= len(rows) && len(rows) > 0 {
 		m.selected = len(rows) - 1
 	}
-	tableView := m.tbl.View()
 
 	// Kill feedback
 	killMsg := ""
@@ -272,179 +469,277 @@ func (m Model) View() string {
 		killMsg = killMsgStyle.Render(m.killMsg)
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, metricsBox, filterBar, tableView, killMsg)
+	// Firing alerts
+	alertLine := ""
+	if m.alerts != nil {
+		var firing []string
+		for _, st := range m.alerts.Firing() {
+			firing = append(firing, fmt.Sprintf("%s %.1f%s", st.Rule.Name, st.Value, st.Rule.Unit))
+		}
+		if len(firing) > 0 {
+			alertLine = killMsgStyle.Render("ALERT: " + strings.Join(firing, "  "))
+		}
+	}
+
+	// Action log
+	actionPane := ""
+	if m.actionErr != "" || len(m.actions.log) > 0 {
+		lines := []string{"Actions:"}
+		if m.actionErr != "" {
+			lines = append(lines, killMsgStyle.Render(m.actionErr))
+		}
+		for _, e := range m.actions.tail(5) {
+			lines = append(lines, e.String())
+		}
+		actionPane = th.Fg(th.Muted).Render(strings.Join(lines, "\n"))
+	}
+
+	var body string
+	switch m.tab {
+	case tabDisk:
+		bar := m.progress
+		bar.Width = 20
+		body = m.io.viewDisk(bar.ViewAs)
+	case tabNet:
+		body = m.io.viewNet()
+	default:
+		below := killMsg
+		switch {
+		case m.menu != nil:
+			below = m.menu.view()
+		case m.colMenu != nil:
+			below = m.colMenu.view()
+		}
+		// the table gets whatever height the other parts leave over
+		rest := lipgloss.JoinVertical(lipgloss.Left, header, renderTabBar(m.tab), alertLine, metricsBox, filterBar, below, actionPane)
+		m.tbl.SetHeight(m.tableHeight(lipgloss.Height(rest)))
+		// keep the selected row inside the table viewport
+		m.tbl.SetCursor(m.selected)
+		body = lipgloss.JoinVertical(lipgloss.Left, metricsBox, filterBar, m.tbl.View(), below)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, renderTabBar(m.tab), alertLine, body, actionPane)
 }
 
 // --- helpers ---
 func (m *Model) refreshMetrics() {
 	// CPU
-	m.cpuPerc, m.prevIdle, m.prevTotal = readCPUPerc(m.prevIdle, m.prevTotal)
+	m.cpuPerc, m.prevCPU = readCPUPerc(m.prevCPU)
 
 	// MEM
-	used, total := readMem()
-	if total > 0 {
-		m.memPerc = int(float64(used) / float64(total) * 100)
+	if mi, err := metrics.Default.MemInfo(); err == nil {
+		m.memPerc = int(mi.UsedPercent())
+		m.swapPerc = int(mi.SwapPercent())
 	}
 
 	// DISK root
 	m.diskPerc = readDisk()
 
+	// per-device disk and network figures for the Disk/Net tabs
+	m.io.refresh(metrics.Default, time.Now())
+
 	// NET
 	rx, tx := readNet()
-	if m.prevRx != 0 {
-		m.netRx = float64(rx-m.prevRx) / 1024
-		m.netTx = float64(tx-m.prevTx) / 1024
+	now := time.Now()
+	if secs := now.Sub(m.prevNetAt).Seconds(); !m.prevNetAt.IsZero() && secs > 0 {
+		m.netRx, m.netTx = 0, 0
+		// a counter reset or a vanished interface lowers the totals; the
+		// wrapped delta would flatten every sparkline scaled to its peak
+		if rx >= m.prevRx && tx >= m.prevTx {
+			m.netRx = float64(rx-m.prevRx) / 1024 / secs
+			m.netTx = float64(tx-m.prevTx) / 1024 / secs
+		}
 	}
-	m.prevRx, m.prevTx = rx, tx
+	m.prevRx, m.prevTx, m.prevNetAt = rx, tx, now
+	m.hist.record(m.cpuPerc, m.memPerc, m.swapPerc, m.diskPerc, m.netRx, m.netTx)
 
 	// processes
-	m.procs = topProcs()
+	m.procs = m.topProcs()
 }
 
-func (m *Model) refreshTable() {
-	rows := []table.Row{}
-	for _, p := range m.sortProcs() {
-		rows = append(rows, table.Row{p.pid, p.user, p.cpu, p.mem, p.name})
+// routeAlerts evaluates the threshold rules and returns a command delivering
+// any transitions, or nil when there are none.
+func (m *Model) routeAlerts(now time.Time) tea.Cmd {
+	if m.alerts == nil {
+		return nil
 	}
-	m.tbl.SetRows(rows)
-	if len(rows) > 0 && m.tbl.Cursor() >= len(rows) {
-		m.tbl.SetCursor(len(rows) - 1)
+	evs := m.alerts.Evaluate(m.alertSampler.Sample(m.alerts.Rules(), now), now)
+	if len(evs) == 0 {
+		return nil
 	}
+	cfg := m.cfg
+	return func() tea.Msg { return alertRoutedMsg{err: alert.Route(cfg, evs)} }
 }
 
-func (m Model) sortProcs() []proc {
-	out := make([]proc, len(m.procs))
-	copy(out, m.procs)
-	switch m.sortBy {
-	case sortCPU:
-		sort.Slice(out, func(i, j int) bool { return out[i].cpu > out[j].cpu })
-	case sortMEM:
-		sort.Slice(out, func(i, j int) bool { return out[i].mem > out[j].mem })
-	case sortPID:
-		sort.Slice(out, func(i, j int) bool { return out[i].pid < out[j].pid })
+// setStep sizes the sparkline history for samples taken every step.
+func (m *Model) setStep(step time.Duration) {
+	m.hist = newMetricHistory(m.histWindow, step)
+}
+
+// setColumns switches the visible table columns. IO rates are only
+// sampled while an IO column is shown.
+func (m *Model) setColumns(cols []column) {
+	m.columns = cols
+	m.sampler.IO = false
+	for _, c := range cols {
+		if c.key == "io_read" || c.key == "io_write" {
+			m.sampler.IO = true
+		}
 	}
-	if len(out) > 30 {
-		out = out[:30]
+}
+
+// sortBy sorts by key in the column's natural direction, or reverses the
+// direction when key is already the sort column.
+func (m *Model) sortBy(key string) {
+	if m.sortKey == key {
+		m.sortDesc = !m.sortDesc
+		return
 	}
-	return out
+	c, _ := columnByKey(key)
+	m.sortKey, m.sortDesc = key, c.desc
 }
 
-// readCPUPerc returns percentage per core and new idle/total arrays.
-func readCPUPerc(prevIdle, prevTotal []uint64) (percs []int, newIdle, newTotal []uint64) {
-	f, _ := os.Open("/proc/stat")
-	defer f.Close()
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		txt := scanner.Text()
-		if !strings.HasPrefix(txt, "cpu") || txt[:4] == "cpu " {
-			continue
+// cycleSort moves the sort column to the next (or previous) visible column.
+func (m *Model) cycleSort(forward bool) {
+	idx := 0
+	for i, c := range m.columns {
+		if c.key == m.sortKey {
+			idx = i
 		}
-		fields := strings.Fields(txt)
-		if len(fields) < 5 {
-			continue
-		}
-		var idle, total uint64
-		var vals []uint64
-		for _, s := range fields[1:] {
-			v, _ := strconv.ParseUint(s, 10, 64)
-			vals = append(vals, v)
-		}
-		for i, v := range vals {
-			total += v
-			if i == 3 { // idle
-				idle = v
-			}
-		}
-		newIdle = append(newIdle, idle)
-		newTotal = append(newTotal, total)
 	}
-	percs = make([]int, len(newIdle))
-	for i := range newIdle {
-		if len(prevIdle) != len(newIdle) {
-			percs[i] = 0
-			continue
+	step := len(m.columns) - 1
+	if forward {
+		step = 1
+	}
+	next := m.columns[(idx+step)%len(m.columns)]
+	m.sortKey, m.sortDesc = next.key, next.desc
+}
+
+// tableHeight is the number of rows left for the table when the rest of
+// the screen takes used lines.
+func (m Model) tableHeight(used int) int {
+	if m.height == 0 {
+		return 20
+	}
+	h := m.height - used - 1
+	if h < 3 {
+		h = 3
+	}
+	return h
+}
+
+// visibleProcs returns the sorted rows that pass the search filter, or the
+// process tree in tree mode; the selection index refers to this list.
+func (m Model) visibleProcs() []proc {
+	f := strings.ToLower(m.filter)
+	matches := func(p proc) bool {
+		return strings.Contains(strings.ToLower(p.cmd), f) ||
+			strings.Contains(strings.ToLower(p.user), f) ||
+			strings.Contains(strconv.Itoa(p.pid), f)
+	}
+	if m.treeMode {
+		var keep func(proc) bool
+		if m.filter != "" {
+			keep = matches
 		}
-		idleTicks := newIdle[i] - prevIdle[i]
-		totalTicks := newTotal[i] - prevTotal[i]
-		if totalTicks == 0 {
-			percs[i] = 0
-		} else {
-			percs[i] = int(100 - (float64(idleTicks)/float64(totalTicks))*100)
+		return buildTree(m.procs, procLess(m.sortKey, m.sortDesc), m.collapsed, keep)
+	}
+	list := m.sortProcs()
+	if m.filter == "" {
+		return list
+	}
+	var filtered []proc
+	for _, p := range list {
+		if matches(p) {
+			filtered = append(filtered, p)
 		}
 	}
-	return percs, newIdle, newTotal
+	return filtered
+}
+
+// selectedProc returns the highlighted process on the process tab.
+func (m Model) selectedProc() (proc, bool) {
+	list := m.visibleProcs()
+	if m.tab != tabProcs || m.selected >= len(list) {
+		return proc{}, false
+	}
+	return list[m.selected], true
+}
+
+// detailRows is the number of body lines the detail view can show.
+func (m Model) detailRows() int {
+	if m.height > 8 {
+		return m.height - 6
+	}
+	return 20
 }
 
-func readMem() (used, total int64) {
-	f, err := os.Open("/proc/meminfo")
+func (m Model) sortProcs() []proc {
+	out := make([]proc, len(m.procs))
+	copy(out, m.procs)
+	sortProcList(out, m.sortKey, m.sortDesc)
+	return out
+}
+
+// readCPUPerc returns percentage per core and the samples to diff against next time.
+func readCPUPerc(prev []metrics.CPUStat) (percs []int, cur []metrics.CPUStat) {
+	st, err := metrics.Default.Stat()
 	if err != nil {
-		return 0, 0
+		return nil, prev
 	}
-	defer f.Close()
-	var avail int64
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		var key string
-		var val int64
-		fmt.Sscanf(scanner.Text(), "%s %d", &key, &val)
-		switch key {
-		case "MemTotal:":
-			total = val * 1024
-		case "MemAvailable:":
-			avail = val * 1024
-		}
+	cur = st.CPUs
+	percs = make([]int, len(cur))
+	if len(prev) != len(cur) {
+		return percs, cur
 	}
-	used = total - avail
-	return
+	for i := range cur {
+		percs[i] = int(metrics.Busy(prev[i], cur[i]))
+	}
+	return percs, cur
 }
 
+// readDisk uses the same used/(used+avail) figure as check and the alerts.
 func readDisk() int {
-	var s syscall.Statfs_t
-	if err := syscall.Statfs("/", &s); err != nil {
-		return 0
-	}
-	total := s.Blocks * uint64(s.Bsize)
-	free := s.Bfree * uint64(s.Bsize)
-	if total == 0 {
+	du, err := metrics.DiskUsage("/")
+	if err != nil {
 		return 0
 	}
-	used := total - free
-	return int(float64(used) / float64(total) * 100)
+	return int(du.UsedPercent())
 }
 
 func readNet() (uint64, uint64) {
-	f, err := os.Open("/proc/net/dev")
+	devs, err := metrics.Default.NetDev()
 	if err != nil {
 		return 0, 0
 	}
-	defer f.Close()
-	var rx, tx uint64
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.Contains(line, ":") {
-			parts := strings.Split(line, ":")
-			fields := strings.Fields(parts[1])
-			if len(fields) >= 8 {
-				r, _ := strconv.ParseUint(fields[0], 10, 64)
-				t, _ := strconv.ParseUint(fields[8], 10, 64)
-				rx += r
-				tx += t
-			}
-		}
-	}
-	return rx, tx
+	return metrics.NetTotals(devs)
 }
 
-func topProcs() []proc {
-	out, _ := exec.Command("ps", "-eo", "pid,user,comm,%cpu,%mem", "--no-headers").Output()
-	scanner := bufio.NewScanner(strings.NewReader(string(out)))
-	var list []proc
-	for scanner.Scan() {
-		f := strings.Fields(scanner.Text())
-		if len(f) >= 5 {
-			list = append(list, proc{pid: f[0], user: f[1], name: f[2], cpu: f[3], mem: f[4]})
-		}
+func (m *Model) topProcs() []proc {
+	list, err := m.sampler.Sample()
+	if err != nil {
+		return m.procs
+	}
+	m.lastList = list
+	out := make([]proc, 0, len(list))
+	for _, p := range list {
+		out = append(out, proc{
+			pid:     p.PID,
+			ppid:    p.PPID,
+			user:    p.User,
+			name:    p.Name,
+			cmd:     p.CommandLine(),
+			state:   p.State,
+			nice:    p.Nice,
+			threads: p.Threads,
+			cpu:     p.CPU,
+			mem:     p.Mem,
+			rss:     p.RSS,
+			vsz:     p.VSZ,
+			start:   p.StartTime,
+			ioRead:  p.ReadRate,
+			ioWrite: p.WriteRate,
+		})
 	}
-	return list
+	return out
 }
@@ -0,0 +1,113 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"fmt"
+	"strings"
+
+	"syskit/internal/procs"
+	"syskit/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var detailSections = [...]string{"Files", "Env", "Limits", "Threads"}
+
+// procDetail is the full-screen view opened with Enter on a process row.
+type procDetail struct {
+	pid     int
+	title   string
+	section int
+	lines   [len(detailSections)][]string
+	offset  int
+}
+
+func loadDetail(fs procs.FS, pid int) *procDetail {
+	d := &procDetail{pid: pid, title: fmt.Sprintf("PID %d", pid)}
+	if p, err := fs.Read(pid); err == nil {
+		d.title = fmt.Sprintf("PID %d  %s  user=%s  state=%s  threads=%d  rss=%s\n%s",
+			p.PID, p.Name, p.User, p.State, p.Threads, humanBytes(p.RSS), trunc(p.CommandLine(), 200))
+	}
+	errLine := func(err error) []string { return []string{"unavailable: " + err.Error()} }
+
+	if fds, err := fs.OpenFiles(pid); err != nil {
+		d.lines[0] = errLine(err)
+	} else {
+		for _, fd := range fds {
+			d.lines[0] = append(d.lines[0], fmt.Sprintf("%5d  %s", fd.Num, fd.Target))
+		}
+	}
+	if env, err := fs.Environ(pid); err != nil {
+		d.lines[1] = errLine(err)
+	} else {
+		d.lines[1] = env
+	}
+	if lims, err := fs.Limits(pid); err != nil {
+		d.lines[2] = errLine(err)
+	} else {
+		d.lines[2] = append(d.lines[2], fmt.Sprintf("%-26s %-20s %-20s %s", "LIMIT", "SOFT", "HARD", "UNITS"))
+		for _, l := range lims {
+			d.lines[2] = append(d.lines[2], fmt.Sprintf("%-26s %-20s %-20s %s", l.Name, l.Soft, l.Hard, l.Units))
+		}
+	}
+	if ths, err := fs.Threads(pid); err != nil {
+		d.lines[3] = errLine(err)
+	} else {
+		d.lines[3] = append(d.lines[3], fmt.Sprintf("%7s  %-16s %-5s %10s %10s", "TID", "NAME", "STATE", "USER s", "SYS s"))
+		for _, t := range ths {
+			d.lines[3] = append(d.lines[3], fmt.Sprintf("%7d  %-16s %-5s %10.2f %10.2f", t.TID, t.Name, t.State,
+				float64(t.UTime)/procs.ClockTicks, float64(t.STime)/procs.ClockTicks))
+		}
+	}
+	return d
+}
+
+func (d *procDetail) switchSection(step int) {
+	n := len(detailSections)
+	d.section = (d.section + step + n) % n
+	d.offset = 0
+}
+
+func (d *procDetail) scroll(step, rows int) {
+	max := len(d.lines[d.section]) - rows
+	d.offset += step
+	if d.offset > max {
+		d.offset = max
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+}
+
+func (d *procDetail) view(rows int) string {
+	th := theme.Current()
+	on := th.Selected().Padding(0, 1)
+	off := th.Fg(th.Muted).Padding(0, 1)
+	var tabs []string
+	for i, name := range detailSections {
+		label := fmt.Sprintf("%s (%d)", name, len(d.lines[i]))
+		if i == d.section {
+			tabs = append(tabs, on.Render(label))
+		} else {
+			tabs = append(tabs, off.Render(label))
+		}
+	}
+	lines := d.lines[d.section]
+	end := d.offset + rows
+	if end > len(lines) {
+		end = len(lines)
+	}
+	body := strings.Join(lines[d.offset:end], "\n")
+	if len(lines) == 0 {
+		body = "(none)"
+	}
+	footer := off.UnsetPadding().
+		Render(fmt.Sprintf("lines %d-%d of %d  ←/→ section  ↑/↓ PgUp/PgDn scroll  r reload  esc back", d.offset+1, end, len(lines)))
+	return lipgloss.JoinVertical(lipgloss.Left,
+		th.Heading().Render(d.title),
+		lipgloss.JoinHorizontal(lipgloss.Top, tabs...),
+		body,
+		footer)
+}
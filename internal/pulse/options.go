@@ -0,0 +1,17 @@
+package pulse
+
+import "time"
+
+// Options tune the dashboard at start-up.
+type Options struct {
+	// DryRun logs custom actions that would fire without running them.
+	DryRun bool
+	// History is the sparkline window; zero uses pulse.history from
+	// config.yaml or DefaultHistory.
+	History time.Duration
+	// Columns lists the process table columns by key; empty uses
+	// pulse.columns from config.yaml or the defaults.
+	Columns []string
+	// Speed is the initial playback speed of a replay; zero means 1x.
+	Speed float64
+}
@@ -5,14 +5,17 @@ package pulse
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 
+	"syskit/internal/metrics"
+	"syskit/internal/theme"
+
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -36,10 +39,25 @@ type Model struct {
 }
 
 func Initial() Model {
-	prg := progress.New(progress.WithDefaultGradient())
+	return New(Options{})
+}
+
+// New returns the basic dashboard; custom actions are Linux-only, so opts
+// is ignored here.
+func New(opts Options) Model {
+	prg := theme.Current().Progress()
 	return Model{cpuBar: prg, memBar: prg}
 }
 
+// Record and replay need the Linux sampler.
+func Record(ctx context.Context, path string, interval time.Duration, opts Options) error {
+	return errors.New("pulse recording is only supported on Linux")
+}
+
+func NewReplay(path string, opts Options) (Model, error) {
+	return Model{}, errors.New("pulse replay is only supported on Linux")
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
 }
@@ -47,10 +65,11 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg.(type) {
 	case tickMsg:
-		m.cpuLoad = readLoad()
-		used, total := readMem()
-		if total > 0 {
-			m.memPerc = int(float64(used) / float64(total) * 100)
+		if l, err := metrics.Default.LoadAvg(); err == nil {
+			m.cpuLoad = l.Load1
+		}
+		if mi, err := metrics.Default.MemInfo(); err == nil {
+			m.memPerc = int(mi.UsedPercent())
 		}
 		m.procs = topProcs()
 		return m, tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
@@ -68,7 +87,7 @@ func (m Model) View() string {
 	cpuBar := m.cpuBar.ViewAs(m.cpuLoad / float64(runtime.NumCPU()))
 	memBar := m.memBar.ViewAs(float64(m.memPerc) / 100)
 
-	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Syskit Pulse — q:quit")
+	title := theme.Current().Heading().Render("Syskit Pulse — q:quit")
 	cpuLbl := fmt.Sprintf("CPU  %.2f  (cores %d)", m.cpuLoad, runtime.NumCPU())
 	memLbl := fmt.Sprintf("MEM  %d%%", m.memPerc)
 
@@ -87,35 +106,6 @@ func (m Model) View() string {
 }
 
 // helpers
-func readLoad() float64 {
-	b, _ := os.ReadFile("/proc/loadavg")
-	f, _ := strconv.ParseFloat(strings.Fields(string(b))[0], 64)
-	return f
-}
-
-func readMem() (used, total int64) {
-	f, err := os.Open("/proc/meminfo")
-	if err != nil {
-		return 0, 0
-	}
-	defer f.Close()
-	var avail int64
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		var key string
-		var val int64
-		fmt.Sscanf(scanner.Text(), "%s %d", &key, &val)
-		switch key {
-		case "MemTotal:":
-			total = val * 1024
-		case "MemAvailable:":
-			avail = val * 1024
-		}
-	}
-	used = total - avail
-	return used, total
-}
-
 func topProcs() []proc {
 	out, _ := exec.Command("ps", "-eo", "pid,comm,%cpu,%mem", "--no-headers", "--sort=-%cpu").Output()
 	scanner := bufio.NewScanner(strings.NewReader(string(out)))
@@ -0,0 +1,505 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// Recording file layout: the magic string, then one frame per tick as
+// [int64 unix nanos][uint32 length][deflated JSON Frame]. Frames are
+// appended and flushed one at a time, so a recording cut short by a crash
+// stays readable up to its last complete frame.
+const recordMagic = "SKPULSE1"
+
+// maxFrameSize bounds the length header; anything larger is a torn or
+// corrupt frame.
+const maxFrameSize = 64 << 20
+
+// Frame is everything the dashboard shows for one tick.
+type Frame struct {
+	Time   time.Time    `json:"t"`
+	CPU    []int        `json:"cpu"`
+	Mem    int          `json:"mem"`
+	Swap   int          `json:"swap"`
+	Disk   int          `json:"disk"`
+	NetRx  float64      `json:"rx"`
+	NetTx  float64      `json:"tx"`
+	Procs  []frameProc  `json:"procs"`
+	Mounts []frameMount `json:"mounts,omitempty"`
+	Disks  []frameDisk  `json:"disks,omitempty"`
+	Nets   []frameNet   `json:"nets,omitempty"`
+}
+
+type frameProc struct {
+	PID     int     `json:"p"`
+	PPID    int     `json:"pp"`
+	User    string  `json:"u"`
+	Name    string  `json:"n"`
+	Cmd     string  `json:"c"`
+	State   string  `json:"s"`
+	Nice    int     `json:"ni,omitempty"`
+	Threads int     `json:"th"`
+	CPU     float64 `json:"cpu,omitempty"`
+	Mem     float64 `json:"mem,omitempty"`
+	RSS     uint64  `json:"rss,omitempty"`
+	VSZ     uint64  `json:"vsz,omitempty"`
+	Start   int64   `json:"st"`
+	IORead  float64 `json:"ior,omitempty"`
+	IOWrite float64 `json:"iow,omitempty"`
+}
+
+type frameMount struct {
+	Device string `json:"dev"`
+	Mount  string `json:"mp"`
+	FSType string `json:"fs"`
+	Total  uint64 `json:"total"`
+	Free   uint64 `json:"free"`
+	Used   uint64 `json:"used"`
+}
+
+type frameDisk struct {
+	Name  string  `json:"n"`
+	RIOPS float64 `json:"r"`
+	WIOPS float64 `json:"w"`
+	RKBs  float64 `json:"rkb"`
+	WKBs  float64 `json:"wkb"`
+	Util  float64 `json:"util"`
+}
+
+type frameNet struct {
+	Name   string  `json:"n"`
+	RxKBs  float64 `json:"rx"`
+	TxKBs  float64 `json:"tx"`
+	RxPkts float64 `json:"rxp"`
+	TxPkts float64 `json:"txp"`
+	RxErrs uint64  `json:"rxe,omitempty"`
+	TxErrs uint64  `json:"txe,omitempty"`
+	RxDrop uint64  `json:"rxd,omitempty"`
+	TxDrop uint64  `json:"txd,omitempty"`
+	DErrs  uint64  `json:"de,omitempty"`
+	DDrop  uint64  `json:"dd,omitempty"`
+}
+
+// capture snapshots the model's current figures.
+func (m *Model) capture(now time.Time) Frame {
+	f := Frame{Time: now, CPU: m.cpuPerc, Mem: m.memPerc, Swap: m.swapPerc, Disk: m.diskPerc, NetRx: m.netRx, NetTx: m.netTx}
+	for _, p := range m.procs {
+		f.Procs = append(f.Procs, frameProc{PID: p.pid, PPID: p.ppid, User: p.user, Name: p.name, Cmd: p.cmd,
+			State: p.state, Nice: p.nice, Threads: p.threads, CPU: p.cpu, Mem: p.mem, RSS: p.rss, VSZ: p.vsz,
+			Start: p.start.Unix(), IORead: p.ioRead, IOWrite: p.ioWrite})
+	}
+	for _, r := range m.io.mounts {
+		f.Mounts = append(f.Mounts, frameMount{Device: r.mount.Device, Mount: r.mount.MountPoint, FSType: r.mount.FSType,
+			Total: r.usage.Total, Free: r.usage.Free, Used: r.usage.Used})
+	}
+	for _, d := range m.io.disks {
+		f.Disks = append(f.Disks, frameDisk{Name: d.name, RIOPS: d.rIOPS, WIOPS: d.wIOPS, RKBs: d.rKBs, WKBs: d.wKBs, Util: d.util})
+	}
+	for _, n := range m.io.nets {
+		f.Nets = append(f.Nets, frameNet{Name: n.name, RxKBs: n.rxKBs, TxKBs: n.txKBs, RxPkts: n.rxPkts, TxPkts: n.txPkts,
+			RxErrs: n.rxErrs, TxErrs: n.txErrs, RxDrop: n.rxDrop, TxDrop: n.txDrop, DErrs: n.dErrs, DDrop: n.dDrop})
+	}
+	return f
+}
+
+// apply shows f as if it had just been sampled.
+func (m *Model) apply(f Frame) {
+	m.cpuPerc, m.memPerc, m.swapPerc, m.diskPerc = f.CPU, f.Mem, f.Swap, f.Disk
+	m.netRx, m.netTx = f.NetRx, f.NetTx
+	m.procs = m.procs[:0]
+	for _, p := range f.Procs {
+		m.procs = append(m.procs, proc{pid: p.PID, ppid: p.PPID, user: p.User, name: p.Name, cmd: p.Cmd,
+			state: p.State, nice: p.Nice, threads: p.Threads, cpu: p.CPU, mem: p.Mem, rss: p.RSS, vsz: p.VSZ,
+			start: time.Unix(p.Start, 0), ioRead: p.IORead, ioWrite: p.IOWrite})
+	}
+	m.io.mounts = m.io.mounts[:0]
+	for _, r := range f.Mounts {
+		m.io.mounts = append(m.io.mounts, mountRow{
+			mount: metrics.Mount{Device: r.Device, MountPoint: r.Mount, FSType: r.FSType},
+			usage: metrics.FSUsage{Path: r.Mount, Total: r.Total, Free: r.Free, Used: r.Used}})
+	}
+	m.io.disks = m.io.disks[:0]
+	for _, d := range f.Disks {
+		m.io.disks = append(m.io.disks, diskRow{name: d.Name, rIOPS: d.RIOPS, wIOPS: d.WIOPS, rKBs: d.RKBs, wKBs: d.WKBs, util: d.Util})
+	}
+	m.io.nets = m.io.nets[:0]
+	for _, n := range f.Nets {
+		m.io.nets = append(m.io.nets, netRow{name: n.Name, rxKBs: n.RxKBs, txKBs: n.TxKBs, rxPkts: n.RxPkts, txPkts: n.TxPkts,
+			rxErrs: n.RxErrs, txErrs: n.TxErrs, rxDrop: n.RxDrop, txDrop: n.TxDrop, dErrs: n.DErrs, dDrop: n.DDrop})
+	}
+}
+
+// frameWriter appends frames to a recording.
+type frameWriter struct {
+	f   *os.File
+	buf bytes.Buffer
+}
+
+func createRecording(path string) (*frameWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if st.Size() == 0 {
+		if _, err := f.WriteString(recordMagic); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &frameWriter{f: f}, nil
+	}
+	if err := checkMagic(io.NewSectionReader(f, 0, int64(len(recordMagic)))); err != nil {
+		f.Close()
+		return nil, err
+	}
+	// drop a frame torn by a crash, or new frames would be read as its tail
+	_, end := indexFrames(f, st.Size())
+	if end < st.Size() {
+		if err := f.Truncate(end); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &frameWriter{f: f}, nil
+}
+
+func (w *frameWriter) write(fr Frame) error {
+	w.buf.Reset()
+	var hdr [12]byte
+	w.buf.Write(hdr[:])
+	zw, _ := flate.NewWriter(&w.buf, flate.BestSpeed)
+	if err := json.NewEncoder(zw).Encode(fr); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	b := w.buf.Bytes()
+	binary.BigEndian.PutUint64(b[0:8], uint64(fr.Time.UnixNano()))
+	binary.BigEndian.PutUint32(b[8:12], uint32(len(b)-12))
+	// one write per frame keeps each frame contiguous in the file
+	_, err := w.f.Write(b)
+	return err
+}
+
+func (w *frameWriter) close() error { return w.f.Close() }
+
+func checkMagic(r io.Reader) error {
+	magic := make([]byte, len(recordMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != recordMagic {
+		return errors.New("not a pulse recording")
+	}
+	return nil
+}
+
+// Record samples the system every interval and appends frames to path
+// until ctx is cancelled. It does not start the TUI, run custom actions or
+// send alerts.
+func Record(ctx context.Context, path string, interval time.Duration, opts Options) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w, err := createRecording(path)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+	m := New(opts)
+	m.alerts = nil
+	m.setStep(interval)
+	// every column is recorded, including IO rates
+	m.sampler.IO = true
+	m.refreshMetrics()
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-t.C:
+			m.refreshMetrics()
+			if err := w.write(m.capture(now)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type frameIndex struct {
+	off int64 // of the compressed payload
+	n   uint32
+	at  time.Time
+}
+
+// player reads frames from a recording by index.
+type player struct {
+	f     *os.File
+	index []frameIndex
+}
+
+func openRecording(path string) (*player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMagic(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	p := &player{f: f}
+	p.index, _ = indexFrames(f, st.Size())
+	if len(p.index) == 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s: recording has no frames", path)
+	}
+	return p, nil
+}
+
+// indexFrames lists the complete frames following the magic string of a
+// recording of size bytes and returns the offset just past the last one.
+// Indexing stops at the first frame that is cut short or whose header is
+// implausible: an empty or oversized payload, or a time before the
+// previous frame's.
+func indexFrames(r io.ReaderAt, size int64) ([]frameIndex, int64) {
+	var index []frameIndex
+	off := int64(len(recordMagic))
+	var hdr [12]byte
+	var prev time.Time
+	for {
+		if _, err := r.ReadAt(hdr[:], off); err != nil {
+			break
+		}
+		ns := int64(binary.BigEndian.Uint64(hdr[0:8]))
+		n := binary.BigEndian.Uint32(hdr[8:12])
+		at := time.Unix(0, ns)
+		if ns <= 0 || n == 0 || n > maxFrameSize || at.Before(prev) || off+12+int64(n) > size {
+			break
+		}
+		index = append(index, frameIndex{off: off + 12, n: n, at: at})
+		off += 12 + int64(n)
+		prev = at
+	}
+	return index, off
+}
+
+func (p *player) frame(i int) (Frame, error) {
+	ix := p.index[i]
+	zr := flate.NewReader(io.NewSectionReader(p.f, ix.off, int64(ix.n)))
+	defer zr.Close()
+	var fr Frame
+	err := json.NewDecoder(zr).Decode(&fr)
+	return fr, err
+}
+
+// at returns the index of the last frame recorded at or before t.
+func (p *player) at(t time.Time) int {
+	i := sort.Search(len(p.index), func(i int) bool { return p.index[i].at.After(t) })
+	if i > 0 {
+		i--
+	}
+	return i
+}
+
+// step is the average interval between frames, 1s for a single frame.
+func (p *player) step() time.Duration {
+	if len(p.index) < 2 || !p.end().After(p.start()) {
+		return time.Second
+	}
+	return p.end().Sub(p.start()) / time.Duration(len(p.index)-1)
+}
+
+func (p *player) start() time.Time { return p.index[0].at }
+func (p *player) end() time.Time   { return p.index[len(p.index)-1].at }
+
+// replayTick is how often the virtual clock advances during replay.
+const replayTick = 100 * time.Millisecond
+
+type replayTickMsg time.Time
+
+// replayState drives the dashboard from a recording instead of /proc.
+type replayState struct {
+	p      *player
+	path   string
+	pos    int       // frame on screen
+	clock  time.Time // virtual time, advanced by speed on every tick
+	speed  float64
+	paused bool
+	err    string
+}
+
+// NewReplay returns a dashboard that plays back a recording made with
+// Record. Process control and custom actions are disabled.
+func NewReplay(path string, opts Options) (Model, error) {
+	p, err := openRecording(path)
+	if err != nil {
+		return Model{}, err
+	}
+	m := New(opts)
+	m.alerts = nil
+	m.actions = newActionRunner(nil, true)
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	m.setStep(p.step())
+	m.replay = &replayState{p: p, path: path, speed: speed, pos: -1}
+	m.seek(p.start())
+	return m, nil
+}
+
+// show puts frame i on screen and records it into the sparkline history.
+func (m *Model) show(i int) {
+	fr, err := m.replay.p.frame(i)
+	if err != nil {
+		m.replay.err = fmt.Sprintf("frame %d: %v", i+1, err)
+		return
+	}
+	m.replay.err = ""
+	m.replay.pos = i
+	m.apply(fr)
+	m.hist.record(m.cpuPerc, m.memPerc, m.swapPerc, m.diskPerc, m.netRx, m.netTx)
+}
+
+// seek jumps to t and rebuilds the sparkline window leading up to it.
+func (m *Model) seek(t time.Time) {
+	r := m.replay
+	if t.Before(r.p.start()) {
+		t = r.p.start()
+	}
+	if t.After(r.p.end()) {
+		t = r.p.end()
+	}
+	r.clock = t
+	i := r.p.at(t)
+	m.hist.reset()
+	from := i - m.hist.size + 1
+	if from < 0 {
+		from = 0
+	}
+	for j := from; j <= i; j++ {
+		m.show(j)
+	}
+}
+
+// step moves n frames and pauses.
+func (m *Model) step(n int) {
+	r := m.replay
+	r.paused = true
+	i := r.pos + n
+	if i < 0 || i >= len(r.p.index) {
+		return
+	}
+	if n == 1 {
+		r.clock = r.p.index[i].at
+		m.show(i)
+		return
+	}
+	m.seek(r.p.index[i].at)
+}
+
+// advance moves the virtual clock by one tick and shows every frame it
+// passed, so the history has no gaps at higher speeds.
+func (m *Model) advance() {
+	r := m.replay
+	if r.paused {
+		return
+	}
+	r.clock = r.clock.Add(time.Duration(float64(replayTick) * r.speed))
+	target := r.p.at(r.clock)
+	if target-r.pos > m.hist.size {
+		m.seek(r.clock)
+	} else {
+		for i := r.pos + 1; i <= target; i++ {
+			m.show(i)
+		}
+	}
+	if !r.clock.Before(r.p.end()) {
+		r.clock = r.p.end()
+		r.paused = true
+	}
+}
+
+// replayKey handles the playback keys; ok is false for all other keys.
+func (m *Model) replayKey(key string) (ok bool) {
+	r := m.replay
+	switch key {
+	case " ", "p":
+		r.paused = !r.paused
+		if !r.paused && !r.clock.Before(r.p.end()) {
+			m.seek(r.p.start())
+		}
+	case ".":
+		m.step(1)
+	case ",":
+		m.step(-1)
+	case "[", "]":
+		d := 10 * time.Second
+		if key == "[" {
+			d = -d
+		}
+		m.seek(r.clock.Add(d))
+	case "{", "}":
+		d := time.Minute
+		if key == "{" {
+			d = -d
+		}
+		m.seek(r.clock.Add(d))
+	case "home":
+		m.seek(r.p.start())
+	case "end":
+		m.seek(r.p.end())
+	case "+", "=":
+		if r.speed < 64 {
+			r.speed *= 2
+		}
+	case "-":
+		if r.speed > 0.25 {
+			r.speed /= 2
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// status is the replay position line shown under the header.
+func (r *replayState) status() string {
+	at := r.clock
+	if r.pos >= 0 {
+		at = r.p.index[r.pos].at
+	}
+	state := "▶"
+	if r.paused {
+		state = "⏸"
+	}
+	s := fmt.Sprintf("REPLAY %s %s  %s  frame %d/%d  %s/%s  x%g  (space:pause  ,/.:step  [/] {/}:seek  +/-:speed)",
+		state, r.path, at.Format("2006-01-02 15:04:05"), r.pos+1, len(r.p.index),
+		r.clock.Sub(r.p.start()).Round(time.Second), r.p.end().Sub(r.p.start()).Round(time.Second), r.speed)
+	if r.err != "" {
+		s += "  " + r.err
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var rec0 = time.Unix(1709280000, 0).UTC()
+
+func testFrame(i int, step time.Duration) Frame {
+	return Frame{
+		Time:  rec0.Add(time.Duration(i) * step),
+		CPU:   []int{i, 100 - i},
+		Mem:   40 + i,
+		NetRx: float64(i) * 1.5,
+		Procs: []frameProc{{PID: 100 + i, User: "root", Name: "worker", Cmd: "worker --id", State: "R", Threads: 1, Start: 1709279000}},
+		Nets:  []frameNet{{Name: "eth0", RxKBs: 12.5}},
+	}
+}
+
+// writeFrames appends frames from..to-1 to path.
+func writeFrames(t *testing.T, path string, from, to int, step time.Duration) {
+	t.Helper()
+	w, err := createRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	for i := from; i < to; i++ {
+		if err := w.write(testFrame(i, step)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkFrames opens path and expects frames 0..n-1.
+func checkFrames(t *testing.T, path string, n int, step time.Duration) {
+	t.Helper()
+	p, err := openRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.f.Close()
+	if len(p.index) != n {
+		t.Fatalf("got %d frames, want %d", len(p.index), n)
+	}
+	for i := range p.index {
+		fr, err := p.frame(i)
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		fr.Time = fr.Time.UTC()
+		if want := testFrame(i, step); !reflect.DeepEqual(fr, want) {
+			t.Errorf("frame %d:\n got %+v\nwant %+v", i, fr, want)
+		}
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec")
+	writeFrames(t, path, 0, 3, time.Second)
+	checkFrames(t, path, 3, time.Second)
+	// a second --record run extends the file
+	writeFrames(t, path, 3, 5, time.Second)
+	checkFrames(t, path, 5, time.Second)
+}
+
+func TestRecordingTornTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rec")
+	writeFrames(t, path, 0, 3, time.Second)
+
+	// the bytes of frame 3, cut in half as by a crash mid-write
+	spare := filepath.Join(dir, "spare")
+	writeFrames(t, spare, 0, 1, time.Second)
+	b, err := os.ReadFile(spare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := b[len(recordMagic):]
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(frame[:len(frame)/2])
+	f.Close()
+
+	checkFrames(t, path, 3, time.Second)
+	writeFrames(t, path, 3, 5, time.Second)
+	checkFrames(t, path, 5, time.Second)
+}
+
+func TestRecordingImplausibleHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		hdr  []byte
+	}{
+		{"huge length", []byte{0x17, 0xb8, 0x9c, 0xf4, 0x9c, 0x7b, 0, 0, 0xff, 0xff, 0xff, 0xff}},
+		{"empty payload", []byte{0x17, 0xb8, 0x9c, 0xf4, 0x9c, 0x7b, 0, 0, 0, 0, 0, 0}},
+		{"time going back", []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 4, 1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rec")
+			writeFrames(t, path, 0, 2, time.Second)
+			f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			f.Write(tt.hdr)
+			f.Close()
+			checkFrames(t, path, 2, time.Second)
+			writeFrames(t, path, 2, 3, time.Second)
+			checkFrames(t, path, 3, time.Second)
+		})
+	}
+	if _, err := openRecording(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("missing file: expected error")
+	}
+	bad := filepath.Join(t.TempDir(), "bad")
+	os.WriteFile(bad, []byte("not a recording"), 0o644)
+	if _, err := openRecording(bad); err == nil {
+		t.Error("bad magic: expected error")
+	}
+	if _, err := createRecording(bad); err == nil {
+		t.Error("append to bad magic: expected error")
+	}
+}
+
+func TestReplaySeek(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "rec")
+	step := 2 * time.Second
+	writeFrames(t, path, 0, 30, step)
+
+	m, err := NewReplay(path, Options{History: 20 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := m.replay
+	if m.hist.size != 10 {
+		t.Errorf("history holds %d samples, want 10 at a 2s step", m.hist.size)
+	}
+	if r.pos != 0 || m.memPerc != 40 {
+		t.Errorf("start: pos %d mem %d", r.pos, m.memPerc)
+	}
+	tests := []struct {
+		at   time.Duration
+		want int
+	}{
+		{21 * time.Second, 10},
+		{20 * time.Second, 10},
+		{-time.Minute, 0},
+		{time.Hour, 29},
+	}
+	for _, tt := range tests {
+		m.seek(rec0.Add(tt.at))
+		if r.pos != tt.want || m.memPerc != 40+tt.want {
+			t.Errorf("seek %v: pos %d mem %d, want frame %d", tt.at, r.pos, m.memPerc, tt.want)
+		}
+	}
+	m.seek(rec0.Add(20 * time.Second))
+	m.step(1)
+	if r.pos != 11 || !r.paused {
+		t.Errorf("step forward: pos %d paused %v", r.pos, r.paused)
+	}
+	m.step(-1)
+	if r.pos != 10 {
+		t.Errorf("step back: pos %d", r.pos)
+	}
+	if got := m.hist.mem.vals; len(got) != 10 {
+		t.Errorf("mem history has %d slots", len(got))
+	}
+}
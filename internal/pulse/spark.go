@@ -0,0 +1,143 @@
+package pulse
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultHistory is the sparkline window when none is configured.
+const DefaultHistory = 2 * time.Minute
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkWidth fits a sparkline next to a label inside a metrics panel.
+const sparkWidth = 24
+
+// series is a fixed-size ring of samples, oldest first when read.
+type series struct {
+	vals []float64
+	next int
+	full bool
+}
+
+func newSeries(n int) *series {
+	if n < 1 {
+		n = 1
+	}
+	return &series{vals: make([]float64, n)}
+}
+
+func (s *series) push(v float64) {
+	s.vals[s.next] = v
+	s.next = (s.next + 1) % len(s.vals)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// values returns the samples in chronological order.
+func (s *series) values() []float64 {
+	if !s.full {
+		return append([]float64(nil), s.vals[:s.next]...)
+	}
+	return append(append([]float64(nil), s.vals[s.next:]...), s.vals[:s.next]...)
+}
+
+// sparkline renders vals in at most width cells. When there are more samples
+// than cells each cell shows the peak of its bucket so short spikes stay
+// visible. max <= 0 scales to the largest value. Missing history on the left
+// is padded with spaces so the newest sample is always at the right edge.
+func sparkline(vals []float64, width int, max float64) string {
+	if width < 1 {
+		return ""
+	}
+	cells := vals
+	if len(vals) > width {
+		cells = make([]float64, width)
+		for i := range cells {
+			lo, hi := i*len(vals)/width, (i+1)*len(vals)/width
+			for _, v := range vals[lo:hi] {
+				if v > cells[i] {
+					cells[i] = v
+				}
+			}
+		}
+	}
+	if max <= 0 {
+		max = peak(cells)
+	}
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(cells)))
+	for _, v := range cells {
+		idx := 0
+		if max > 0 {
+			idx = int(v / max * float64(len(sparkLevels)-1))
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= len(sparkLevels) {
+			idx = len(sparkLevels) - 1
+		}
+		b.WriteRune(sparkLevels[idx])
+	}
+	return b.String()
+}
+
+// metricHistory holds the rolling windows drawn in graph mode.
+type metricHistory struct {
+	size                    int
+	cpu                     []*series
+	mem, swap, disk, rx, tx *series
+}
+
+func newMetricHistory(window, step time.Duration) *metricHistory {
+	n := int(window / step)
+	if n < 2 {
+		n = 2
+	}
+	return &metricHistory{size: n, mem: newSeries(n), swap: newSeries(n), disk: newSeries(n),
+		rx: newSeries(n), tx: newSeries(n)}
+}
+
+// width is the sparkline width: one cell per sample for short windows.
+func (h *metricHistory) width() int {
+	if h.size < sparkWidth {
+		return h.size
+	}
+	return sparkWidth
+}
+
+func (h *metricHistory) record(cpu []int, mem, swap, disk int, rx, tx float64) {
+	if len(h.cpu) != len(cpu) {
+		h.cpu = make([]*series, len(cpu))
+		for i := range h.cpu {
+			h.cpu[i] = newSeries(h.size)
+		}
+	}
+	for i, v := range cpu {
+		h.cpu[i].push(float64(v))
+	}
+	h.mem.push(float64(mem))
+	h.swap.push(float64(swap))
+	h.disk.push(float64(disk))
+	h.rx.push(rx)
+	h.tx.push(tx)
+}
+
+func peak(vals []float64) float64 {
+	var max float64
+	for _, v := range vals {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// reset drops all samples, keeping the window size.
+func (h *metricHistory) reset() {
+	n := h.size
+	*h = metricHistory{size: n, mem: newSeries(n), swap: newSeries(n), disk: newSeries(n),
+		rx: newSeries(n), tx: newSeries(n)}
+}
@@ -0,0 +1,208 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"syskit/internal/metrics"
+	"syskit/internal/theme"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type tab int
+
+const (
+	tabProcs tab = iota
+	tabDisk
+	tabNet
+	numTabs
+)
+
+var tabNames = [...]string{"CPU/MEM", "Disk", "Net"}
+
+type mountRow struct {
+	mount metrics.Mount
+	usage metrics.FSUsage
+}
+
+type diskRow struct {
+	name         string
+	rIOPS, wIOPS float64
+	rKBs, wKBs   float64
+	util         float64 // percent of wall time the device was busy
+}
+
+type netRow struct {
+	name           string
+	rxKBs, txKBs   float64
+	rxPkts, txPkts float64
+	rxErrs, txErrs uint64
+	rxDrop, txDrop uint64
+	dErrs, dDrop   uint64 // new errors / drops since the previous refresh
+}
+
+// ioState keeps the previous counters needed for per-device rates.
+type ioState struct {
+	at       time.Time
+	prevDisk map[string]metrics.DiskStats
+	prevNet  map[string]metrics.NetDev
+
+	mounts []mountRow
+	disks  []diskRow
+	nets   []netRow
+}
+
+func (s *ioState) refresh(fs metrics.FS, now time.Time) {
+	secs := now.Sub(s.at).Seconds()
+	first := s.at.IsZero() || secs <= 0
+	s.at = now
+
+	s.mounts = s.mounts[:0]
+	if ms, err := fs.Mounts(); err == nil {
+		seen := map[string]bool{}
+		for _, m := range ms {
+			if !m.IsDevice() || seen[m.MountPoint] {
+				continue
+			}
+			seen[m.MountPoint] = true
+			if du, err := metrics.DiskUsage(m.MountPoint); err == nil && du.Total > 0 {
+				s.mounts = append(s.mounts, mountRow{mount: m, usage: du})
+			}
+		}
+		sort.Slice(s.mounts, func(i, j int) bool { return s.mounts[i].mount.MountPoint < s.mounts[j].mount.MountPoint })
+	}
+
+	if ds, err := fs.DiskStats(); err == nil {
+		cur := map[string]metrics.DiskStats{}
+		s.disks = s.disks[:0]
+		for _, d := range ds {
+			if strings.HasPrefix(d.Name, "loop") || strings.HasPrefix(d.Name, "ram") {
+				continue
+			}
+			cur[d.Name] = d
+			row := diskRow{name: d.Name}
+			if p, ok := s.prevDisk[d.Name]; ok && !first {
+				row.rIOPS = rate(d.Reads, p.Reads, secs)
+				row.wIOPS = rate(d.Writes, p.Writes, secs)
+				row.rKBs = rate(d.ReadBytes(), p.ReadBytes(), secs) / 1024
+				row.wKBs = rate(d.WrittenBytes(), p.WrittenBytes(), secs) / 1024
+				row.util = rate(d.IOTimeMs, p.IOTimeMs, secs) / 10 // ms per s -> %
+				if row.util > 100 {
+					row.util = 100
+				}
+			}
+			s.disks = append(s.disks, row)
+		}
+		s.prevDisk = cur
+	}
+
+	if devs, err := fs.NetDev(); err == nil {
+		cur := map[string]metrics.NetDev{}
+		s.nets = s.nets[:0]
+		for _, d := range devs {
+			cur[d.Name] = d
+			row := netRow{name: d.Name, rxErrs: d.RxErrs, txErrs: d.TxErrs, rxDrop: d.RxDrop, txDrop: d.TxDrop}
+			if p, ok := s.prevNet[d.Name]; ok && !first {
+				row.rxKBs = rate(d.RxBytes, p.RxBytes, secs) / 1024
+				row.txKBs = rate(d.TxBytes, p.TxBytes, secs) / 1024
+				row.rxPkts = rate(d.RxPackets, p.RxPackets, secs)
+				row.txPkts = rate(d.TxPackets, p.TxPackets, secs)
+				row.dErrs = delta(d.RxErrs+d.TxErrs, p.RxErrs+p.TxErrs)
+				row.dDrop = delta(d.RxDrop+d.TxDrop, p.RxDrop+p.TxDrop)
+			}
+			s.nets = append(s.nets, row)
+		}
+		sort.Slice(s.nets, func(i, j int) bool { return s.nets[i].name < s.nets[j].name })
+		s.prevNet = cur
+	}
+}
+
+// delta tolerates counter resets (e.g. an interface going down).
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+func rate(cur, prev uint64, secs float64) float64 {
+	return float64(delta(cur, prev)) / secs
+}
+
+func renderTabBar(active tab) string {
+	th := theme.Current()
+	on := th.Selected().Padding(0, 1)
+	off := th.Fg(th.Muted).Padding(0, 1)
+	var parts []string
+	for i, name := range tabNames {
+		if tab(i) == active {
+			parts = append(parts, on.Render(name))
+		} else {
+			parts = append(parts, off.Render(name))
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+func (s *ioState) viewDisk(bar func(float64) string) string {
+	th := theme.Current()
+	title := th.Fg(th.Disk).Bold(true)
+	var b strings.Builder
+	b.WriteString(title.Render("Filesystems") + "\n")
+	fmt.Fprintf(&b, "%-24s %-16s %-6s %9s %9s  %s\n", "MOUNT", "DEVICE", "TYPE", "SIZE", "FREE", "USED")
+	for _, r := range s.mounts {
+		fmt.Fprintf(&b, "%-24s %-16s %-6s %9s %9s  %s\n", trunc(r.mount.MountPoint, 24), trunc(r.mount.Device, 16),
+			trunc(r.mount.FSType, 6), humanBytes(r.usage.Total), humanBytes(r.usage.Free), bar(r.usage.UsedPercent()/100))
+	}
+	b.WriteString("\n" + title.Render("Block devices") + "\n")
+	fmt.Fprintf(&b, "%-12s %8s %8s %10s %10s %6s\n", "DEVICE", "R/s", "W/s", "READ KB/s", "WRITE KB/s", "UTIL%")
+	for _, d := range s.disks {
+		fmt.Fprintf(&b, "%-12s %8.1f %8.1f %10.1f %10.1f %5.1f%%\n", trunc(d.name, 12), d.rIOPS, d.wIOPS, d.rKBs, d.wKBs, d.util)
+	}
+	return b.String()
+}
+
+func (s *ioState) viewNet() string {
+	th := theme.Current()
+	title := th.Fg(th.Net).Bold(true)
+	warn := th.Fg(th.Error).Bold(true)
+	var b strings.Builder
+	b.WriteString(title.Render("Interfaces") + "\n")
+	fmt.Fprintf(&b, "%-14s %10s %10s %9s %9s %8s %8s %8s %8s\n", "IFACE", "RX KB/s", "TX KB/s", "RX pkt/s", "TX pkt/s", "RX ERR", "TX ERR", "RX DROP", "TX DROP")
+	for _, n := range s.nets {
+		line := fmt.Sprintf("%-14s %10.1f %10.1f %9.0f %9.0f %8d %8d %8d %8d", trunc(n.name, 14), n.rxKBs, n.txKBs,
+			n.rxPkts, n.txPkts, n.rxErrs, n.txErrs, n.rxDrop, n.txDrop)
+		// highlight interfaces that are accumulating errors or drops right now
+		if n.dErrs > 0 || n.dDrop > 0 {
+			line = warn.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func trunc(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
+
+func humanBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,88 @@
+//go:build linux
+// +build linux
+
+package pulse
+
+import "sort"
+
+// buildTree orders list depth-first by parent PID, siblings sorted by less.
+// Subtrees of collapsed PIDs are hidden. When keep is non-nil only matching
+// processes and their ancestors are returned. Tree-drawing prefixes are set
+// on the returned copies.
+func buildTree(list []proc, less func(a, b proc) bool, collapsed map[int]bool, keep func(proc) bool) []proc {
+	byPID := make(map[int]bool, len(list))
+	for _, p := range list {
+		byPID[p.pid] = true
+	}
+	children := map[int][]proc{}
+	var roots []proc
+	for _, p := range list {
+		if p.ppid == 0 || p.ppid == p.pid || !byPID[p.ppid] {
+			roots = append(roots, p)
+		} else {
+			children[p.ppid] = append(children[p.ppid], p)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return less(roots[i], roots[j]) })
+	for _, kids := range children {
+		sort.Slice(kids, func(i, j int) bool { return less(kids[i], kids[j]) })
+	}
+
+	// visible[pid] is true if the process or one of its descendants matches
+	visible := map[int]bool{}
+	var mark func(p proc) bool
+	mark = func(p proc) bool {
+		v := keep == nil || keep(p)
+		for _, c := range children[p.pid] {
+			if mark(c) {
+				v = true
+			}
+		}
+		visible[p.pid] = v
+		return v
+	}
+	for _, r := range roots {
+		mark(r)
+	}
+
+	var out []proc
+	var walk func(p proc, indent string, last, root bool)
+	walk = func(p proc, indent string, last, root bool) {
+		if !visible[p.pid] {
+			return
+		}
+		var kids []proc
+		for _, c := range children[p.pid] {
+			if visible[c.pid] {
+				kids = append(kids, c)
+			}
+		}
+		branch, next := "", ""
+		if !root {
+			branch, next = "├─", "│ "
+			if last {
+				branch, next = "└─", "  "
+			}
+		}
+		marker := ""
+		if len(kids) > 0 {
+			marker = "▾ "
+			if collapsed[p.pid] {
+				marker = "▸ "
+			}
+		}
+		p.prefix = indent + branch + marker
+		p.children = len(kids)
+		out = append(out, p)
+		if collapsed[p.pid] {
+			return
+		}
+		for i, c := range kids {
+			walk(c, indent+next, i == len(kids)-1, false)
+		}
+	}
+	for _, r := range roots {
+		walk(r, "", true, true)
+	}
+	return out
+}
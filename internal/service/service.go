@@ -45,3 +45,21 @@ func List() ([]Unit, error) {
 func Control(name, action string) error {
     return exec.Command("systemctl", action, name).Run()
 }
+
+// UnitFiles returns the names of unit files of the given type (service,
+// timer, ...) in the given state, e.g. "enabled".
+func UnitFiles(unitType, state string) ([]string, error) {
+    cmd := exec.Command("systemctl", "list-unit-files", "--type="+unitType, "--state="+state, "--no-legend", "--no-pager")
+    out, err := cmd.Output()
+    if err != nil {
+        return nil, err
+    }
+    names := []string{}
+    for _, line := range strings.Split(string(out), "\n") {
+        // columns: UNIT FILE STATE [PRESET]
+        if fields := strings.Fields(line); len(fields) >= 2 {
+            names = append(names, fields[0])
+        }
+    }
+    return names, nil
+}
@@ -0,0 +1,63 @@
+package snapshot
+
+import "sort"
+
+// Change kinds.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is one difference between two snapshots.
+type Change struct {
+	Category string `json:"category" yaml:"category"`
+	Kind     string `json:"kind" yaml:"kind"`
+	Item     string `json:"item" yaml:"item"`
+	Old      string `json:"old,omitempty" yaml:"old,omitempty"`
+	New      string `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// Diff is the result of comparing snapshot From with To. Skipped lists the
+// categories that could not be captured on either side, with the reason.
+type Diff struct {
+	From    string            `json:"from" yaml:"from"`
+	To      string            `json:"to" yaml:"to"`
+	Changes []Change          `json:"changes" yaml:"changes"`
+	Skipped map[string]string `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+// Compare returns the changes from a to b, grouped by category in the
+// order of Categories and sorted by item within a category.
+func Compare(a, b *Snapshot) Diff {
+	d := Diff{From: a.Name, To: b.Name, Changes: []Change{}, Skipped: map[string]string{}}
+	for _, cat := range Categories {
+		if reason, ok := a.Errors[cat]; ok {
+			d.Skipped[cat] = a.Name + ": " + reason
+			continue
+		}
+		if reason, ok := b.Errors[cat]; ok {
+			d.Skipped[cat] = b.Name + ": " + reason
+			continue
+		}
+		old, cur := a.items(cat), b.items(cat)
+		var changes []Change
+		for k, v := range old {
+			nv, ok := cur[k]
+			switch {
+			case !ok:
+				changes = append(changes, Change{Category: cat, Kind: Removed, Item: k, Old: v})
+			case nv != v:
+				changes = append(changes, Change{Category: cat, Kind: Changed, Item: k, Old: v, New: nv})
+			}
+		}
+		for k, v := range cur {
+			if _, ok := old[k]; !ok {
+				changes = append(changes, Change{Category: cat, Kind: Added, Item: k, New: v})
+			}
+		}
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Item < changes[j].Item })
+		d.Changes = append(d.Changes, changes...)
+	}
+	return d
+}
@@ -0,0 +1,345 @@
+// Package snapshot captures the configuration-like state of a host (system
+// info, listening ports, services, scheduled jobs, accounts, containers,
+// kernel modules and sysctls) so that two captures can be compared to find
+// what changed.
+package snapshot
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"syskit/internal/container"
+	"syskit/internal/inventory"
+	"syskit/internal/netstat"
+	"syskit/internal/schedule"
+	"syskit/internal/service"
+)
+
+// Version is the snapshot file format version written by Save. Load
+// rejects files from a newer syskit.
+const Version = 1
+
+// Categories are the sections of a snapshot, in display order.
+var Categories = []string{"info", "ports", "services", "jobs", "users", "containers", "modules", "sysctls"}
+
+// Sysctls are the kernel parameters recorded by Capture.
+var Sysctls = []string{
+	"fs.file-max",
+	"fs.inotify.max_user_watches",
+	"kernel.dmesg_restrict",
+	"kernel.kptr_restrict",
+	"kernel.pid_max",
+	"kernel.randomize_va_space",
+	"kernel.unprivileged_bpf_disabled",
+	"net.core.somaxconn",
+	"net.ipv4.conf.all.rp_filter",
+	"net.ipv4.ip_forward",
+	"net.ipv4.tcp_congestion_control",
+	"net.ipv4.tcp_syncookies",
+	"net.ipv6.conf.all.disable_ipv6",
+	"net.ipv6.conf.all.forwarding",
+	"vm.dirty_ratio",
+	"vm.max_map_count",
+	"vm.overcommit_memory",
+	"vm.swappiness",
+}
+
+// Snapshot is one capture. Errors records, per category, why it could not
+// be captured (no systemctl, no docker, ...); such categories are skipped
+// when diffing so a missing tool does not look like everything vanished.
+type Snapshot struct {
+	Version    int                  `json:"version" yaml:"version"`
+	Name       string               `json:"name" yaml:"name"`
+	Taken      time.Time            `json:"taken" yaml:"taken"`
+	Info       inventory.SystemInfo `json:"info" yaml:"info"`
+	Ports      []Port               `json:"ports" yaml:"ports"`
+	Services   []Service            `json:"services" yaml:"services"`
+	Jobs       []Job                `json:"jobs" yaml:"jobs"`
+	Users      []Account            `json:"users" yaml:"users"`
+	Containers []Container          `json:"containers" yaml:"containers"`
+	Modules    []string             `json:"modules" yaml:"modules"`
+	Sysctls    map[string]string    `json:"sysctls" yaml:"sysctls"`
+	Errors     map[string]string    `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Port is a listening socket.
+type Port struct {
+	Proto   string `json:"proto" yaml:"proto"`
+	Local   string `json:"local" yaml:"local"`
+	Process string `json:"process,omitempty" yaml:"process,omitempty"`
+	User    string `json:"user,omitempty" yaml:"user,omitempty"`
+}
+
+// Service is a systemd service that is enabled, running, or both.
+type Service struct {
+	Name    string `json:"name" yaml:"name"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Active  string `json:"active" yaml:"active"`
+	Sub     string `json:"sub" yaml:"sub"`
+}
+
+// Job is a scheduled job: a crontab line or an enabled systemd timer.
+// Source is "crontab", a file under /etc/cron.d, or "timer".
+type Job struct {
+	Source string `json:"source" yaml:"source"`
+	Entry  string `json:"entry" yaml:"entry"`
+}
+
+// Account is a line of /etc/passwd.
+type Account struct {
+	Name  string `json:"name" yaml:"name"`
+	UID   string `json:"uid" yaml:"uid"`
+	GID   string `json:"gid" yaml:"gid"`
+	Home  string `json:"home" yaml:"home"`
+	Shell string `json:"shell" yaml:"shell"`
+}
+
+// Container is a running docker or podman container.
+type Container struct {
+	Name  string `json:"name" yaml:"name"`
+	ID    string `json:"id" yaml:"id"`
+	Image string `json:"image" yaml:"image"`
+}
+
+// Capture records the live state. It never fails as a whole; categories
+// that cannot be read are noted in Errors.
+func Capture() *Snapshot {
+	now := time.Now()
+	s := &Snapshot{
+		Version: Version,
+		Name:    now.Format("20060102-150405"),
+		Taken:   now,
+		Info:    inventory.Gather(false),
+		Errors:  map[string]string{},
+	}
+	fail := func(category string, err error) {
+		if err != nil {
+			s.Errors[category] = err.Error()
+		}
+	}
+	var err error
+	s.Ports, err = capturePorts()
+	fail("ports", err)
+	s.Services, err = captureServices()
+	fail("services", err)
+	s.Jobs = captureJobs()
+	s.Users, err = captureUsers()
+	fail("users", err)
+	s.Containers, err = captureContainers()
+	fail("containers", err)
+	s.Modules, err = captureModules(inventory.Default)
+	fail("modules", err)
+	s.Sysctls, err = captureSysctls(filepath.Join(inventory.Default.Proc, "sys"), Sysctls)
+	fail("sysctls", err)
+	return s
+}
+
+func captureModules(fs inventory.FS) ([]string, error) {
+	mods, err := fs.Modules()
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, m := range mods {
+		names = append(names, m.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// captureSysctls reads keys under root (/proc/sys). A key the kernel does
+// not have (e.g. net.ipv6.* with IPv6 disabled) is left out; any other
+// failure, or finding none of the keys, is an error.
+func captureSysctls(root string, keys []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, key := range keys {
+		b, err := os.ReadFile(filepath.Join(root, strings.ReplaceAll(key, ".", "/")))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return m, err
+		}
+		m[key] = strings.Join(strings.Fields(string(b)), " ")
+	}
+	if len(m) == 0 && len(keys) > 0 {
+		return m, fmt.Errorf("no sysctls found under %s", root)
+	}
+	return m, nil
+}
+
+func capturePorts() ([]Port, error) {
+	entries, err := netstat.List(netstat.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	ports := []Port{}
+	for _, e := range entries {
+		ports = append(ports, Port{Proto: e.Proto, Local: e.Local(), Process: e.Process, User: e.User})
+	}
+	return ports, nil
+}
+
+func captureServices() ([]Service, error) {
+	enabled, err := service.UnitFiles("service", "enabled")
+	if err != nil {
+		return nil, fmt.Errorf("systemctl list-unit-files: %w", err)
+	}
+	units, err := service.List()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl list-units: %w", err)
+	}
+	byName := map[string]*Service{}
+	for _, name := range enabled {
+		byName[name] = &Service{Name: name, Enabled: true, Active: "inactive", Sub: "dead"}
+	}
+	for _, u := range units {
+		if sv, ok := byName[u.Name]; ok {
+			sv.Active, sv.Sub = u.Active, u.Sub
+		} else if u.Active == "active" {
+			byName[u.Name] = &Service{Name: u.Name, Active: u.Active, Sub: u.Sub}
+		}
+	}
+	out := []Service{}
+	for _, sv := range byName {
+		out = append(out, *sv)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// captureJobs collects the user's crontab, /etc/crontab, /etc/cron.d and
+// enabled timers. Each source is optional, so none of them is an error.
+func captureJobs() []Job {
+	jobs := []Job{}
+	lines, _ := schedule.Read()
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+			jobs = append(jobs, Job{Source: "crontab", Entry: l})
+		}
+	}
+	files, _ := filepath.Glob("/etc/cron.d/*")
+	for _, path := range append([]string{"/etc/crontab"}, files...) {
+		jobs = append(jobs, cronFile(path)...)
+	}
+	if timers, err := service.UnitFiles("timer", "enabled"); err == nil {
+		for _, t := range timers {
+			jobs = append(jobs, Job{Source: "timer", Entry: t})
+		}
+	}
+	return jobs
+}
+
+func cronFile(path string) []Job {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var jobs []Job
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		jobs = append(jobs, Job{Source: path, Entry: l})
+	}
+	return jobs
+}
+
+func captureUsers() ([]Account, error) {
+	b, err := os.ReadFile("/etc/passwd")
+	if err != nil {
+		return nil, err
+	}
+	users := []Account{}
+	for _, line := range strings.Split(string(b), "\n") {
+		// name:password:uid:gid:gecos:home:shell
+		f := strings.Split(line, ":")
+		if len(f) != 7 || strings.HasPrefix(f[0], "#") {
+			continue
+		}
+		users = append(users, Account{Name: f[0], UID: f[2], GID: f[3], Home: f[5], Shell: f[6]})
+	}
+	return users, nil
+}
+
+func captureContainers() ([]Container, error) {
+	items, err := container.List()
+	if err != nil {
+		return nil, err
+	}
+	out := []Container{}
+	for _, it := range items {
+		out = append(out, Container{Name: it.Name, ID: it.ID, Image: it.Image})
+	}
+	return out, nil
+}
+
+// items flattens one category into key → value pairs for diffing. A key
+// identifies the item (a port, a service name); the value holds the
+// attributes whose change is reported as "changed".
+func (s *Snapshot) items(category string) map[string]string {
+	m := map[string]string{}
+	switch category {
+	case "info":
+		si := s.Info
+		m["hostname"], m["os"], m["kernel"] = si.Hostname, si.OS, si.Kernel
+		if si.CPU != nil {
+			m["cpu.model"] = si.CPU.Model
+			m["cpu.cores"] = fmt.Sprint(si.CPU.Cores)
+		}
+		if si.Memory != nil {
+			m["memory.total"] = fmt.Sprint(si.Memory.Total)
+			m["swap.total"] = fmt.Sprint(si.Memory.SwapTotal)
+		}
+		if si.Disk != nil {
+			m["disk.total"] = fmt.Sprint(si.Disk.Total)
+		}
+		if si.GPU != "" {
+			m["gpu"] = si.GPU
+		}
+		for _, it := range si.Interfaces {
+			m["iface."+it.Name] = strings.Join(it.Addrs, " ")
+		}
+	case "ports":
+		for _, p := range s.Ports {
+			m[p.Proto+" "+p.Local] = strings.TrimSpace(p.Process + " " + p.User)
+		}
+	case "services":
+		for _, sv := range s.Services {
+			state := sv.Active + "/" + sv.Sub
+			if sv.Enabled {
+				state = "enabled, " + state
+			}
+			m[sv.Name] = state
+		}
+	case "jobs":
+		for _, j := range s.Jobs {
+			m[j.Source+": "+j.Entry] = ""
+		}
+	case "users":
+		for _, u := range s.Users {
+			m[u.Name] = fmt.Sprintf("uid=%s gid=%s home=%s shell=%s", u.UID, u.GID, u.Home, u.Shell)
+		}
+	case "containers":
+		for _, c := range s.Containers {
+			m[c.Name] = c.Image
+		}
+	case "modules":
+		for _, name := range s.Modules {
+			m[name] = ""
+		}
+	case "sysctls":
+		for k, v := range s.Sysctls {
+			m[k] = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"syskit/internal/inventory"
+)
+
+func TestCompare(t *testing.T) {
+	a := &Snapshot{
+		Name:     "a",
+		Ports:    []Port{{Proto: "tcp", Local: "0.0.0.0:22", Process: "sshd"}, {Proto: "tcp", Local: "0.0.0.0:80", Process: "nginx"}},
+		Services: []Service{{Name: "cron.service", Enabled: true, Active: "active", Sub: "running"}},
+		Modules:  []string{"ext4", "kvm"},
+		Sysctls:  map[string]string{"vm.swappiness": "60"},
+		Errors:   map[string]string{"containers": "docker not found"},
+	}
+	b := &Snapshot{
+		Name:     "b",
+		Ports:    []Port{{Proto: "tcp", Local: "0.0.0.0:22", Process: "sshd"}, {Proto: "tcp", Local: "0.0.0.0:8080", Process: "java"}},
+		Services: []Service{{Name: "cron.service", Enabled: true, Active: "failed", Sub: "failed"}},
+		Modules:  []string{"ext4", "kvm", "wireguard"},
+		Sysctls:  map[string]string{"vm.swappiness": "10"},
+		Errors:   map[string]string{"users": "permission denied"},
+	}
+	d := Compare(a, b)
+	if d.From != "a" || d.To != "b" {
+		t.Errorf("From, To = %q, %q", d.From, d.To)
+	}
+	want := []Change{
+		{Category: "ports", Kind: Removed, Item: "tcp 0.0.0.0:80", Old: "nginx"},
+		{Category: "ports", Kind: Added, Item: "tcp 0.0.0.0:8080", New: "java"},
+		{Category: "services", Kind: Changed, Item: "cron.service", Old: "enabled, active/running", New: "enabled, failed/failed"},
+		{Category: "modules", Kind: Added, Item: "wireguard"},
+		{Category: "sysctls", Kind: Changed, Item: "vm.swappiness", Old: "60", New: "10"},
+	}
+	if !reflect.DeepEqual(d.Changes, want) {
+		t.Errorf("changes:\n got %+v\nwant %+v", d.Changes, want)
+	}
+	skipped := map[string]string{"containers": "a: docker not found", "users": "b: permission denied"}
+	if !reflect.DeepEqual(d.Skipped, skipped) {
+		t.Errorf("skipped = %v, want %v", d.Skipped, skipped)
+	}
+
+	if d := Compare(a, a); len(d.Changes) != 0 {
+		t.Errorf("comparing a snapshot with itself: %+v", d.Changes)
+	}
+}
+
+func TestSaveLoadList(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := List(dir); err != nil || len(got) != 0 {
+		t.Fatalf("List(empty) = %v, %v", got, err)
+	}
+	if _, err := Load("latest", dir); err == nil {
+		t.Error("Load(latest) succeeded on an empty dir")
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// saved out of order: List sorts by the time taken, not the name
+	for i, name := range []string{"second", "first", "third"} {
+		taken := base.Add(time.Duration([]int{1, 0, 2}[i]) * time.Hour)
+		s := &Snapshot{Version: Version, Name: name, Taken: taken, Modules: []string{name}}
+		if _, err := Save(s, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Save(&Snapshot{Version: Version, Name: "../escape"}, dir); err == nil {
+		t.Error("Save accepted a name with a slash")
+	}
+	os.WriteFile(filepath.Join(dir, "garbage.json"), []byte("{not json"), 0o644)
+	os.WriteFile(filepath.Join(dir, "other.json"), []byte(`{"name":"other"}`), 0o644)
+
+	list, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range list {
+		names = append(names, e.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"first", "second", "third"}) {
+		t.Errorf("List() names = %v", names)
+	}
+
+	for ref, want := range map[string]string{
+		"latest":                         "third",
+		"first":                          "first",
+		"second.json":                    "second",
+		filepath.Join(dir, "third.json"): "third",
+	} {
+		s, err := Load(ref, dir)
+		if err != nil {
+			t.Errorf("Load(%q): %v", ref, err)
+			continue
+		}
+		if s.Name != want || !reflect.DeepEqual(s.Modules, []string{want}) {
+			t.Errorf("Load(%q) = %s %v, want %s", ref, s.Name, s.Modules, want)
+		}
+	}
+	if _, err := Load("missing", dir); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+}
+
+func TestLoadVersion(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		body string
+		err  string
+	}{
+		{`{"version":1,"name":"ok"}`, ""},
+		{`{"name":"old"}`, "not a syskit snapshot"},
+		{`{"version":99,"name":"new"}`, "version 99 is newer than supported"},
+		{`{"version":`, "unexpected end"},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, string(rune('a'+i))+".json")
+		if err := os.WriteFile(path, []byte(tt.body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Load(path, dir)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: %v", tt.body, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error %v, want %q", tt.body, err, tt.err)
+		}
+	}
+}
+
+func TestCaptureModules(t *testing.T) {
+	proc := t.TempDir()
+	modules := "kvm 1138688 1 kvm_intel, Live 0x0000000000000000\n" +
+		"ext4 1007616 2 - Live 0x0000000000000000\n"
+	if err := os.WriteFile(filepath.Join(proc, "modules"), []byte(modules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := captureModules(inventory.FS{Proc: proc})
+	if err != nil || !reflect.DeepEqual(got, []string{"ext4", "kvm"}) {
+		t.Errorf("captureModules = %v, %v", got, err)
+	}
+	if _, err := captureModules(inventory.FS{Proc: filepath.Join(proc, "none")}); err == nil {
+		t.Error("captureModules succeeded without /proc/modules")
+	}
+}
+
+func TestCaptureSysctls(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "vm"), 0o755)
+	os.WriteFile(filepath.Join(root, "vm", "swappiness"), []byte("60\n"), 0o644)
+	os.MkdirAll(filepath.Join(root, "net", "ipv4", "tcp_rmem"), 0o755) // unreadable as a file
+
+	got, err := captureSysctls(root, []string{"vm.swappiness", "net.ipv6.conf.all.forwarding"})
+	if err != nil || !reflect.DeepEqual(got, map[string]string{"vm.swappiness": "60"}) {
+		t.Errorf("missing key: %v, %v", got, err)
+	}
+	if _, err := captureSysctls(root, []string{"vm.swappiness", "net.ipv4.tcp_rmem"}); err == nil {
+		t.Error("read error not reported")
+	}
+	if _, err := captureSysctls(filepath.Join(root, "none"), Sysctls); err == nil {
+		t.Error("no /proc/sys not reported")
+	}
+}
@@ -0,0 +1,104 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDir returns ~/.syskit/snapshots.
+func DefaultDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".syskit", "snapshots")
+}
+
+// Save writes s to dir as <name>.json and returns the path.
+func Save(s *Snapshot, dir string) (string, error) {
+	if s.Name == "" || strings.ContainsAny(s.Name, `/\`) {
+		return "", fmt.Errorf("invalid snapshot name %q", s.Name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, s.Name+".json")
+	return path, os.WriteFile(path, b, 0o644)
+}
+
+// Load reads a snapshot. ref is a file path, a name saved in dir, or
+// "latest" for the most recent snapshot in dir.
+func Load(ref, dir string) (*Snapshot, error) {
+	path := ref
+	if ref == "latest" {
+		list, err := List(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, fmt.Errorf("no snapshots in %s", dir)
+		}
+		path = list[len(list)-1].Path
+	} else if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(dir, strings.TrimSuffix(ref, ".json")+".json")
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %q not found", ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch {
+	case s.Version == 0:
+		return nil, fmt.Errorf("%s: not a syskit snapshot", path)
+	case s.Version > Version:
+		return nil, fmt.Errorf("%s: snapshot version %d is newer than supported (%d)", path, s.Version, Version)
+	}
+	return &s, nil
+}
+
+// Entry describes a saved snapshot.
+type Entry struct {
+	Name  string    `json:"name" yaml:"name"`
+	Taken time.Time `json:"taken" yaml:"taken"`
+	Path  string    `json:"path" yaml:"path"`
+}
+
+// List returns the snapshots in dir, oldest first. Unreadable files are
+// skipped.
+func List(dir string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	out := []Entry{}
+	for _, path := range files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var head struct {
+			Version int       `json:"version"`
+			Name    string    `json:"name"`
+			Taken   time.Time `json:"taken"`
+		}
+		if json.Unmarshal(b, &head) != nil || head.Version == 0 {
+			continue
+		}
+		out = append(out, Entry{Name: head.Name, Taken: head.Taken, Path: path})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Taken.Before(out[j].Taken) })
+	return out, nil
+}
@@ -0,0 +1,289 @@
+// Package theme holds the color schemes shared by every TUI screen and the
+// help output.
+package theme
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"syskit/internal/config"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+	"github.com/muesli/termenv"
+	"github.com/rivo/tview"
+)
+
+// Theme is a named set of colors. Each color is an ANSI 256 palette number
+// ("205") or a hex value ("#268bd2"); empty means the terminal default.
+// The progress bar gradient (BarFrom, BarTo) must be hex.
+type Theme struct {
+	Name       string
+	Title      string // headings and header text
+	HeaderBg   string // header bar background
+	Accent     string // command names, borders
+	SelectedFg string
+	SelectedBg string
+	Muted      string // hints, inactive tabs, logs
+	Error      string // alerts and failures
+	Highlight  string // search input, section markers
+	CPU        string
+	Mem        string
+	Swap       string
+	Disk       string
+	Net        string
+	BarFrom    string
+	BarTo      string
+	// NoColor drops all colors; selection is shown in reverse video.
+	NoColor bool
+}
+
+// Default is used when nothing else is configured.
+const Default = "default"
+
+var builtin = map[string]Theme{
+	"default": {
+		Title: "205", HeaderBg: "236", Accent: "69", SelectedFg: "229", SelectedBg: "57",
+		Muted: "245", Error: "160", Highlight: "220",
+		CPU: "39", Mem: "45", Swap: "44", Disk: "99", Net: "81",
+		BarFrom: "#5A56E0", BarTo: "#EE6FF8",
+	},
+	"high-contrast": {
+		Title: "226", HeaderBg: "16", Accent: "51", SelectedFg: "16", SelectedBg: "226",
+		Muted: "252", Error: "196", Highlight: "226",
+		CPU: "51", Mem: "46", Swap: "48", Disk: "201", Net: "87",
+		BarFrom: "#00FF00", BarTo: "#FFFF00",
+	},
+	"solarized": {
+		Title: "#d33682", HeaderBg: "#073642", Accent: "#268bd2", SelectedFg: "#fdf6e3", SelectedBg: "#268bd2",
+		Muted: "#586e75", Error: "#dc322f", Highlight: "#b58900",
+		CPU: "#268bd2", Mem: "#2aa198", Swap: "#859900", Disk: "#6c71c4", Net: "#cb4b16",
+		BarFrom: "#268bd2", BarTo: "#2aa198",
+	},
+	"monochrome": {NoColor: true},
+}
+
+// roles maps the YAML keys of user themes to Theme fields.
+var roles = map[string]func(*Theme) *string{
+	"title":       func(t *Theme) *string { return &t.Title },
+	"header_bg":   func(t *Theme) *string { return &t.HeaderBg },
+	"accent":      func(t *Theme) *string { return &t.Accent },
+	"selected_fg": func(t *Theme) *string { return &t.SelectedFg },
+	"selected_bg": func(t *Theme) *string { return &t.SelectedBg },
+	"muted":       func(t *Theme) *string { return &t.Muted },
+	"error":       func(t *Theme) *string { return &t.Error },
+	"highlight":   func(t *Theme) *string { return &t.Highlight },
+	"cpu":         func(t *Theme) *string { return &t.CPU },
+	"mem":         func(t *Theme) *string { return &t.Mem },
+	"swap":        func(t *Theme) *string { return &t.Swap },
+	"disk":        func(t *Theme) *string { return &t.Disk },
+	"net":         func(t *Theme) *string { return &t.Net },
+	"bar_from":    func(t *Theme) *string { return &t.BarFrom },
+	"bar_to":      func(t *Theme) *string { return &t.BarTo },
+}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func validColor(c string) bool {
+	if c == "" || hexColor.MatchString(c) {
+		return true
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n < 256
+}
+
+// Names lists the built-in themes followed by the user themes of cfg.
+func Names(cfg *config.Config) []string {
+	var names []string
+	for n := range builtin {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, d := range cfg.Themes {
+		if _, ok := builtin[d.Name]; !ok {
+			names = append(names, d.Name)
+		}
+	}
+	return names
+}
+
+// Get resolves name against the user themes of cfg and the built-ins. A
+// user theme starts from its base (default if unset) and overrides the
+// colors it lists; it may shadow a built-in of the same name.
+func Get(name string, cfg *config.Config) (Theme, error) {
+	return get(name, cfg, map[string]bool{})
+}
+
+func get(name string, cfg *config.Config, seen map[string]bool) (Theme, error) {
+	for _, d := range cfg.Themes {
+		if d.Name != name || seen[name] {
+			continue
+		}
+		seen[name] = true
+		base := d.Base
+		if base == "" {
+			base = Default
+		}
+		t, err := get(base, cfg, seen)
+		if err != nil {
+			return Theme{}, fmt.Errorf("theme %s: %w", name, err)
+		}
+		t.Name = name
+		for key, c := range d.Colors {
+			field, ok := roles[key]
+			if !ok {
+				return Theme{}, fmt.Errorf("theme %s: unknown color %q", name, key)
+			}
+			if !validColor(c) || ((key == "bar_from" || key == "bar_to") && c != "" && !hexColor.MatchString(c)) {
+				return Theme{}, fmt.Errorf("theme %s: invalid %s color %q", name, key, c)
+			}
+			*field(&t) = c
+		}
+		return t, nil
+	}
+	if t, ok := builtin[name]; ok {
+		t.Name = name
+		return t, nil
+	}
+	if seen[name] {
+		return Theme{}, fmt.Errorf("theme %s is its own base", name)
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(Names(cfg), ", "))
+}
+
+var current *Theme
+
+// Use selects the theme for this process: name (from --theme) if set,
+// otherwise monochrome when NO_COLOR is set, otherwise the theme key of
+// config.yaml, otherwise Default. On error the default theme stays active.
+func Use(name string) error {
+	cfg := config.Load()
+	if name == "" {
+		switch {
+		case os.Getenv("NO_COLOR") != "":
+			name = "monochrome"
+		case cfg.Theme != "":
+			name = cfg.Theme
+		default:
+			name = Default
+		}
+	}
+	t, err := Get(name, cfg)
+	if err != nil {
+		t, _ = Get(Default, cfg)
+	}
+	set(t)
+	return err
+}
+
+func set(t Theme) {
+	current = &t
+	if t.NoColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	t.applyTview()
+}
+
+// Current returns the active theme, resolving it from the environment and
+// config.yaml on first use.
+func Current() Theme {
+	if current == nil {
+		_ = Use("")
+	}
+	return *current
+}
+
+// Color converts c for lipgloss.
+func (t Theme) Color(c string) lipgloss.TerminalColor {
+	if t.NoColor || c == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(c)
+}
+
+// Fg is a style with foreground c.
+func (t Theme) Fg(c string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(t.Color(c))
+}
+
+// Heading is bold title text.
+func (t Theme) Heading() lipgloss.Style {
+	return t.Fg(t.Title).Bold(true)
+}
+
+// Header is the bar at the top of a dashboard.
+func (t Theme) Header() lipgloss.Style {
+	return t.Heading().Background(t.Color(t.HeaderBg))
+}
+
+// Selected highlights the current row or tab.
+func (t Theme) Selected() lipgloss.Style {
+	if t.NoColor {
+		return lipgloss.NewStyle().Reverse(true).Bold(true)
+	}
+	return t.Fg(t.SelectedFg).Background(t.Color(t.SelectedBg)).Bold(true)
+}
+
+// Progress returns a progress bar in the theme's gradient.
+func (t Theme) Progress(opts ...progress.Option) progress.Model {
+	switch {
+	case t.NoColor:
+		opts = append(opts, progress.WithColorProfile(termenv.Ascii))
+	case t.BarFrom != "" && t.BarTo != "":
+		opts = append(opts, progress.WithGradient(t.BarFrom, t.BarTo))
+	case t.BarFrom != "":
+		opts = append(opts, progress.WithSolidFill(t.BarFrom))
+	default:
+		opts = append(opts, progress.WithDefaultGradient())
+	}
+	return progress.New(opts...)
+}
+
+// Tag is c as a tview color tag, e.g. "[#d33682]"; "[-]" resets.
+func (t Theme) Tag(c string) string {
+	if t.NoColor || c == "" {
+		return "[-]"
+	}
+	return fmt.Sprintf("[#%06x]", tcellColor(c).Hex())
+}
+
+// TviewSelected is the style of the selected list item.
+func (t Theme) TviewSelected() tcell.Style {
+	if t.NoColor {
+		return tcell.StyleDefault.Reverse(true)
+	}
+	return tcell.StyleDefault.Foreground(tcellColor(t.SelectedFg)).Background(tcellColor(t.SelectedBg))
+}
+
+func tcellColor(c string) tcell.Color {
+	if n, err := strconv.Atoi(c); err == nil {
+		return tcell.PaletteColor(n)
+	}
+	return tcell.GetColor(c)
+}
+
+// applyTview sets the global tview styles used by new primitives.
+func (t Theme) applyTview() {
+	if t.NoColor {
+		tview.Styles.PrimitiveBackgroundColor = tcell.ColorDefault
+		tview.Styles.ContrastBackgroundColor = tcell.ColorDefault
+		tview.Styles.MoreContrastBackgroundColor = tcell.ColorDefault
+		tview.Styles.BorderColor = tcell.ColorDefault
+		tview.Styles.TitleColor = tcell.ColorDefault
+		tview.Styles.GraphicsColor = tcell.ColorDefault
+		tview.Styles.PrimaryTextColor = tcell.ColorDefault
+		tview.Styles.SecondaryTextColor = tcell.ColorDefault
+		tview.Styles.TertiaryTextColor = tcell.ColorDefault
+		return
+	}
+	tview.Styles.BorderColor = tcellColor(t.Accent)
+	tview.Styles.TitleColor = tcellColor(t.Title)
+	tview.Styles.GraphicsColor = tcellColor(t.Accent)
+	tview.Styles.SecondaryTextColor = tcellColor(t.Highlight)
+	tview.Styles.TertiaryTextColor = tcellColor(t.Muted)
+}
@@ -0,0 +1,76 @@
+package timeline
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// AuthLog reads syslog-formatted authentication logs. The first path that
+// exists is used (Debian uses auth.log, RHEL uses secure).
+type AuthLog struct {
+	Paths []string
+}
+
+func (a *AuthLog) Name() string { return "auth" }
+
+func (a *AuthLog) Collect(since, until time.Time) ([]Event, error) {
+	for _, p := range a.Paths {
+		f, err := os.Open(p)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseSyslog(f, "auth", time.Now(), since, until)
+	}
+	return nil, nil
+}
+
+// ParseSyslog parses classic "Jan  2 15:04:05 host prog[pid]: msg" lines as
+// well as RFC 3339 prefixed lines written by newer rsyslog defaults. Classic
+// timestamps carry no year, so the year of now is assumed and entries that
+// would land in the future are moved to the previous year.
+func ParseSyslog(r io.Reader, src string, now, since, until time.Time) ([]Event, error) {
+	var evs []Event
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		when, rest, ok := syslogTime(sc.Text(), now)
+		if !ok || !inWindow(when, since, until) {
+			continue
+		}
+		// drop the hostname column
+		if i := strings.IndexByte(rest, ' '); i != -1 {
+			rest = rest[i+1:]
+		}
+		evs = append(evs, Event{When: when, Src: src, Msg: rest})
+	}
+	return evs, sc.Err()
+}
+
+func syslogTime(line string, now time.Time) (time.Time, string, bool) {
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			return t, line[i+1:], true
+		}
+	}
+	const layout = "Jan _2 15:04:05"
+	if len(line) <= len(layout) {
+		return time.Time{}, "", false
+	}
+	t, err := time.ParseInLocation(layout, line[:len(layout)], now.Location())
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.After(now.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t, strings.TrimLeft(line[len(layout):], " "), true
+}
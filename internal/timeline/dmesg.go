@@ -0,0 +1,74 @@
+package timeline
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"syskit/internal/metrics"
+)
+
+// Dmesg reads the kernel ring buffer in /dev/kmsg record format
+// ("prio,seq,usec,flags;message"). Timestamps are microseconds since boot,
+// converted with Boot (read from /proc/stat btime when zero).
+type Dmesg struct {
+	Path string
+	Boot time.Time
+}
+
+func (d *Dmesg) Name() string { return "dmesg" }
+
+func (d *Dmesg) Collect(since, until time.Time) ([]Event, error) {
+	data, err := readKmsg(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	boot := d.Boot
+	if boot.IsZero() {
+		boot = bootTime()
+	}
+	return ParseKmsg(bytes.NewReader(data), boot, since, until)
+}
+
+// ParseKmsg decodes kmsg records. Continuation lines (starting with a space)
+// carry key/value metadata and are skipped.
+func ParseKmsg(r io.Reader, boot time.Time, since, until time.Time) ([]Event, error) {
+	var evs []Event
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == ' ' {
+			continue
+		}
+		semi := strings.IndexByte(line, ';')
+		if semi == -1 {
+			continue
+		}
+		hdr := strings.Split(line[:semi], ",")
+		if len(hdr) < 3 {
+			continue
+		}
+		usec, err := strconv.ParseInt(hdr[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		when := boot.Add(time.Duration(usec) * time.Microsecond)
+		if !inWindow(when, since, until) {
+			continue
+		}
+		evs = append(evs, Event{When: when, Src: "dmesg", Msg: line[semi+1:]})
+	}
+	return evs, sc.Err()
+}
+
+func bootTime() time.Time {
+	st, err := metrics.Default.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+	return st.BootTime
+}
@@ -0,0 +1,120 @@
+package timeline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Journal reads systemd journal entries in export format
+// (journalctl -o export). If Path is set the export is read from that file,
+// otherwise journalctl is executed for the requested window.
+type Journal struct {
+	Path string
+}
+
+func (j *Journal) Name() string { return "journal" }
+
+func (j *Journal) Collect(since, until time.Time) ([]Event, error) {
+	if j.Path != "" {
+		f, err := os.Open(j.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseJournalExport(f, since, until)
+	}
+	bin, err := exec.LookPath("journalctl")
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"-o", "export", "--no-pager"}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=@%d", since.Unix()))
+	}
+	if !until.IsZero() {
+		args = append(args, fmt.Sprintf("--until=@%d", until.Unix()))
+	}
+	out, err := exec.Command(bin, args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return ParseJournalExport(bytes.NewReader(out), since, until)
+}
+
+// ParseJournalExport decodes the journal export format: one KEY=value field
+// per line, binary fields as KEY\n<le64 size><data>\n, entries separated by
+// an empty line.
+func ParseJournalExport(r io.Reader, since, until time.Time) ([]Event, error) {
+	br := bufio.NewReader(r)
+	var evs []Event
+	fields := map[string]string{}
+	flush := func() {
+		if len(fields) == 0 {
+			return
+		}
+		if e, ok := journalEvent(fields); ok && inWindow(e.When, since, until) {
+			evs = append(evs, e)
+		}
+		fields = map[string]string{}
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return evs, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			flush()
+		case strings.Contains(line, "="):
+			kv := strings.SplitN(line, "=", 2)
+			fields[kv[0]] = kv[1]
+		default:
+			// binary field: name line followed by little-endian size and data
+			var size uint64
+			if e := binary.Read(br, binary.LittleEndian, &size); e != nil {
+				return evs, fmt.Errorf("field %s: %w", line, e)
+			}
+			data := make([]byte, size)
+			if _, e := io.ReadFull(br, data); e != nil {
+				return evs, fmt.Errorf("field %s: %w", line, e)
+			}
+			br.ReadByte() // trailing newline
+			fields[line] = string(data)
+		}
+		if err == io.EOF {
+			flush()
+			return evs, nil
+		}
+	}
+}
+
+func journalEvent(f map[string]string) (Event, bool) {
+	usec, err := strconv.ParseInt(f["__REALTIME_TIMESTAMP"], 10, 64)
+	if err != nil || f["MESSAGE"] == "" {
+		return Event{}, false
+	}
+	e := Event{When: time.UnixMicro(usec), Src: "journal", Msg: f["MESSAGE"]}
+	switch {
+	case f["_TRANSPORT"] == "kernel":
+		e.Src = "dmesg"
+		return e, true
+	case f["SYSLOG_FACILITY"] == "4" || f["SYSLOG_FACILITY"] == "10":
+		e.Src = "auth"
+	}
+	if id := f["SYSLOG_IDENTIFIER"]; id != "" {
+		if pid := f["SYSLOG_PID"]; pid != "" {
+			id += "[" + pid + "]"
+		}
+		e.Msg = id + ": " + e.Msg
+	}
+	return e, true
+}
@@ -0,0 +1,39 @@
+//go:build linux
+// +build linux
+
+package timeline
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// readKmsg drains a kmsg-style file. /dev/kmsg returns one record per read
+// and EAGAIN once the buffer is exhausted, so it is read with raw
+// non-blocking syscalls; regular fixture files simply hit EOF.
+func readKmsg(path string) ([]byte, error) {
+	fd, err := syscall.Open(path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+	var out bytes.Buffer
+	buf := make([]byte, 8192)
+	for {
+		n, err := syscall.Read(fd, buf)
+		if err == syscall.EAGAIN {
+			return out.Bytes(), nil
+		}
+		if err == syscall.EPIPE {
+			// record overwritten while reading; continue with the next one
+			continue
+		}
+		if err != nil {
+			return out.Bytes(), err
+		}
+		if n == 0 {
+			return out.Bytes(), nil
+		}
+		out.Write(buf[:n])
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package timeline
+
+import "os"
+
+func readKmsg(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
@@ -0,0 +1,85 @@
+package timeline
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// PackageLog reads package manager history: dpkg.log on Debian and
+// dnf.rpm.log on Fedora/RHEL. Every path that exists is read.
+type PackageLog struct {
+	Paths []string
+}
+
+func (p *PackageLog) Name() string { return "pkg" }
+
+func (p *PackageLog) Collect(since, until time.Time) ([]Event, error) {
+	var evs []Event
+	for _, path := range p.Paths {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return evs, err
+		}
+		more, err := ParsePackageLog(f, time.Local, since, until)
+		f.Close()
+		evs = append(evs, more...)
+		if err != nil {
+			return evs, err
+		}
+	}
+	return evs, nil
+}
+
+// dpkgActions are the dpkg.log actions reported; status and trigger lines
+// repeat each of them several times.
+var dpkgActions = map[string]string{"install": "installed", "upgrade": "upgraded", "remove": "removed", "purge": "purged"}
+
+// ParsePackageLog parses dpkg.log lines ("2024-03-01 10:00:00 upgrade
+// pkg:amd64 1.0 1.1") and dnf.rpm.log lines ("2024-03-01T10:00:00+0000
+// SUBDEBUG Upgrade: pkg-1.1.x86_64"). dpkg times are local, in loc.
+func ParsePackageLog(r io.Reader, loc *time.Location, since, until time.Time) ([]Event, error) {
+	var evs []Event
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		var e Event
+		switch {
+		case len(f) >= 6 && dpkgActions[f[2]] != "":
+			t, err := time.ParseInLocation("2006-01-02 15:04:05", f[0]+" "+f[1], loc)
+			if err != nil {
+				continue
+			}
+			// action package old-version new-version
+			msg := dpkgActions[f[2]] + " " + f[3]
+			switch f[2] {
+			case "upgrade":
+				msg += " " + f[4] + " -> " + f[5]
+			case "install":
+				msg += " " + f[5]
+			default:
+				msg += " " + f[4]
+			}
+			e = Event{When: t, Msg: msg}
+		case len(f) >= 4 && f[1] == "SUBDEBUG" && strings.HasSuffix(f[2], ":"):
+			t, err := time.Parse("2006-01-02T15:04:05-0700", f[0])
+			if err != nil {
+				continue
+			}
+			e = Event{When: t, Msg: strings.ToLower(strings.TrimSuffix(f[2], ":")) + " " + f[3]}
+		default:
+			continue
+		}
+		if inWindow(e.When, since, until) {
+			e.Src = "pkg"
+			evs = append(evs, e)
+		}
+	}
+	return evs, sc.Err()
+}
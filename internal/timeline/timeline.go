@@ -0,0 +1,90 @@
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Event is a single entry on the system timeline.
+type Event struct {
+	When time.Time
+	Src  string // auth | dmesg | journal | wtmp | pkg
+	Msg  string
+}
+
+// Collector reads events from one log source.
+type Collector interface {
+	Name() string
+	Collect(since, until time.Time) ([]Event, error)
+}
+
+// Default returns collectors for the standard Linux log locations.
+func Default() []Collector {
+	return []Collector{
+		&Journal{},
+		&AuthLog{Paths: []string{"/var/log/auth.log", "/var/log/secure"}},
+		&Dmesg{Path: "/dev/kmsg"},
+		&Wtmp{Path: "/var/log/wtmp"},
+		&PackageLog{Paths: []string{"/var/log/dpkg.log", "/var/log/dnf.rpm.log"}},
+	}
+}
+
+// kernelSkew is how far the same kernel message may be apart when read from
+// the journal and from /dev/kmsg: kmsg times are offset from btime, which
+// has one-second precision.
+const kernelSkew = time.Second
+
+// Gather runs every collector, merges the results chronologically and drops
+// duplicates (the journal usually mirrors auth.log and the kernel ring buffer).
+// Collector errors are returned alongside whatever could be read.
+func Gather(cs []Collector, since, until time.Time) ([]Event, []error) {
+	var all []Event
+	var errs []error
+	seen := map[string]bool{}
+	kernel := map[string][]time.Time{} // dmesg message -> times seen
+	for _, c := range cs {
+		evs, err := c.Collect(since, until)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name(), err))
+		}
+		for _, e := range evs {
+			if e.Src == "dmesg" {
+				if nearAny(kernel[e.Msg], e.When, kernelSkew) {
+					continue
+				}
+				kernel[e.Msg] = append(kernel[e.Msg], e.When)
+				all = append(all, e)
+				continue
+			}
+			key := fmt.Sprintf("%s|%d|%s", e.Src, e.When.Unix(), e.Msg)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			all = append(all, e)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].When.Before(all[j].When) })
+	return all, errs
+}
+
+// nearAny reports whether t is within d of one of times.
+func nearAny(times []time.Time, t time.Time, d time.Duration) bool {
+	for _, u := range times {
+		if diff := t.Sub(u); diff <= d && diff >= -d {
+			return true
+		}
+	}
+	return false
+}
+
+func inWindow(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && t.After(until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package timeline
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"syskit/internal/utmp"
+)
+
+// t0 is 2024-03-01 08:00:00 UTC, the boot time used by the fixtures.
+var t0 = time.Unix(1709280000, 0).UTC()
+
+func at(sec float64) time.Time {
+	return t0.Add(time.Duration(sec * float64(time.Second)))
+}
+
+func open(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestParsers(t *testing.T) {
+	now := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		parse func(t *testing.T) ([]Event, error)
+		want  []Event
+	}{
+		{
+			name: "journal export",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseJournalExport(open(t, "journal.export"), time.Time{}, at(3700))
+			},
+			want: []Event{
+				{at(3600), "auth", "sshd[812]: Accepted publickey for alice from 192.0.2.7 port 50022 ssh2"},
+				{at(3610.5), "dmesg", "usb 1-1: new high-speed USB device number 2 using xhci_hcd"},
+				{at(3620), "journal", "backup.sh: line one\nline two"},
+				{at(3630), "journal", "systemd: Started Daily apt download activities."},
+			},
+		},
+		{
+			name: "journal export since",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseJournalExport(open(t, "journal.export"), at(3625), time.Time{})
+			},
+			want: []Event{
+				{at(3630), "journal", "systemd: Started Daily apt download activities."},
+				{at(10000), "journal", "cron[99]: late entry"},
+			},
+		},
+		{
+			name: "kmsg",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseKmsg(open(t, "kmsg"), t0, time.Time{}, time.Time{})
+			},
+			want: []Event{
+				{at(5.123), "dmesg", "usb 1-1: new high-speed USB device number 2 using xhci_hcd"},
+				{at(9), "dmesg", "EXT4-fs error (device sda1): ext4_find_entry:1455: comm ls: reading directory"},
+				{at(12), "dmesg", "CPU1: Core temperature above threshold, cpu clock throttled"},
+			},
+		},
+		{
+			name: "kmsg window",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseKmsg(open(t, "kmsg"), t0, at(6), at(10))
+			},
+			want: []Event{
+				{at(9), "dmesg", "EXT4-fs error (device sda1): ext4_find_entry:1455: comm ls: reading directory"},
+			},
+		},
+		{
+			name: "auth.log classic timestamps",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseSyslog(open(t, "auth.log"), "auth", now, time.Time{}, time.Time{})
+			},
+			want: []Event{
+				{at(3600), "auth", "sshd[812]: Accepted publickey for alice from 192.0.2.7 port 50022 ssh2"},
+				{at(3912), "auth", "sshd[901]: Failed password for root from 203.0.113.9 port 40112 ssh2"},
+				{at(3915), "auth", "sudo:    alice : TTY=pts/0 ; PWD=/home/alice ; USER=root ; COMMAND=/usr/bin/apt update"},
+				// December lies in the future, so it belongs to the previous year
+				{time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC), "auth", "CRON[77]: pam_unix(cron:session): session opened for user root"},
+			},
+		},
+		{
+			name: "secure RFC 3339 timestamps",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParseSyslog(open(t, "secure"), "auth", now, time.Time{}, time.Time{})
+			},
+			want: []Event{
+				{at(3600.123456), "auth", "sshd[812]: Accepted password for bob from 192.0.2.8 port 50100 ssh2"},
+				{at(4200), "auth", "su[1022]: pam_unix(su:session): session opened for user root"},
+			},
+		},
+		{
+			name: "wtmp boot and shutdown",
+			parse: func(t *testing.T) ([]Event, error) {
+				recs, err := utmp.Parse(open(t, "wtmp"))
+				return WtmpEvents(recs, time.Time{}, time.Time{}), err
+			},
+			want: []Event{
+				{at(0), "wtmp", "system boot (6.1.0-18-amd64)"},
+				{at(10800), "wtmp", "system shutdown (6.1.0-18-amd64)"},
+				{at(11000), "wtmp", "system boot (6.1.0-21-amd64)"},
+			},
+		},
+		{
+			name: "dpkg.log",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParsePackageLog(open(t, "dpkg.log"), time.UTC, time.Time{}, time.Time{})
+			},
+			want: []Event{
+				{at(7201), "pkg", "installed libssl3:amd64 3.0.11-1"},
+				{at(7202), "pkg", "upgraded openssh-server:amd64 1:9.2p1-2 -> 1:9.2p1-2+deb12u2"},
+				{at(7203), "pkg", "removed telnet:amd64 0.17+2.4-2"},
+				{at(7204), "pkg", "purged telnet:amd64 0.17+2.4-2"},
+			},
+		},
+		{
+			name: "dnf.rpm.log",
+			parse: func(t *testing.T) ([]Event, error) {
+				return ParsePackageLog(open(t, "dnf.rpm.log"), time.UTC, time.Time{}, time.Time{})
+			},
+			want: []Event{
+				{at(7200), "pkg", "upgrade kernel-core-6.7.6-200.fc39.x86_64"},
+				{at(7201), "pkg", "installed htop-3.3.0-1.fc39.x86_64"},
+				{at(7203), "pkg", "erase nano-7.2-5.fc39.x86_64"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse(t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if !g.When.Equal(w.When) || g.Src != w.Src || g.Msg != w.Msg {
+					t.Errorf("event %d:\n got %v %s %q\nwant %v %s %q", i, g.When.UTC(), g.Src, g.Msg, w.When, w.Src, w.Msg)
+				}
+			}
+		})
+	}
+}
+
+// fixed is a collector returning canned events.
+type fixed struct {
+	name string
+	evs  []Event
+}
+
+func (f fixed) Name() string                                    { return f.name }
+func (f fixed) Collect(since, until time.Time) ([]Event, error) { return f.evs, nil }
+
+func TestGatherDedup(t *testing.T) {
+	journal := fixed{"journal", []Event{
+		{at(10.9), "dmesg", "eth0: link up"},
+		{at(20), "auth", "sshd[1]: Accepted publickey for alice"},
+		{at(30), "dmesg", "eth0: link down"},
+	}}
+	kmsg := fixed{"dmesg", []Event{
+		{at(10.2), "dmesg", "eth0: link up"},   // same message, btime rounding
+		{at(30.8), "dmesg", "eth0: link down"}, // same
+		{at(40), "dmesg", "eth0: link up"},     // a later, distinct occurrence
+	}}
+	auth := fixed{"auth", []Event{
+		{at(20), "auth", "sshd[1]: Accepted publickey for alice"},
+		{at(21), "auth", "sshd[1]: Accepted publickey for alice"},
+	}}
+	got, errs := Gather([]Collector{journal, kmsg, auth}, time.Time{}, time.Time{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	want := []Event{
+		{at(10.9), "dmesg", "eth0: link up"},
+		{at(20), "auth", "sshd[1]: Accepted publickey for alice"},
+		{at(21), "auth", "sshd[1]: Accepted publickey for alice"},
+		{at(30), "dmesg", "eth0: link down"},
+		{at(40), "dmesg", "eth0: link up"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
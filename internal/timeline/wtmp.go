@@ -0,0 +1,52 @@
+package timeline
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"syskit/internal/utmp"
+)
+
+// Wtmp reports boot and shutdown records from the wtmp login accounting file.
+type Wtmp struct {
+	Path string
+}
+
+func (w *Wtmp) Name() string { return "wtmp" }
+
+func (w *Wtmp) Collect(since, until time.Time) ([]Event, error) {
+	recs, err := utmp.Read(w.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return WtmpEvents(recs, since, until), nil
+}
+
+// WtmpEvents converts boot/shutdown records into events. The kernel version
+// is stored in the host field of both record kinds.
+func WtmpEvents(recs []utmp.Record, since, until time.Time) []Event {
+	var evs []Event
+	for _, r := range recs {
+		var msg string
+		switch {
+		case r.Type == utmp.BootTime:
+			msg = "system boot"
+		case r.Type == utmp.RunLevel && r.User == "shutdown":
+			msg = "system shutdown"
+		default:
+			continue
+		}
+		if !inWindow(r.Time, since, until) {
+			continue
+		}
+		if r.Host != "" {
+			msg += " (" + r.Host + ")"
+		}
+		evs = append(evs, Event{When: r.Time, Src: "wtmp", Msg: msg})
+	}
+	return evs
+}
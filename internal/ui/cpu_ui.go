@@ -1,11 +1,13 @@
 package ui
 
 import (
-    "os"
+    "fmt"
     "strconv"
     "strings"
     "time"
-    "runtime"
+
+    "syskit/internal/metrics"
+    "syskit/internal/theme"
 
     "github.com/charmbracelet/bubbles/progress"
     tea "github.com/charmbracelet/bubbletea"
@@ -14,29 +16,172 @@ import (
 
 type cpuTick time.Time
 
+// CPURow is the usage of one logical CPU (or of all of them, CPU -1)
+// between two samples, together with its sysfs attributes.
+type CPURow struct {
+    Name  string           `json:"name" yaml:"name"`
+    Usage metrics.CPUUsage `json:"usage" yaml:"usage"`
+    metrics.CPUCore        `yaml:",inline"`
+}
+
+// CPUReport is everything `syskit cpu` shows.
+type CPUReport struct {
+    Model    string            `json:"model" yaml:"model"`
+    Sockets  int               `json:"sockets" yaml:"sockets"`
+    Cores    int               `json:"cores" yaml:"cores"`
+    Threads  int               `json:"threads" yaml:"threads"`
+    Load1    float64           `json:"load1" yaml:"load1"`
+    Load5    float64           `json:"load5" yaml:"load5"`
+    Load15   float64           `json:"load15" yaml:"load15"`
+    Total    metrics.CPUUsage  `json:"total" yaml:"total"`
+    CPUs     []CPURow          `json:"cpus" yaml:"cpus"`
+    Pressure *metrics.Pressure `json:"pressure,omitempty" yaml:"pressure,omitempty"`
+}
+
+// BuildCPUReport combines two /proc/stat samples with the static CPU
+// information. Missing sysfs or PSI files only leave their fields empty.
+func BuildCPUReport(prev, cur metrics.Stat) CPUReport {
+    r := CPUReport{Total: metrics.Usage(prev.Total, cur.Total)}
+    if l, err := metrics.Default.LoadAvg(); err == nil {
+        r.Load1, r.Load5, r.Load15 = l.Load1, l.Load5, l.Load15
+    }
+    if p, err := metrics.Default.Pressure("cpu"); err == nil {
+        r.Pressure = &p
+    }
+    info, _ := metrics.Default.CPUInfo()
+    if len(info) > 0 {
+        r.Model = info[0].ModelName
+    }
+    cores, _ := metrics.DefaultSys.CPUCores()
+    byNum := map[int]metrics.CPUCore{}
+    for _, c := range cores {
+        byNum[c.CPU] = c
+    }
+    mhz := map[int]float64{}
+    for _, ci := range info {
+        mhz[ci.Processor] = ci.MHz
+    }
+    for i, c := range cur.CPUs {
+        n, _ := strconv.Atoi(strings.TrimPrefix(c.Name, "cpu"))
+        row := CPURow{Name: c.Name, CPUCore: metrics.CPUCore{CPU: n, Online: true}}
+        if sc, ok := byNum[n]; ok {
+            row.CPUCore = sc
+        }
+        // without a cpufreq driver (most VMs) cpuinfo still has the clock
+        if row.CurKHz == 0 {
+            row.CurKHz = uint64(mhz[n] * 1000)
+        }
+        if i < len(prev.CPUs) && prev.CPUs[i].Name == c.Name {
+            row.Usage = metrics.Usage(prev.CPUs[i], c)
+        }
+        r.CPUs = append(r.CPUs, row)
+    }
+    if len(cores) > 0 {
+        r.Sockets, r.Cores, r.Threads = metrics.Topology(cores)
+    } else {
+        r.Threads = len(cur.CPUs)
+    }
+    return r
+}
+
+// ReadCPUReport samples /proc/stat twice, interval apart.
+func ReadCPUReport(interval time.Duration) (CPUReport, error) {
+    prev, err := metrics.Default.Stat()
+    if err != nil {
+        return CPUReport{}, err
+    }
+    time.Sleep(interval)
+    cur, err := metrics.Default.Stat()
+    if err != nil {
+        return CPUReport{}, err
+    }
+    return BuildCPUReport(prev, cur), nil
+}
+
+// Topology is e.g. "1 socket, 4 cores, 8 threads".
+func (r CPUReport) Topology() string {
+    plural := func(n int, s string) string {
+        if n == 1 {
+            return fmt.Sprintf("%d %s", n, s)
+        }
+        return fmt.Sprintf("%d %ss", n, s)
+    }
+    if r.Sockets == 0 {
+        return plural(r.Threads, "thread")
+    }
+    return plural(r.Sockets, "socket") + ", " + plural(r.Cores, "core") + ", " + plural(r.Threads, "thread")
+}
+
+// Governors lists the distinct cpufreq governors and drivers in use.
+func (r CPUReport) Governors() string {
+    seen := map[string]bool{}
+    var out []string
+    for _, c := range r.CPUs {
+        g := c.Governor
+        if c.Driver != "" {
+            g += " (" + c.Driver + ")"
+        }
+        if g != "" && !seen[g] {
+            seen[g] = true
+            out = append(out, g)
+        }
+    }
+    return strings.Join(out, ", ")
+}
+
+// PSI formats the cpu pressure averages, or "" when unavailable.
+func (r CPUReport) PSI() string {
+    if r.Pressure == nil {
+        return ""
+    }
+    s := r.Pressure.Some
+    return fmt.Sprintf("some %.2f%% / %.2f%% / %.2f%% (10s/60s/300s)", s.Avg10, s.Avg60, s.Avg300)
+}
+
+// FreqRange formats the current clock and the scaling limits in MHz.
+func (c CPURow) FreqRange() (cur, limits string) {
+    if c.CurKHz > 0 {
+        cur = strconv.FormatUint(c.CurKHz/1000, 10)
+    }
+    if c.MaxKHz > 0 {
+        limits = fmt.Sprintf("%d-%d", c.MinKHz/1000, c.MaxKHz/1000)
+    }
+    return cur, limits
+}
+
 type CpuModel struct {
-    load   float64
     watch  bool
     bar    progress.Model
+    prev   metrics.Stat
+    report CPUReport
+    ready  bool
+    err    error
 }
 
 func NewCpuModel(watch bool) CpuModel {
-    prg := progress.New(progress.WithDefaultGradient())
-    return CpuModel{watch: watch, bar: prg}
+    prg := theme.Current().Progress(progress.WithWidth(20), progress.WithoutPercentage())
+    m := CpuModel{watch: watch, bar: prg}
+    m.prev, m.err = metrics.Default.Stat()
+    return m
 }
 
+// Init takes the second sample half a second in; per-core usage needs two.
 func (m CpuModel) Init() tea.Cmd {
-    if m.watch {
-        return tea.Tick(time.Second, func(t time.Time) tea.Msg { return cpuTick(t) })
-    }
-    return nil
+    return tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg { return cpuTick(t) })
 }
 
 func (m CpuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
     switch msg.(type) {
     case cpuTick:
-        m.load = readLoad()
-        return m, tea.Tick(time.Second, func(t time.Time) tea.Msg { return cpuTick(t) })
+        cur, err := metrics.Default.Stat()
+        if err != nil {
+            m.err = err
+            return m, nil
+        }
+        m.report, m.prev, m.ready = BuildCPUReport(m.prev, cur), cur, true
+        if m.watch {
+            return m, tea.Tick(time.Second, func(t time.Time) tea.Msg { return cpuTick(t) })
+        }
     case tea.KeyMsg:
         if msg.(tea.KeyMsg).String() == "q" {
             return m, tea.Quit
@@ -46,17 +191,38 @@ func (m CpuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m CpuModel) View() string {
-    m.load = readLoad() // initial or each rendering when not watch
-    cores := runtime.NumCPU()
-    bar := m.bar.ViewAs(m.load / float64(cores))
-    title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("CPU Usage — q:quit")
-    info := lipgloss.NewStyle().Render(
-        "Load: " + strconv.FormatFloat(m.load, 'f', 2, 64) + " | Cores: " + strconv.Itoa(cores))
-    return lipgloss.JoinVertical(lipgloss.Left, title, info, bar)
-}
-
-func readLoad() float64 {
-    b, _ := os.ReadFile("/proc/loadavg")
-    f, _ := strconv.ParseFloat(strings.Fields(string(b))[0], 64)
-    return f
+    th := theme.Current()
+    title := th.Heading().Render("CPU Usage — q:quit")
+    if m.err != nil {
+        return lipgloss.JoinVertical(lipgloss.Left, title, th.Fg(th.Error).Render(m.err.Error()))
+    }
+    if !m.ready {
+        return lipgloss.JoinVertical(lipgloss.Left, title, "sampling…")
+    }
+    r := m.report
+    info := []string{r.Model, r.Topology()}
+    if g := r.Governors(); g != "" {
+        info = append(info, g)
+    }
+    load := fmt.Sprintf("Load: %.2f %.2f %.2f", r.Load1, r.Load5, r.Load15)
+    if psi := r.PSI(); psi != "" {
+        load += " | Pressure: " + psi
+    }
+    head := th.Fg(th.Muted).Render(fmt.Sprintf("%-6s %-6s %s %6s %6s %6s %6s %6s %11s %5s",
+        "CPU", "BUSY", strings.Repeat(" ", 20), "USR%", "SYS%", "IOW%", "STL%", "MHz", "MIN-MAX", "THR"))
+    row := func(name string, u metrics.CPUUsage, cur, limits string, thr string) string {
+        return fmt.Sprintf("%-6s %5.1f%% %s %6.1f %6.1f %6.1f %6.1f %6s %11s %5s",
+            name, u.Busy, m.bar.ViewAs(u.Busy/100), u.User, u.System, u.IOWait, u.Steal, cur, limits, thr)
+    }
+    lines := []string{title, strings.Join(info, " | "), load, "", head,
+        th.Fg(th.CPU).Render(row("all", r.Total, "", "", ""))}
+    for _, c := range r.CPUs {
+        cur, limits := c.FreqRange()
+        thr := ""
+        if n := c.CoreThrottles + c.PackageThrottles; n > 0 {
+            thr = th.Fg(th.Error).Render(strconv.FormatUint(n, 10))
+        }
+        lines = append(lines, row(c.Name, c.Usage, cur, limits, thr))
+    }
+    return strings.Join(lines, "\n")
 }
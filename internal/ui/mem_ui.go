@@ -1,13 +1,15 @@
 package ui
 
 import (
-    "bufio"
     "fmt"
-    "os"
-    "strconv"
+    "sort"
     "strings"
     "time"
 
+    "syskit/internal/metrics"
+    "syskit/internal/procs"
+    "syskit/internal/theme"
+
     "github.com/charmbracelet/bubbles/progress"
     tea "github.com/charmbracelet/bubbletea"
     "github.com/charmbracelet/lipgloss"
@@ -15,17 +17,135 @@ import (
 
 type memTick time.Time
 
+// MemBreakdown splits physical memory like free(1); all values are bytes.
+// Used excludes buffers, page cache and reclaimable slab. Shmem is part of
+// Cached.
+type MemBreakdown struct {
+    Total        uint64 `json:"total" yaml:"total"`
+    Used         uint64 `json:"used" yaml:"used"`
+    Free         uint64 `json:"free" yaml:"free"`
+    Available    uint64 `json:"available" yaml:"available"`
+    Buffers      uint64 `json:"buffers" yaml:"buffers"`
+    Cached       uint64 `json:"cached" yaml:"cached"`
+    Shmem        uint64 `json:"shmem" yaml:"shmem"`
+    Slab         uint64 `json:"slab" yaml:"slab"`
+    SReclaimable uint64 `json:"slab_reclaimable" yaml:"slab_reclaimable"`
+    HugePages    uint64 `json:"hugepages" yaml:"hugepages"` // reserved for the hugetlb pool
+    AnonHuge     uint64 `json:"anon_hugepages" yaml:"anon_hugepages"`
+    SwapTotal    uint64 `json:"swap_total" yaml:"swap_total"`
+    SwapUsed     uint64 `json:"swap_used" yaml:"swap_used"`
+    SwapCached   uint64 `json:"swap_cached" yaml:"swap_cached"`
+}
+
+// MemProc is the memory use of one process. PSS and swap PSS are zero for
+// processes whose smaps_rollup is not readable.
+type MemProc struct {
+    PID      int    `json:"pid" yaml:"pid"`
+    Name     string `json:"name" yaml:"name"`
+    User     string `json:"user" yaml:"user"`
+    RSS      uint64 `json:"rss" yaml:"rss"`
+    PSS      uint64 `json:"pss" yaml:"pss"`
+    Swap     uint64 `json:"swap" yaml:"swap"`
+    OOMScore int    `json:"oom_score" yaml:"oom_score"`
+    OOMAdj   int    `json:"oom_score_adj" yaml:"oom_score_adj"`
+}
+
+// MemReport is everything `syskit mem` shows.
+type MemReport struct {
+    Memory   MemBreakdown      `json:"memory" yaml:"memory"`
+    UsedPct  float64           `json:"used_percent" yaml:"used_percent"`
+    SwapPct  float64           `json:"swap_percent" yaml:"swap_percent"`
+    SortBy   string            `json:"sort_by" yaml:"sort_by"`
+    Top      []MemProc         `json:"top" yaml:"top"`
+    Swap     []MemProc         `json:"swap" yaml:"swap"`
+    OOM      []MemProc         `json:"oom" yaml:"oom"`
+    Pressure *metrics.Pressure `json:"pressure,omitempty" yaml:"pressure,omitempty"`
+}
+
+// ReadMemReport collects the breakdown and the top n processes by rss or
+// pss, by swap and by OOM score.
+func ReadMemReport(n int, sortBy string) (MemReport, error) {
+    mi, err := metrics.Default.MemInfo()
+    if err != nil {
+        return MemReport{}, err
+    }
+    r := MemReport{UsedPct: mi.UsedPercent(), SwapPct: mi.SwapPercent(), SortBy: sortBy}
+    r.Memory = MemBreakdown{
+        Total: mi.MemTotal, Free: mi.MemFree, Available: mi.MemAvailable,
+        Buffers: mi.Buffers, Cached: mi.Cached, Shmem: mi.Shmem, Slab: mi.Slab, SReclaimable: mi.SReclaimable,
+        HugePages: mi.Raw["HugePages_Total"] * mi.Raw["Hugepagesize"], AnonHuge: mi.Raw["AnonHugePages"],
+        SwapTotal: mi.SwapTotal, SwapUsed: mi.SwapUsed(), SwapCached: mi.SwapCached,
+    }
+    if cache := mi.Buffers + mi.Cached + mi.SReclaimable; mi.MemTotal > mi.MemFree+cache {
+        r.Memory.Used = mi.MemTotal - mi.MemFree - cache
+    }
+    if p, err := metrics.Default.Pressure("memory"); err == nil {
+        r.Pressure = &p
+    }
+
+    list, err := procs.Default.List()
+    if err != nil {
+        return r, err
+    }
+    var all []MemProc
+    for _, p := range list {
+        if len(p.Cmdline) == 0 && p.RSS == 0 {
+            continue // kernel thread
+        }
+        mp := MemProc{PID: p.PID, Name: p.Name, User: p.User, RSS: p.RSS}
+        if u, err := procs.Default.MemUsage(p.PID); err == nil {
+            mp.PSS, mp.Swap = u.PSS, u.Swap
+        }
+        mp.OOMScore, mp.OOMAdj, _ = procs.Default.OOMScore(p.PID)
+        all = append(all, mp)
+    }
+    top := func(less func(a, b MemProc) bool, keep func(MemProc) bool) []MemProc {
+        out := []MemProc{}
+        for _, p := range all {
+            if keep(p) {
+                out = append(out, p)
+            }
+        }
+        sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+        if len(out) > n {
+            out = out[:n]
+        }
+        return out
+    }
+    every := func(MemProc) bool { return true }
+    if sortBy == "pss" {
+        r.Top = top(func(a, b MemProc) bool { return a.PSS > b.PSS }, every)
+    } else {
+        r.Top = top(func(a, b MemProc) bool { return a.RSS > b.RSS }, every)
+    }
+    r.Swap = top(func(a, b MemProc) bool { return a.Swap > b.Swap }, func(p MemProc) bool { return p.Swap > 0 })
+    r.OOM = top(func(a, b MemProc) bool { return a.OOMScore > b.OOMScore }, every)
+    return r, nil
+}
+
+// PSI formats the memory pressure averages, or "" when unavailable.
+func (r MemReport) PSI() string {
+    if r.Pressure == nil {
+        return ""
+    }
+    s, f := r.Pressure.Some, r.Pressure.Full
+    return fmt.Sprintf("some %.2f%% / %.2f%% / %.2f%%, full %.2f%% / %.2f%% / %.2f%% (10s/60s/300s)",
+        s.Avg10, s.Avg60, s.Avg300, f.Avg10, f.Avg60, f.Avg300)
+}
+
 type MemModel struct {
-    ramPct  float64
-    swapPct float64
-    watch   bool
-    ramBar  progress.Model
-    swapBar progress.Model
+    watch  bool
+    top    int
+    sortBy string
+    bar    progress.Model
+    report MemReport
+    err    error
 }
 
-func NewMemModel(watch bool) MemModel {
-    prg := progress.New(progress.WithDefaultGradient())
-    return MemModel{watch: watch, ramBar: prg, swapBar: prg}
+func NewMemModel(watch bool, top int, sortBy string) MemModel {
+    m := MemModel{watch: watch, top: top, sortBy: sortBy, bar: theme.Current().Progress(progress.WithWidth(40))}
+    m.readMem()
+    return m
 }
 
 func (m MemModel) Init() tea.Cmd {
@@ -49,47 +169,69 @@ func (m MemModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m MemModel) View() string {
-    m.readMem() // initial render
-    title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).Render("Memory Usage — q:quit")
-    ramLbl := fmt.Sprintf("RAM %.1f%%", m.ramPct)
-    swapLbl := fmt.Sprintf("Swap %.1f%%", m.swapPct)
-    return lipgloss.JoinVertical(lipgloss.Left,
+    th := theme.Current()
+    title := th.Heading().Render("Memory Usage — q:quit")
+    if m.err != nil {
+        return lipgloss.JoinVertical(lipgloss.Left, title, th.Fg(th.Error).Render(m.err.Error()))
+    }
+    r, b := m.report, m.report.Memory
+    ramLbl := fmt.Sprintf("RAM %.1f%%  (%s of %s, %s available)", r.UsedPct, humanBytes(b.Total-b.Available), humanBytes(b.Total), humanBytes(b.Available))
+    swapLbl := fmt.Sprintf("Swap %.1f%%  (%s of %s, %s cached)", r.SwapPct, humanBytes(b.SwapUsed), humanBytes(b.SwapTotal), humanBytes(b.SwapCached))
+    muted := th.Fg(th.Muted)
+    breakdown := fmt.Sprintf("used %s  buffers %s  cached %s (shmem %s)  slab %s (%s reclaimable)  free %s",
+        humanBytes(b.Used), humanBytes(b.Buffers), humanBytes(b.Cached), humanBytes(b.Shmem),
+        humanBytes(b.Slab), humanBytes(b.SReclaimable), humanBytes(b.Free))
+    if b.HugePages > 0 || b.AnonHuge > 0 {
+        breakdown += fmt.Sprintf("\nhugepages %s  anon THP %s", humanBytes(b.HugePages), humanBytes(b.AnonHuge))
+    }
+    lines := []string{
         title,
-        lipgloss.NewStyle().Render(ramLbl+"\n"+m.ramBar.ViewAs(m.ramPct/100)),
-        lipgloss.NewStyle().Render(swapLbl+"\n"+m.swapBar.ViewAs(m.swapPct/100)),
-    )
+        th.Fg(th.Mem).Render(ramLbl) + "\n" + m.bar.ViewAs(r.UsedPct/100),
+        th.Fg(th.Swap).Render(swapLbl) + "\n" + m.bar.ViewAs(r.SwapPct/100),
+        breakdown,
+    }
+    if psi := r.PSI(); psi != "" {
+        lines = append(lines, "Pressure: "+psi)
+    }
+    procTable := func(heading string, list []MemProc) string {
+        rows := []string{th.Fg(th.Title).Render(heading),
+            muted.Render(fmt.Sprintf("%7s %-16s %-10s %10s %10s %10s %5s %5s", "PID", "NAME", "USER", "RSS", "PSS", "SWAP", "OOM", "ADJ"))}
+        for _, p := range list {
+            rows = append(rows, fmt.Sprintf("%7d %-16s %-10s %10s %10s %10s %5d %5d", p.PID, clip(p.Name, 16), clip(p.User, 10),
+                humanBytes(p.RSS), humanBytes(p.PSS), humanBytes(p.Swap), p.OOMScore, p.OOMAdj))
+        }
+        if len(list) == 0 {
+            rows = append(rows, muted.Render("(none)"))
+        }
+        return strings.Join(rows, "\n")
+    }
+    lines = append(lines, "",
+        procTable(fmt.Sprintf("Top processes by %s", strings.ToUpper(r.SortBy)), r.Top), "",
+        procTable("Swap users", r.Swap), "",
+        procTable("OOM kill candidates", r.OOM))
+    return strings.Join(lines, "\n")
 }
 
 func (m *MemModel) readMem() {
-    f, err := os.Open("/proc/meminfo")
-    if err != nil {
-        return
-    }
-    defer f.Close()
-    scanner := bufio.NewScanner(f)
-    var memTotal, memFree, swapTotal, swapFree float64
-    for scanner.Scan() {
-        parts := strings.Fields(scanner.Text())
-        if len(parts) < 2 {
-            continue
-        }
-        key := parts[0]
-        val, _ := strconv.ParseFloat(parts[1], 64)
-        switch {
-        case strings.HasPrefix(key, "MemTotal"):
-            memTotal = val
-        case strings.HasPrefix(key, "MemAvailable"):
-            memFree = val
-        case strings.HasPrefix(key, "SwapTotal"):
-            swapTotal = val
-        case strings.HasPrefix(key, "SwapFree"):
-            swapFree = val
-        }
+    m.report, m.err = ReadMemReport(m.top, m.sortBy)
+}
+
+func humanBytes(b uint64) string {
+    const unit = 1024
+    if b < unit {
+        return fmt.Sprintf("%d B", b)
     }
-    if memTotal > 0 {
-        m.ramPct = 100 * (memTotal - memFree) / memTotal
+    div, exp := uint64(unit), 0
+    for n := b / unit; n >= unit; n /= unit {
+        div *= unit
+        exp++
     }
-    if swapTotal > 0 {
-        m.swapPct = 100 * (swapTotal - swapFree) / swapTotal
+    return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func clip(s string, n int) string {
+    if len(s) <= n {
+        return s
     }
+    return s[:n-1] + "…"
 }
@@ -1,34 +1,36 @@
 package ui
 
 import (
-    "os/exec"
+    "fmt"
+    "strconv"
     "strings"
     "time"
 
+    "syskit/internal/netstat"
+    "syskit/internal/theme"
+
     tea "github.com/charmbracelet/bubbletea"
-    "github.com/charmbracelet/lipgloss"
 )
 
 type portsTick time.Time
 
-type portRow struct {
-    proto string
-    local string
-    peer  string
-}
-
 type PortsModel struct {
-    filter string
-    watch  bool
-    rows   []portRow
+    filter      netstat.Filter
+    established bool
+    watch       bool
+    rows        []netstat.Entry
+    err         error
 }
 
-func NewPortsModel(filter string, watch bool) PortsModel {
-    return PortsModel{filter: filter, watch: watch}
+// NewPortsModel shows the sockets matching filter. In established mode a
+// per-remote summary is drawn above the connections.
+func NewPortsModel(filter netstat.Filter, established, watch bool) PortsModel {
+    m := PortsModel{filter: filter, established: established, watch: watch}
+    m.refresh()
+    return m
 }
 
 func (m PortsModel) Init() tea.Cmd {
-    m.refresh()
     if m.watch {
         return tea.Tick(time.Second*2, func(t time.Time) tea.Msg { return portsTick(t) })
     }
@@ -48,39 +50,48 @@ func (m PortsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
     return m, nil
 }
 
+// PortFields formats an entry for display: proto, local, peer, state,
+// pid, process, user and container.
+func PortFields(e netstat.Entry) []string {
+    pid := ""
+    if e.PID != 0 {
+        pid = strconv.Itoa(e.PID)
+    }
+    return []string{e.Proto, e.Local(), e.Remote(), e.State, pid, e.Process, e.User, e.Container}
+}
+
 func (m PortsModel) View() string {
-    header := lipgloss.NewStyle().Bold(true).Render("Proto  Local Address  Peer")
+    th := theme.Current()
     var lines []string
-    lines = append(lines, header)
-    for _, r := range m.rows {
-        lines = append(lines, r.proto+"  "+r.local+"  "+r.peer)
+    if m.err != nil {
+        lines = append(lines, th.Fg(th.Error).Render(m.err.Error()))
     }
-    if len(m.rows)==0 {
+    if m.established {
+        lines = append(lines, th.Heading().Render(fmt.Sprintf("%-40s %6s  %-14s %s", "Remote", "Conns", "Local ports", "Processes")))
+        for _, rc := range netstat.Remotes(m.rows) {
+            ports := make([]string, len(rc.Ports))
+            for i, p := range rc.Ports {
+                ports[i] = strconv.Itoa(p)
+            }
+            lines = append(lines, fmt.Sprintf("%-40s %6d  %-14s %s", clip(rc.Remote, 40), rc.Count,
+                clip(strings.Join(ports, ","), 14), strings.Join(rc.Processes, ",")))
+        }
+        lines = append(lines, "")
+    }
+    format := "%-5s %-28s %-28s %-11s %7s %-15s %-10s %s"
+    lines = append(lines, th.Heading().Render(fmt.Sprintf(format, "Proto", "Local Address", "Peer", "State", "PID", "Process", "User", "Container")))
+    for _, e := range m.rows {
+        f := PortFields(e)
+        lines = append(lines, fmt.Sprintf(format, f[0], clip(f[1], 28), clip(f[2], 28), f[3], f[4], clip(f[5], 15), clip(f[6], 10), f[7]))
+    }
+    if len(m.rows) == 0 {
         lines = append(lines, "<no matches>")
     }
-    hint := lipgloss.NewStyle().Faint(true).Render("q: quit")
+    hint := th.Fg(th.Muted).Render("q: quit")
     lines = append(lines, "", hint)
     return strings.Join(lines, "\n")
 }
 
 func (m *PortsModel) refresh() {
-    cmd := exec.Command("ss", "-tuln")
-    out, err := cmd.Output()
-    if err != nil {
-        m.rows = nil
-        return
-    }
-    lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-    var rows []portRow
-    for _, l := range lines[1:] {
-        if m.filter != "" && !strings.Contains(l, m.filter) {
-            continue
-        }
-        f := strings.Fields(l)
-        if len(f) < 5 {
-            continue
-        }
-        rows = append(rows, portRow{proto: f[0], local: f[3], peer: f[4]})
-    }
-    m.rows = rows
+    m.rows, m.err = netstat.List(m.filter)
 }
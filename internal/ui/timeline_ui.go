@@ -4,12 +4,15 @@ import (
     "fmt"
     "sort"
 
+    "syskit/internal/theme"
+
     "github.com/gdamore/tcell/v2"
     "github.com/rivo/tview"
 )
 
 // RunTimeline displays timeline groups using tview list + textview. keys: arrow, enter, q
 func RunTimeline(groups map[string][]string) error {
+    th := theme.Current()
     app := tview.NewApplication()
 
     // sort categories
@@ -19,7 +22,7 @@ func RunTimeline(groups map[string][]string) error {
     }
     sort.Strings(cats)
 
-    list := tview.NewList().ShowSecondaryText(false)
+    list := tview.NewList().ShowSecondaryText(false).SetSelectedStyle(th.TviewSelected())
     for _, c := range cats {
         list.AddItem(fmt.Sprintf("%s (%d)", c, len(groups[c])), "", 0, nil)
     }
@@ -35,7 +38,7 @@ func RunTimeline(groups map[string][]string) error {
         var lines []string
         if idx == len(cats) { // All
             for _, c := range cats {
-                lines = append(lines, fmt.Sprintf("[yellow]=== %s ===[-]", c))
+                lines = append(lines, fmt.Sprintf("%s=== %s ===[-]", th.Tag(th.Highlight), c))
                 lines = append(lines, groups[c]...)
                 lines = append(lines, "")
             }
@@ -1,32 +1,176 @@
 package ui
 
 import (
-    "os/exec"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
     "strings"
     "time"
 
+    "syskit/internal/theme"
+    "syskit/internal/utmp"
+
     tea "github.com/charmbracelet/bubbletea"
-    "github.com/charmbracelet/lipgloss"
 )
 
 type usersTick time.Time
 
-type userRow struct {
-    name string
-    tty  string
+// UserSession is a current login from utmp.
+type UserSession struct {
+    User        string    `json:"user" yaml:"user"`
+    TTY         string    `json:"tty" yaml:"tty"`
+    Host        string    `json:"host,omitempty" yaml:"host,omitempty"`
+    Login       time.Time `json:"login" yaml:"login"`
+    IdleSeconds int64     `json:"idle_seconds" yaml:"idle_seconds"`
+    PID         int       `json:"pid" yaml:"pid"`
+    Process     string    `json:"process,omitempty" yaml:"process,omitempty"`
+}
+
+// LoginEntry is one past or current session from wtmp; reboots appear as
+// user "reboot".
+type LoginEntry struct {
+    User            string    `json:"user" yaml:"user"`
+    TTY             string    `json:"tty" yaml:"tty"`
+    Host            string    `json:"host,omitempty" yaml:"host,omitempty"`
+    Login           time.Time `json:"login" yaml:"login"`
+    Logout          time.Time `json:"logout,omitempty" yaml:"logout,omitempty"`
+    DurationSeconds int64     `json:"duration_seconds" yaml:"duration_seconds"`
+    End             string    `json:"end" yaml:"end"`
+}
+
+// FailedLogin is one record of btmp.
+type FailedLogin struct {
+    User string    `json:"user" yaml:"user"`
+    TTY  string    `json:"tty" yaml:"tty"`
+    Host string    `json:"host,omitempty" yaml:"host,omitempty"`
+    Time time.Time `json:"time" yaml:"time"`
+}
+
+// UserStats aggregates the history of one user.
+type UserStats struct {
+    User         string    `json:"user" yaml:"user"`
+    Logins       int       `json:"logins" yaml:"logins"`
+    Failed       int       `json:"failed" yaml:"failed"`
+    TotalSeconds int64     `json:"total_seconds" yaml:"total_seconds"`
+    LastLogin    time.Time `json:"last_login,omitempty" yaml:"last_login,omitempty"`
+    Hosts        []string  `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+// UsersHistory is the output of `syskit users --history`. Warnings name
+// the files that could not be read (btmp is root-only).
+type UsersHistory struct {
+    Sessions []LoginEntry  `json:"sessions" yaml:"sessions"`
+    Failed   []FailedLogin `json:"failed" yaml:"failed"`
+    Users    []UserStats   `json:"users" yaml:"users"`
+    Warnings []string      `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// ReadUsers returns the current sessions from utmp. A missing utmp (as in
+// most containers) means nobody is logged in.
+func ReadUsers() ([]UserSession, error) {
+    recs, err := utmp.Read(utmp.UtmpPath)
+    if errors.Is(err, os.ErrNotExist) {
+        return []UserSession{}, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    out := []UserSession{}
+    for _, r := range utmp.Active(recs) {
+        s := UserSession{User: r.User, TTY: r.Line, Host: r.Host, Login: r.Time, PID: int(r.PID)}
+        if idle, err := utmp.Idle(r.Line, now); err == nil {
+            s.IdleSeconds = int64(idle / time.Second)
+        }
+        if b, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(s.PID), "comm")); err == nil {
+            s.Process = strings.TrimSpace(string(b))
+        }
+        out = append(out, s)
+    }
+    return out, nil
+}
+
+// ReadUsersHistory reads wtmp and btmp and keeps the limit most recent
+// sessions and failed attempts; aggregates cover the whole files.
+func ReadUsersHistory(limit int) (UsersHistory, error) {
+    h := UsersHistory{Sessions: []LoginEntry{}, Failed: []FailedLogin{}, Users: []UserStats{}}
+    wtmp, err := utmp.Read(utmp.WtmpPath)
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+        return h, err
+    }
+    btmp, err := utmp.Read(utmp.BtmpPath)
+    if err != nil && !errors.Is(err, os.ErrNotExist) {
+        h.Warnings = append(h.Warnings, err.Error())
+    }
+    now := time.Now()
+    sessions := utmp.Sessions(wtmp)
+    for i, s := range sessions {
+        if limit > 0 && i >= limit {
+            break
+        }
+        h.Sessions = append(h.Sessions, LoginEntry{User: s.User, TTY: s.Line, Host: s.Host, Login: s.Login, Logout: s.Logout,
+            DurationSeconds: int64(s.Duration(now) / time.Second), End: s.End})
+    }
+    for i := len(btmp) - 1; i >= 0; i-- {
+        if limit > 0 && len(h.Failed) >= limit {
+            break
+        }
+        r := btmp[i]
+        h.Failed = append(h.Failed, FailedLogin{User: r.User, TTY: r.Line, Host: r.Host, Time: r.Time})
+    }
+    for _, s := range utmp.Summarize(sessions, btmp, now) {
+        h.Users = append(h.Users, UserStats{User: s.User, Logins: s.Logins, Failed: s.Failed,
+            TotalSeconds: int64(s.Total / time.Second), LastLogin: s.LastLogin, Hosts: s.Hosts})
+    }
+    return h, nil
+}
+
+// ShortDuration formats d like last(1): "00:42", "3+01:15".
+func ShortDuration(d time.Duration) string {
+    if d < 0 {
+        d = 0
+    }
+    days := int(d / (24 * time.Hour))
+    d -= time.Duration(days) * 24 * time.Hour
+    s := fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+    if days > 0 {
+        s = fmt.Sprintf("%d+%s", days, s)
+    }
+    return s
+}
+
+// IdleString formats an idle time in seconds compactly: "12s", "4m", "2h05m", "3d".
+func IdleString(secs int64) string {
+    d := time.Duration(secs) * time.Second
+    switch {
+    case d < time.Minute:
+        return fmt.Sprintf("%ds", secs)
+    case d < time.Hour:
+        return fmt.Sprintf("%dm", int(d/time.Minute))
+    case d < 24*time.Hour:
+        return fmt.Sprintf("%dh%02dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+    }
+    return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
 }
 
 type UsersModel struct {
-    watch bool
-    rows  []userRow
+    watch   bool
+    history bool
+    limit   int
+    rows    []UserSession
+    hist    UsersHistory
+    err     error
 }
 
-func NewUsersModel(watch bool) UsersModel {
-    return UsersModel{watch: watch}
+func NewUsersModel(watch, history bool, limit int) UsersModel {
+    m := UsersModel{watch: watch, history: history, limit: limit}
+    m.refresh()
+    return m
 }
 
 func (m UsersModel) Init() tea.Cmd {
-    m.refresh()
     if m.watch {
         return tea.Tick(time.Second*2, func(t time.Time) tea.Msg { return usersTick(t) })
     }
@@ -47,32 +191,71 @@ func (m UsersModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m UsersModel) View() string {
-    header := lipgloss.NewStyle().Bold(true).Render("User  TTY")
+    th := theme.Current()
     var lines []string
-    lines = append(lines, header)
-    for _, r := range m.rows {
-        lines = append(lines, r.name+"  "+r.tty)
+    if m.err != nil {
+        lines = append(lines, th.Fg(th.Error).Render(m.err.Error()))
     }
-    if len(m.rows) == 0 {
-        lines = append(lines, "<no users>")
+    if m.history {
+        lines = append(lines, m.historyView()...)
+    } else {
+        format := "%-12s %-8s %-22s %-12s %6s %7s %s"
+        lines = append(lines, th.Heading().Render(fmt.Sprintf(format, "User", "TTY", "From", "Login", "Idle", "PID", "What")))
+        for _, r := range m.rows {
+            lines = append(lines, fmt.Sprintf(format, clip(r.User, 12), r.TTY, clip(r.Host, 22), r.Login.Format("Jan 02 15:04"),
+                IdleString(r.IdleSeconds), strconv.Itoa(r.PID), r.Process))
+        }
+        if len(m.rows) == 0 {
+            lines = append(lines, "<no users>")
+        }
     }
-    lines = append(lines, "", lipgloss.NewStyle().Faint(true).Render("q: quit"))
+    lines = append(lines, "", th.Fg(th.Muted).Render("q: quit"))
     return strings.Join(lines, "\n")
 }
 
-func (m *UsersModel) refresh() {
-    out, err := exec.Command("who").Output()
-    if err != nil {
-        m.rows = nil
-        return
+func (m UsersModel) historyView() []string {
+    th := theme.Current()
+    h := m.hist
+    var lines []string
+    for _, w := range h.Warnings {
+        lines = append(lines, th.Fg(th.Muted).Render("warning: "+w))
+    }
+    format := "%-10s %-12s %-22s %-16s %-16s %10s"
+    lines = append(lines, th.Heading().Render(fmt.Sprintf(format, "User", "TTY", "From", "Login", "Logout", "Duration")))
+    for _, s := range h.Sessions {
+        logout := s.End
+        if s.End == utmp.EndLogout {
+            logout = s.Logout.Format("Jan 02 15:04")
+        }
+        lines = append(lines, fmt.Sprintf(format, clip(s.User, 10), clip(s.TTY, 12), clip(s.Host, 22), s.Login.Format("Jan 02 15:04"),
+            logout, ShortDuration(time.Duration(s.DurationSeconds)*time.Second)))
+    }
+    if len(h.Sessions) == 0 {
+        lines = append(lines, "<no sessions in wtmp>")
+    }
+    lines = append(lines, "", th.Heading().Render(fmt.Sprintf("%-10s %-12s %-22s %s", "Failed", "TTY", "From", "When")))
+    for _, f := range h.Failed {
+        lines = append(lines, th.Fg(th.Error).Render(fmt.Sprintf("%-10s %-12s %-22s %s", clip(f.User, 10), clip(f.TTY, 12), clip(f.Host, 22), f.Time.Format("Jan 02 15:04"))))
     }
-    lines := strings.Split(strings.TrimSpace(string(out)), "\n")
-    var rows []userRow
-    for _, l := range lines {
-        f := strings.Fields(l)
-        if len(f) >= 2 {
-            rows = append(rows, userRow{name: f[0], tty: f[1]})
+    if len(h.Failed) == 0 {
+        lines = append(lines, "<no failed logins>")
+    }
+    lines = append(lines, "", th.Heading().Render(fmt.Sprintf("%-12s %7s %7s %12s %-16s %s", "User", "Logins", "Failed", "Total", "Last login", "Hosts")))
+    for _, u := range h.Users {
+        last := ""
+        if !u.LastLogin.IsZero() {
+            last = u.LastLogin.Format("Jan 02 15:04")
         }
+        lines = append(lines, fmt.Sprintf("%-12s %7d %7d %12s %-16s %s", clip(u.User, 12), u.Logins, u.Failed,
+            ShortDuration(time.Duration(u.TotalSeconds)*time.Second), last, strings.Join(u.Hosts, ",")))
+    }
+    return lines
+}
+
+func (m *UsersModel) refresh() {
+    if m.history {
+        m.hist, m.err = ReadUsersHistory(m.limit)
+        return
     }
-    m.rows = rows
+    m.rows, m.err = ReadUsers()
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/olekukonko/tablewriter"
+	"golang.org/x/term"
 	"gopkg.in/yaml.v2"
 	"syskit/internal/i18n"
 )
@@ -15,6 +16,12 @@ var format = "table"
 // SetFormat sets global output format
 func SetFormat(f string) { format = f }
 
+// IsTerminal reports whether f is a TTY. A character device alone is not
+// enough: /dev/null is one too.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
 // PrintTable prints 2D string slice as table
 func PrintTable(headers []string, rows [][]string) {
 	table := tablewriter.NewWriter(os.Stdout)
@@ -57,3 +64,18 @@ func Print(headers []string, rows [][]string) {
 		PrintTable(headers, rows)
 	}
 }
+
+// PrintValue prints v as JSON or YAML, or calls table for the table
+// format. It suits reports that do not fit a single table.
+func PrintValue(v interface{}, table func()) {
+	switch format {
+	case "json":
+		b, _ := json.MarshalIndent(v, "", "  ")
+		fmt.Println(string(b))
+	case "yaml":
+		b, _ := yaml.Marshal(v)
+		fmt.Print(string(b))
+	default:
+		table()
+	}
+}
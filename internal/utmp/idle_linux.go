@@ -0,0 +1,25 @@
+package utmp
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// Idle returns how long the terminal line (e.g. "pts/0") has had no input,
+// measured from the device's access time like w(1) does.
+func Idle(line string, now time.Time) (time.Duration, error) {
+	fi, err := os.Stat(filepath.Join("/dev", line))
+	if err != nil {
+		return 0, err
+	}
+	at := fi.ModTime()
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		at = time.Unix(st.Atim.Sec, st.Atim.Nsec)
+	}
+	if d := now.Sub(at); d > 0 {
+		return d, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package utmp
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Idle returns how long the terminal line has been idle. Without access
+// times it falls back to the device's modification time.
+func Idle(line string, now time.Time) (time.Duration, error) {
+	fi, err := os.Stat(filepath.Join("/dev", line))
+	if err != nil {
+		return 0, err
+	}
+	if d := now.Sub(fi.ModTime()); d > 0 {
+		return d, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,164 @@
+package utmp
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// How a session ended, as printed by last(1).
+const (
+	EndLogout  = "logout"
+	EndDown    = "down"  // system shut down cleanly
+	EndCrash   = "crash" // system booted again without a shutdown record
+	EndGone    = "gone"  // the line was reused without a logout record
+	EndStillIn = "still logged in"
+	EndRunning = "still running" // the current boot
+)
+
+// Session is one login from its USER_PROCESS record to whatever ended it.
+// Boots are sessions of the pseudo user "reboot" whose Host is the kernel
+// version, like in last(1).
+type Session struct {
+	User   string
+	Line   string
+	Host   string
+	PID    int32
+	Login  time.Time
+	Logout time.Time // zero while still open
+	End    string
+}
+
+// Duration is the session length, measured up to now for open sessions.
+func (s Session) Duration(now time.Time) time.Duration {
+	if s.Logout.IsZero() {
+		return now.Sub(s.Login)
+	}
+	return s.Logout.Sub(s.Login)
+}
+
+// Sessions pairs the login, logout, boot and shutdown records of a wtmp
+// file into sessions, newest first.
+func Sessions(recs []Record) []Session {
+	recs = append([]Record(nil), recs...)
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Time.Before(recs[j].Time) })
+	var out []Session
+	open := map[string]int{} // line -> index into out
+	boot := -1
+	closeAll := func(at time.Time, end string) {
+		for line, i := range open {
+			out[i].Logout, out[i].End = at, end
+			delete(open, line)
+		}
+	}
+	for _, r := range recs {
+		switch {
+		case r.Type == UserProcess && r.User != "":
+			if i, ok := open[r.Line]; ok {
+				out[i].Logout, out[i].End = r.Time, EndGone
+			}
+			out = append(out, Session{User: r.User, Line: r.Line, Host: r.Host, PID: r.PID, Login: r.Time})
+			open[r.Line] = len(out) - 1
+		case r.Type == DeadProcess && r.Line != "":
+			if i, ok := open[r.Line]; ok {
+				out[i].Logout, out[i].End = r.Time, EndLogout
+				delete(open, r.Line)
+			}
+		case r.Type == RunLevel && r.User == "shutdown":
+			closeAll(r.Time, EndDown)
+			if boot >= 0 {
+				out[boot].Logout, out[boot].End = r.Time, EndDown
+				boot = -1
+			}
+		case r.Type == BootTime:
+			closeAll(r.Time, EndCrash)
+			if boot >= 0 {
+				out[boot].Logout, out[boot].End = r.Time, EndCrash
+			}
+			out = append(out, Session{User: "reboot", Line: "system boot", Host: r.Host, Login: r.Time})
+			boot = len(out) - 1
+		}
+	}
+	for _, i := range open {
+		out[i].End = EndStillIn
+	}
+	if boot >= 0 {
+		out[boot].End = EndRunning
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Login.After(out[j].Login) })
+	return out
+}
+
+// Active returns the USER_PROCESS records of a utmp file, i.e. the
+// sessions currently logged in.
+func Active(recs []Record) []Record {
+	var out []Record
+	for _, r := range recs {
+		if r.Type == UserProcess && r.User != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Summary aggregates the history of one user.
+type Summary struct {
+	User      string
+	Logins    int
+	Failed    int
+	Total     time.Duration
+	LastLogin time.Time
+	Hosts     []string
+}
+
+// Summarize aggregates sessions (reboots excluded) and failed attempts
+// (btmp records) per user, sorted by name.
+func Summarize(sessions []Session, failed []Record, now time.Time) []Summary {
+	by := map[string]*Summary{}
+	get := func(user string) *Summary {
+		s := by[user]
+		if s == nil {
+			s = &Summary{User: user}
+			by[user] = s
+		}
+		return s
+	}
+	addHost := func(s *Summary, host string) {
+		if host == "" {
+			return
+		}
+		for _, h := range s.Hosts {
+			if h == host {
+				return
+			}
+		}
+		s.Hosts = append(s.Hosts, host)
+	}
+	for _, ss := range sessions {
+		if ss.User == "reboot" {
+			continue
+		}
+		s := get(ss.User)
+		s.Logins++
+		s.Total += ss.Duration(now)
+		if ss.Login.After(s.LastLogin) {
+			s.LastLogin = ss.Login
+		}
+		addHost(s, ss.Host)
+	}
+	for _, r := range failed {
+		if r.User == "" {
+			continue
+		}
+		s := get(r.User)
+		s.Failed++
+		addHost(s, r.Host)
+	}
+	out := make([]Summary, 0, len(by))
+	for _, s := range by {
+		sort.Strings(s.Hosts)
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.Compare(out[i].User, out[j].User) < 0 })
+	return out
+}
@@ -0,0 +1,124 @@
+package utmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"time"
+)
+
+// Record types as defined in <utmp.h>.
+const (
+	Empty        = 0
+	RunLevel     = 1
+	BootTime     = 2
+	NewTime      = 3
+	OldTime      = 4
+	InitProcess  = 5
+	LoginProcess = 6
+	UserProcess  = 7
+	DeadProcess  = 8
+	Accounting   = 9
+)
+
+// Default file locations on Linux.
+const (
+	UtmpPath = "/var/run/utmp"
+	WtmpPath = "/var/log/wtmp"
+	BtmpPath = "/var/log/btmp"
+)
+
+// raw mirrors struct utmp on 64-bit glibc (384 bytes, 32-bit time fields).
+type raw struct {
+	Type    int16
+	_       [2]byte
+	PID     int32
+	Line    [32]byte
+	ID      [4]byte
+	User    [32]byte
+	Host    [256]byte
+	ExitTrm int16
+	ExitSts int16
+	Session int32
+	Sec     int32
+	Usec    int32
+	Addr    [4]int32
+	_       [20]byte
+}
+
+// RecordSize is the on-disk size of one utmp/wtmp/btmp record.
+const RecordSize = 384
+
+// Record is a decoded utmp entry.
+type Record struct {
+	Type    int16
+	PID     int32
+	Line    string
+	ID      string
+	User    string
+	Host    string
+	Session int32
+	Time    time.Time
+	Addr    net.IP
+}
+
+// Read decodes all records from the file at path.
+func Read(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse decodes records from r until EOF. A trailing partial record is ignored.
+func Parse(r io.Reader) ([]Record, error) {
+	var out []Record
+	for {
+		var rr raw
+		err := binary.Read(r, binary.LittleEndian, &rr)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, decode(rr))
+	}
+}
+
+func decode(rr raw) Record {
+	rec := Record{
+		Type:    rr.Type,
+		PID:     rr.PID,
+		Line:    cstr(rr.Line[:]),
+		ID:      cstr(rr.ID[:]),
+		User:    cstr(rr.User[:]),
+		Host:    cstr(rr.Host[:]),
+		Session: rr.Session,
+		Time:    time.Unix(int64(uint32(rr.Sec)), int64(rr.Usec)*1000),
+	}
+	var buf [16]byte
+	for i, v := range rr.Addr {
+		binary.LittleEndian.PutUint32(buf[i*4:], uint32(v))
+	}
+	if rr.Addr[1] == 0 && rr.Addr[2] == 0 && rr.Addr[3] == 0 {
+		if rr.Addr[0] != 0 {
+			rec.Addr = net.IP(buf[:4])
+		}
+	} else {
+		rec.Addr = net.IP(buf[:])
+	}
+	return rec
+}
+
+func cstr(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		b = b[:i]
+	}
+	return string(b)
+}
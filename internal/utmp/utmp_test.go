@@ -0,0 +1,112 @@
+package utmp
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// t0 is 2024-03-01 08:00:00 UTC, the first boot in testdata/wtmp.
+var t0 = time.Unix(1709280000, 0)
+
+func at(sec int) time.Time { return t0.Add(time.Duration(sec) * time.Second) }
+
+func readFixture(t *testing.T, name string) []Record {
+	t.Helper()
+	recs, err := Read("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recs
+}
+
+func TestParse(t *testing.T) {
+	recs := readFixture(t, "wtmp")
+	// the trailing partial record is ignored
+	if len(recs) != 8 {
+		t.Fatalf("got %d records, want 8", len(recs))
+	}
+	tests := []struct {
+		idx  int
+		want Record
+	}{
+		{0, Record{Type: BootTime, Line: "~", ID: "~~", User: "reboot", Host: "6.1.0-18-amd64", Time: at(0)}},
+		{2, Record{Type: UserProcess, PID: 1000, Line: "pts/0", ID: "ts/0", User: "alice", Host: "192.0.2.7",
+			Time: at(3600), Addr: net.IPv4(192, 0, 2, 7).To4()}},
+		{4, Record{Type: DeadProcess, PID: 1000, Line: "pts/0", ID: "ts/0", Time: at(5400)}},
+		{5, Record{Type: RunLevel, Line: "~", ID: "~~", User: "shutdown", Host: "6.1.0-18-amd64", Time: at(10800)}},
+	}
+	for _, tt := range tests {
+		got := recs[tt.idx]
+		got.Time = got.Time.In(tt.want.Time.Location())
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("record %d:\n got %+v\nwant %+v", tt.idx, got, tt.want)
+		}
+	}
+}
+
+func TestSessions(t *testing.T) {
+	sessions := Sessions(readFixture(t, "wtmp"))
+	want := []Session{
+		{User: "alice", Line: "pts/1", Host: "2001:db8::1", PID: 1100, Login: at(12000), End: EndStillIn},
+		{User: "reboot", Line: "system boot", Host: "6.1.0-21-amd64", Login: at(11000), End: EndRunning},
+		{User: "bob", Line: "tty1", PID: 1001, Login: at(7200), Logout: at(10800), End: EndDown},
+		{User: "alice", Line: "pts/0", Host: "192.0.2.7", PID: 1000, Login: at(3600), Logout: at(5400), End: EndLogout},
+		{User: "reboot", Line: "system boot", Host: "6.1.0-18-amd64", Login: at(0), Logout: at(10800), End: EndDown},
+	}
+	if len(sessions) != len(want) {
+		t.Fatalf("got %d sessions, want %d: %+v", len(sessions), len(want), sessions)
+	}
+	for i, w := range want {
+		g := sessions[i]
+		if g.User != w.User || g.Line != w.Line || g.Host != w.Host || g.PID != w.PID ||
+			!g.Login.Equal(w.Login) || !g.Logout.Equal(w.Logout) || g.End != w.End {
+			t.Errorf("session %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+	if d := sessions[0].Duration(at(13000)); d != 1000*time.Second {
+		t.Errorf("open session duration = %v, want 1000s", d)
+	}
+}
+
+func TestActive(t *testing.T) {
+	active := Active(readFixture(t, "wtmp"))
+	var users []string
+	for _, r := range active {
+		users = append(users, r.User+"@"+r.Line)
+	}
+	// Active expects a utmp file and does not pair logins with logouts
+	want := []string{"alice@pts/0", "bob@tty1", "alice@pts/1"}
+	if !reflect.DeepEqual(users, want) {
+		t.Errorf("got %v, want %v", users, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	sessions := Sessions(readFixture(t, "wtmp"))
+	got := Summarize(sessions, readFixture(t, "btmp"), at(13000))
+	want := []Summary{
+		{User: "alice", Logins: 2, Failed: 1, Total: 2800 * time.Second, LastLogin: at(12000),
+			Hosts: []string{"192.0.2.7", "2001:db8::1", "203.0.113.9"}},
+		{User: "bob", Logins: 1, Total: 3600 * time.Second, LastLogin: at(7200)},
+		{User: "root", Failed: 1, Hosts: []string{"203.0.113.9"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.User != w.User || g.Logins != w.Logins || g.Failed != w.Failed || g.Total != w.Total ||
+			!g.LastLogin.Equal(w.LastLogin) || !reflect.DeepEqual(g.Hosts, w.Hosts) {
+			t.Errorf("summary %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	if _, err := Read("testdata/nonexistent"); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want not-exist", err)
+	}
+}